@@ -14,6 +14,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata"
 	"wishlist-app/internal/config"
 
 	"wishlist-app/internal/server"
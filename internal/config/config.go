@@ -1,10 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+
+	"wishlist-app/pkg/money"
 )
 
 type Config struct {
@@ -19,14 +23,63 @@ type Config struct {
 
 	Server struct {
 		Port         string
+		PublicURL    string
 		ReadTimeout  time.Duration
 		WriteTimeout time.Duration
 		IdleTimeout  time.Duration
 	}
 
 	Auth struct {
-		JWTSecret   string
-		JWTLifetime time.Duration
+		JWTSecret             string
+		AccessTokenLifetime   time.Duration
+		RefreshTokenLifetime  time.Duration
+		SessionCacheTTL       time.Duration
+		SessionPruneInterval  time.Duration
+		PasswordResetLifetime time.Duration
+
+		EmailVerificationLifetime       time.Duration
+		EmailVerificationResendInterval time.Duration
+
+		TOTPIssuer                 string
+		TwoFactorChallengeLifetime time.Duration
+		TwoFactorLockout           time.Duration
+	}
+
+	Mail struct {
+		Driver       string
+		SMTPHost     string
+		SMTPPort     string
+		SMTPUsername string
+		SMTPPassword string
+		From         string
+	}
+
+	Money struct {
+		DefaultCurrency string
+	}
+
+	Product struct {
+		FetchTimeout time.Duration
+		MaxPageBytes int64
+	}
+
+	Search struct {
+		Language string
+	}
+
+	Storage struct {
+		Driver         string
+		LocalDir       string
+		S3Endpoint     string
+		S3Region       string
+		S3Bucket       string
+		S3AccessKey    string
+		S3SecretKey    string
+		S3PathStyle    bool
+		URLExpiry      time.Duration
+		MaxUploadBytes int64
+		MaxPixels      int
+		ThumbnailSize  int
 	}
 
 	LogLevel string
@@ -47,18 +100,91 @@ func Load() (*Config, error) {
 	cfg.DB.SSLMode = getEnv("DB_SSLMODE", "disable")
 
 	cfg.Server.Port = getEnv("SERVER_PORT", "8080")
+	cfg.Server.PublicURL = strings.TrimRight(getEnv("PUBLIC_URL", "http://localhost:8080"), "/")
 	cfg.Server.ReadTimeout = 10 * time.Second
 	cfg.Server.WriteTimeout = 10 * time.Second
 	cfg.Server.IdleTimeout = 60 * time.Second
 
 	cfg.Auth.JWTSecret = getEnv("JWT_SECRET", "default-secret")
-	cfg.Auth.JWTLifetime = 24 * time.Hour
+	var err error
+	if cfg.Auth.AccessTokenLifetime, err = getDuration("ACCESS_TOKEN_LIFETIME", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.RefreshTokenLifetime, err = getDuration("REFRESH_TOKEN_LIFETIME", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.SessionCacheTTL, err = getDuration("SESSION_CACHE_TTL", time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.SessionPruneInterval, err = getDuration("SESSION_PRUNE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.PasswordResetLifetime, err = getDuration("PASSWORD_RESET_LIFETIME", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.EmailVerificationLifetime, err = getDuration("EMAIL_VERIFICATION_LIFETIME", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.EmailVerificationResendInterval, err = getDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute); err != nil {
+		return nil, err
+	}
+	cfg.Auth.TOTPIssuer = getEnv("TOTP_ISSUER", "Wishlist")
+	if cfg.Auth.TwoFactorChallengeLifetime, err = getDuration("TWO_FACTOR_CHALLENGE_LIFETIME", 5*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.TwoFactorLockout, err = getDuration("TWO_FACTOR_LOCKOUT", 15*time.Minute); err != nil {
+		return nil, err
+	}
+
+	cfg.Mail.Driver = getEnv("MAIL_DRIVER", "smtp")
+	cfg.Mail.SMTPHost = getEnv("SMTP_HOST", "localhost")
+	cfg.Mail.SMTPPort = getEnv("SMTP_PORT", "25")
+	cfg.Mail.SMTPUsername = getEnv("SMTP_USERNAME", "")
+	cfg.Mail.SMTPPassword = getEnv("SMTP_PASSWORD", "")
+	cfg.Mail.From = getEnv("MAIL_FROM", "Wishlist <no-reply@localhost>")
+
+	// The default currency decides the precision existing prices are migrated
+	// with, so a typo must stop startup rather than round them.
+	cfg.Money.DefaultCurrency = strings.ToUpper(getEnv("DEFAULT_CURRENCY", "EUR"))
+	if !money.ValidCurrency(cfg.Money.DefaultCurrency) {
+		return nil, fmt.Errorf("DEFAULT_CURRENCY must be an ISO 4217 currency code such as EUR, got %q", cfg.Money.DefaultCurrency)
+	}
+
+	cfg.Product.FetchTimeout = 10 * time.Second
+	cfg.Product.MaxPageBytes = 2 << 20
+
+	cfg.Search.Language = getEnv("SEARCH_LANGUAGE", "english")
+
+	cfg.Storage.Driver = getEnv("STORAGE_DRIVER", "local")
+	cfg.Storage.LocalDir = getEnv("STORAGE_DIR", "uploads")
+	cfg.Storage.S3Endpoint = getEnv("S3_ENDPOINT", "https://s3.amazonaws.com")
+	cfg.Storage.S3Region = getEnv("S3_REGION", "us-east-1")
+	cfg.Storage.S3Bucket = getEnv("S3_BUCKET", "")
+	cfg.Storage.S3AccessKey = getEnv("S3_ACCESS_KEY", "")
+	cfg.Storage.S3SecretKey = getEnv("S3_SECRET_KEY", "")
+	cfg.Storage.S3PathStyle = getEnv("S3_PATH_STYLE", "false") == "true"
+	cfg.Storage.URLExpiry = 15 * time.Minute
+	cfg.Storage.MaxUploadBytes = 10 << 20
+	cfg.Storage.MaxPixels = 40_000_000
+	cfg.Storage.ThumbnailSize = 320
 
 	cfg.LogLevel = getEnv("LOG_LEVEL", "info")
 
 	return cfg, nil
 }
 
+func getDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration such as 15m or 720h", key)
+	}
+	return duration, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
 	"wishlist-app/internal/service"
 	"wishlist-app/pkg/logger"
 	"wishlist-app/pkg/metrics"
@@ -12,22 +15,25 @@ import (
 )
 
 type AuthHandler struct {
-	authService *service.AuthService
-	logger      logger.Logger
-	cfg         *config.Config
+	authService  *service.AuthService
+	emailService *service.EmailService
+	logger       logger.Logger
+	cfg          *config.Config
 }
 
-func NewAuthHandler(cfg *config.Config, logger logger.Logger, authService *service.AuthService) *AuthHandler {
+func NewAuthHandler(cfg *config.Config, logger logger.Logger, authService *service.AuthService, emailService *service.EmailService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		cfg:         cfg,
-		logger:      logger,
+		authService:  authService,
+		emailService: emailService,
+		cfg:          cfg,
+		logger:       logger,
 	}
 }
 
 type RegisterRequest struct {
 	Login    string `json:"login" binding:"required,min=3,max=50"`
 	Password string `json:"password" binding:"required,min=6,max=50"`
+	Email    string `json:"email" binding:"omitempty,email,max=254" example:"alice@example.com"`
 }
 
 type LoginRequest struct {
@@ -35,21 +41,58 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginResponse carries the access token in Token and the refresh token to
+// get the next one with. ExpiresIn is the access token lifetime in seconds.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"`
+}
+
+// TwoFactorChallengeResponse is returned by login instead of tokens when the
+// user has two-factor authentication. ExpiresIn is in seconds.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in" example:"300"`
+}
+
+type CompleteLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required" example:"123456"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6,max=50"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"alice@example.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6,max=50"`
 }
 
 // @BasePath /api
 
 // Register godoc
 // @Summary Register a new user
-// @Description Register a new user with login and password
+// @Description Register a new user with login and password. The email address is optional; if given, a verification link is sent to it. Forgotten passwords can only be reset through a verified address.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body RegisterRequest true "Register Request"
 // @Success 201 "Created"
 // @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Conflict"
 // @Router /register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
@@ -59,24 +102,35 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.Register(req.Login, req.Password); err != nil {
+	user, err := h.authService.Register(req.Login, req.Password, req.Email)
+	if err != nil {
 		metrics.RecordAuthRequest("register", "failure")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrEmailTaken) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
+	// The account exists either way; the user can ask for the link again.
+	if err := h.emailService.SendInitialVerification(c.Request.Context(), user); err != nil {
+		h.logger.Errorf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
 	metrics.RecordAuthRequest("register", "success")
 	c.Status(http.StatusCreated)
 }
 
 // Login godoc
 // @Summary Login a user
-// @Description Login a user with login and password
+// @Description Login a user with login and password. Starts a session and returns a short-lived access token and a refresh token. Users with two-factor authentication get 202 Accepted with a challenge token instead, to be completed at /login/2fa.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Login Request"
 // @Success 200 {object} LoginResponse "OK"
+// @Success 202 {object} TwoFactorChallengeResponse "Accepted"
 // @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Router /login [post]
@@ -88,13 +142,298 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.authService.Login(req.Login, req.Password)
+	result, err := h.authService.Login(req.Login, req.Password, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		metrics.RecordAuthRequest("login", "failure")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
 	}
 
+	if result.Challenge != nil {
+		metrics.RecordAuthRequest("login", "challenge")
+		c.JSON(http.StatusAccepted, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    result.Challenge.Token,
+			ExpiresIn:         int(result.Challenge.ExpiresIn.Seconds()),
+		})
+		return
+	}
+
 	metrics.RecordAuthRequest("login", "success")
-	c.JSON(http.StatusOK, LoginResponse{Token: token})
+	c.JSON(http.StatusOK, loginResponse(result.Tokens))
+}
+
+// CompleteLogin godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge token from /login and a code from the authenticator app, or an unused recovery code, for a session. Each challenge completes one login. After five wrong codes, codes are refused until the lockout has passed.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body CompleteLoginRequest true "Complete Login Request"
+// @Success 200 {object} LoginResponse "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 429 {object} map[string]string "Too Many Requests"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /login/2fa [post]
+func (h *AuthHandler) CompleteLogin(c *gin.Context) {
+	var req CompleteLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordAuthRequest("login_2fa", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.authService.CompleteLogin(req.ChallengeToken, req.Code, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		metrics.RecordAuthRequest("login_2fa", "failure")
+		status := errorStatus(err)
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("login_2fa", "success")
+	c.JSON(http.StatusOK, loginResponse(tokens))
+}
+
+// Refresh godoc
+// @Summary Refresh the access token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Every refresh token can be used once; using one again revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body RefreshRequest true "Refresh Request"
+// @Success 200 {object} LoginResponse "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordAuthRequest("refresh", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			h.logger.Warnf("Refresh token reused, session revoked")
+		}
+		metrics.RecordAuthRequest("refresh", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("refresh", "success")
+	c.JSON(http.StatusOK, loginResponse(tokens))
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description End the session of the access token. Its refresh token and all access tokens issued for it stop working.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.authService.Logout(c.GetUint("userID"), c.GetUint("sessionID")); err != nil {
+		metrics.RecordAuthRequest("logout", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("logout", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Set a new password. The current password must be given. All other sessions of the user are logged out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body ChangePasswordRequest true "Change Password Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordAuthRequest("change_password", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.ChangePassword(c.GetUint("userID"), c.GetUint("sessionID"), req.CurrentPassword, req.NewPassword); err != nil {
+		metrics.RecordAuthRequest("change_password", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("change_password", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a password reset link to the account with this address. The response is the same whether or not such an account exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Forgot Password Request"
+// @Success 202 "Accepted"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordAuthRequest("forgot_password", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		h.logger.Errorf("Failed to send password reset email: %v", err)
+		metrics.RecordAuthRequest("forgot_password", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password reset email could not be sent"})
+		return
+	}
+
+	metrics.RecordAuthRequest("forgot_password", "success")
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password with the token from a password reset email. The token works once, and all sessions of the user are logged out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Reset Password Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordAuthRequest("reset_password", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		metrics.RecordAuthRequest("reset_password", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("reset_password", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// Sessions godoc
+// @Summary List sessions
+// @Description List the devices the authenticated user is logged in on, most recently used first. The session of the request is marked as current.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PublicSession "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /sessions [get]
+func (h *AuthHandler) Sessions(c *gin.Context) {
+	sessions, err := h.authService.Sessions(c.GetUint("userID"))
+	if err != nil {
+		metrics.RecordAuthRequest("sessions", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("sessions", "success")
+	currentSessionID := c.GetUint("sessionID")
+	publicSessions := make([]*models.PublicSession, len(sessions))
+	for i := range sessions {
+		publicSessions[i] = sessions[i].ToPublic(currentSessionID)
+	}
+
+	c.JSON(http.StatusOK, publicSessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Log the authenticated user out on one device. Its refresh token and access tokens stop working.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Session ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordAuthRequest("revoke_session", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.GetUint("userID"), uint(sessionID)); err != nil {
+		metrics.RecordAuthRequest("revoke_session", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("revoke_session", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke all other sessions
+// @Description Log the authenticated user out on every device except the one making the request
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	if err := h.authService.RevokeOtherSessions(c.GetUint("userID"), c.GetUint("sessionID")); err != nil {
+		metrics.RecordAuthRequest("revoke_sessions", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("revoke_sessions", "success")
+	c.Status(http.StatusNoContent)
+}
+
+func loginResponse(tokens *service.TokenPair) LoginResponse {
+	return LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(tokens.ExpiresIn.Seconds()),
+	}
 }
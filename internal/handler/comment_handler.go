@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	commentService *service.CommentService
+	logger         logger.Logger
+	cfg            *config.Config
+}
+
+func NewCommentHandler(cfg *config.Config, logger logger.Logger, commentService *service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+type CommentRequest struct {
+	Body string `json:"body" binding:"required,max=2000" example:"Does anyone know her size?"`
+}
+
+// Create godoc
+// @Summary Comment on a wish
+// @Description Add a comment to the thread of a wish. Threads are for gifters only: the owner of the wish never sees them and gets 404 Not Found.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Param request body CommentRequest true "Comment Request"
+// @Success 201 {object} models.PublicWishComment "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/comments [post]
+func (h *CommentHandler) Create(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordCommentOperation("create", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish ID"})
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordCommentOperation("create", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.Create(userID, uint(wishID), req.Body)
+	if err != nil {
+		metrics.RecordCommentOperation("create", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordCommentOperation("create", "success")
+	c.JSON(http.StatusCreated, comment.ToPublic())
+}
+
+// GetByWishID godoc
+// @Summary Get the comments on a wish
+// @Description Get a page of the comment thread of a wish, oldest first. The owner of the wish gets 404 Not Found.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param wish_id query int true "Wish ID"
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} models.WishCommentPage "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /comments [get]
+func (h *CommentHandler) GetByWishID(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, err := strconv.ParseUint(c.Query("wish_id"), 10, 64)
+	if err != nil {
+		metrics.RecordCommentOperation("read", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish ID"})
+		return
+	}
+	limit, err := queryLimit(c)
+	if err != nil {
+		metrics.RecordCommentOperation("read", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	comments, next, err := h.commentService.GetByWishID(userID, uint(wishID), c.Query("cursor"), limit)
+	if err != nil {
+		metrics.RecordCommentOperation("read", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordCommentOperation("read", "success")
+	page := models.WishCommentPage{
+		Items:      make([]*models.PublicWishComment, len(comments)),
+		NextCursor: next,
+	}
+	for i := range comments {
+		page.Items[i] = comments[i].ToPublic()
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Update godoc
+// @Summary Edit a comment
+// @Description Change the text of the authenticated user's comment
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Comment ID"
+// @Param request body CommentRequest true "Comment Request"
+// @Success 200 {object} models.PublicWishComment "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /comments/{id} [put]
+func (h *CommentHandler) Update(c *gin.Context) {
+	userID := c.GetUint("userID")
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordCommentOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordCommentOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.Update(userID, uint(commentID), req.Body)
+	if err != nil {
+		metrics.RecordCommentOperation("update", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordCommentOperation("update", "success")
+	c.JSON(http.StatusOK, comment.ToPublic())
+}
+
+// Delete godoc
+// @Summary Delete a comment
+// @Description Delete a comment. Authors may delete their own comments, and the co-organizer of the wish's list may delete any comment in its threads.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Comment ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /comments/{id} [delete]
+func (h *CommentHandler) Delete(c *gin.Context) {
+	userID := c.GetUint("userID")
+	commentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordCommentOperation("delete", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	if err := h.commentService.Delete(userID, uint(commentID)); err != nil {
+		metrics.RecordCommentOperation("delete", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordCommentOperation("delete", "success")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+	"wishlist-app/pkg/money"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRatesFileSize bounds the exchange rate files admins upload.
+const maxRatesFileSize = 1 << 20
+
+type CurrencyHandler struct {
+	currencyService *service.CurrencyService
+	logger          logger.Logger
+	cfg             *config.Config
+}
+
+func NewCurrencyHandler(cfg *config.Config, logger logger.Logger, currencyService *service.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{
+		currencyService: currencyService,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+type CurrencyRequest struct {
+	Currency string `json:"currency" example:"USD"`
+}
+
+// LoadRates godoc
+// @Summary Load exchange rates
+// @Description Replace all exchange rates with the ones in an uploaded CSV file of "currency,rate" lines. Every rate is the amount of that currency worth one unit of a common base currency, which itself has a rate of 1. Admins only.
+// @Tags currencies
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "CSV file with exchange rates"
+// @Success 200 {array} models.PublicExchangeRate "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /admin/exchange-rates [put]
+func (h *CurrencyHandler) LoadRates(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		metrics.RecordCurrencyOperation("load_rates", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rates file required"})
+		return
+	}
+	if header.Size > maxRatesFileSize {
+		metrics.RecordCurrencyOperation("load_rates", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rates file too large"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		metrics.RecordCurrencyOperation("load_rates", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	rates, err := h.currencyService.LoadRates(userID, file)
+	if err != nil {
+		metrics.RecordCurrencyOperation("load_rates", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordCurrencyOperation("load_rates", "success")
+	c.JSON(http.StatusOK, publicRates(rates))
+}
+
+// GetRates godoc
+// @Summary Get exchange rates
+// @Description Get the exchange rates used to convert totals
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.PublicExchangeRate "OK"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchange-rates [get]
+func (h *CurrencyHandler) GetRates(c *gin.Context) {
+	rates, err := h.currencyService.GetRates()
+	if err != nil {
+		metrics.RecordCurrencyOperation("read_rates", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordCurrencyOperation("read_rates", "success")
+	c.JSON(http.StatusOK, publicRates(rates))
+}
+
+// SetPreferred godoc
+// @Summary Set preferred currency
+// @Description Set the currency totals are converted into for the authenticated user. An empty currency uses the server default.
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body CurrencyRequest true "Currency Request"
+// @Success 200 "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /me/currency [put]
+func (h *CurrencyHandler) SetPreferred(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req CurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordCurrencyOperation("set_preferred", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.currencyService.SetPreferredCurrency(userID, req.Currency); err != nil {
+		metrics.RecordCurrencyOperation("set_preferred", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordCurrencyOperation("set_preferred", "success")
+	c.Status(http.StatusOK)
+}
+
+// Total godoc
+// @Summary Get the total of a user's wishes
+// @Description Add up the prices of the items a user still wishes for that are visible to the viewer, converted into one currency. Without a currency, the viewer's preferred currency or the server default is used.
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param currency query string false "ISO 4217 currency code"
+// @Success 200 {object} models.WishTotal "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 422 {object} map[string]string "Unprocessable Entity"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{username}/total [get]
+func (h *CurrencyHandler) Total(c *gin.Context) {
+	viewerID := c.GetUint("userID")
+
+	total, err := h.currencyService.Total(viewerID, c.Param("username"), c.Query("currency"))
+	if err != nil {
+		metrics.RecordCurrencyOperation("total", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordCurrencyOperation("total", "success")
+	c.JSON(http.StatusOK, total)
+}
+
+func publicRates(rates []models.ExchangeRate) []*models.PublicExchangeRate {
+	public := make([]*models.PublicExchangeRate, len(rates))
+	for i := range rates {
+		public[i] = rates[i].ToPublic()
+	}
+	return public
+}
+
+// parseAmount converts an amount sent by a client into minor units of its
+// currency. Without a currency the fallback is used; an empty amount is zero.
+func parseAmount(amount money.Decimal, currency, fallback string) (int64, string, error) {
+	currency = strings.ToUpper(currency)
+	if currency != "" && !money.ValidCurrency(currency) {
+		return 0, "", service.ErrInvalidCurrency
+	}
+	if currency == "" {
+		currency = fallback
+	}
+	if amount == "" {
+		return 0, currency, nil
+	}
+
+	minor, err := money.Parse(string(amount), currency)
+	switch {
+	case errors.Is(err, money.ErrInvalidCurrency):
+		return 0, "", service.ErrInvalidCurrency
+	case err != nil:
+		return 0, "", service.ErrInvalidAmount
+	}
+	return minor, currency, nil
+}
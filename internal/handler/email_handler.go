@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"net/http"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailHandler struct {
+	emailService *service.EmailService
+	logger       logger.Logger
+	cfg          *config.Config
+}
+
+func NewEmailHandler(cfg *config.Config, logger logger.Logger, emailService *service.EmailService) *EmailHandler {
+	return &EmailHandler{
+		emailService: emailService,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email,max=254" example:"alice@example.com"`
+	Password string `json:"password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Status godoc
+// @Summary Get email status
+// @Description Get the authenticated user's email address and whether it is verified
+// @Tags email
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.EmailStatus "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /email [get]
+func (h *EmailHandler) Status(c *gin.Context) {
+	user, err := h.emailService.Status(c.GetUint("userID"))
+	if err != nil {
+		metrics.RecordAuthRequest("email_status", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("email_status", "success")
+	c.JSON(http.StatusOK, user.ToEmailStatus())
+}
+
+// Change godoc
+// @Summary Change email address
+// @Description Set a new email address. The password must be given. The new address is unverified until the link sent to it is opened.
+// @Tags email
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body ChangeEmailRequest true "Change Email Request"
+// @Success 200 {object} models.EmailStatus "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 429 {object} map[string]string "Too Many Requests"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /email [put]
+func (h *EmailHandler) Change(c *gin.Context) {
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordAuthRequest("change_email", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.emailService.ChangeEmail(c.Request.Context(), c.GetUint("userID"), req.Password, req.Email)
+	if err != nil {
+		metrics.RecordAuthRequest("change_email", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("change_email", "success")
+	c.JSON(http.StatusOK, user.ToEmailStatus())
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new verification link to the authenticated user's unverified address. Links can be requested once per resend interval.
+// @Tags email
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 202 "Accepted"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 429 {object} map[string]string "Too Many Requests"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /email/verification [post]
+func (h *EmailHandler) ResendVerification(c *gin.Context) {
+	if err := h.emailService.SendVerification(c.Request.Context(), c.GetUint("userID")); err != nil {
+		metrics.RecordAuthRequest("resend_verification", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("resend_verification", "success")
+	c.Status(http.StatusAccepted)
+}
+
+// Verify godoc
+// @Summary Verify email address
+// @Description Confirm an email address with the token from a verification link. Links expire, and a link stops working once the address is changed.
+// @Tags email
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Verify Email Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /email/verify [post]
+func (h *EmailHandler) Verify(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordAuthRequest("verify_email", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.emailService.Verify(req.Token); err != nil {
+		metrics.RecordAuthRequest("verify_email", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("verify_email", "success")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"wishlist-app/internal/service"
+)
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidRefreshToken),
+		errors.Is(err, service.ErrRefreshTokenReused),
+		errors.Is(err, service.ErrSessionRevoked),
+		errors.Is(err, service.ErrInvalidChallenge):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrForbidden),
+		errors.Is(err, service.ErrOwnWish),
+		errors.Is(err, service.ErrWrongPassword),
+		errors.Is(err, service.ErrEmailNotVerified):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrAlreadyReserved),
+		errors.Is(err, service.ErrAlreadyClaimed),
+		errors.Is(err, service.ErrInvalidTransition),
+		errors.Is(err, service.ErrNotReceived),
+		errors.Is(err, service.ErrWishNotActive),
+		errors.Is(err, service.ErrTagExists),
+		errors.Is(err, service.ErrShareLinkRevoked),
+		errors.Is(err, service.ErrQuantityTooLarge),
+		errors.Is(err, service.ErrGroupGift),
+		errors.Is(err, service.ErrAlreadyPledged),
+		errors.Is(err, service.ErrPledgeTooLarge),
+		errors.Is(err, service.ErrCurrencyLocked),
+		errors.Is(err, service.ErrFriendshipExists),
+		errors.Is(err, service.ErrExchangeRevealed),
+		errors.Is(err, service.ErrExchangeNotDrawn),
+		errors.Is(err, service.ErrExchangeNotRevealed),
+		errors.Is(err, service.ErrAlreadyParticipant),
+		errors.Is(err, service.ErrEmailTaken),
+		errors.Is(err, service.ErrEmailAlreadyVerified),
+		errors.Is(err, service.ErrTwoFactorEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnabled):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrTooFewParticipants),
+		errors.Is(err, service.ErrNoValidAssignment),
+		errors.Is(err, service.ErrNoExchangeRate):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrDefaultWishlist),
+		errors.Is(err, service.ErrInvalidExpiry),
+		errors.Is(err, service.ErrNoPrice),
+		errors.Is(err, service.ErrSelfFriendship),
+		errors.Is(err, service.ErrInvalidCursor),
+		errors.Is(err, service.ErrInvalidTimezone),
+		errors.Is(err, service.ErrInvalidMove),
+		errors.Is(err, service.ErrInvalidQuantity),
+		errors.Is(err, service.ErrInvalidTag),
+		errors.Is(err, service.ErrInvalidCategory),
+		errors.Is(err, service.ErrInvalidCurrency),
+		errors.Is(err, service.ErrInvalidAmount),
+		errors.Is(err, service.ErrInvalidPriceRange),
+		errors.Is(err, service.ErrInvalidSort),
+		errors.Is(err, service.ErrInvalidProductURL),
+		errors.Is(err, service.ErrInvalidSearch),
+		errors.Is(err, service.ErrInvalidFilter),
+		errors.Is(err, service.ErrCopyOwnWish),
+		errors.Is(err, service.ErrInvalidComment),
+		errors.Is(err, service.ErrSelfCoOrganizer),
+		errors.Is(err, service.ErrInvalidRates),
+		errors.Is(err, service.ErrOrganizerLeave),
+		errors.Is(err, service.ErrInvalidExclusion),
+		errors.Is(err, service.ErrInvalidResetToken),
+		errors.Is(err, service.ErrNoEmail),
+		errors.Is(err, service.ErrInvalidVerificationToken),
+		errors.Is(err, service.ErrTwoFactorNotEnrolled),
+		errors.Is(err, service.ErrInvalidTwoFactorCode):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrVerificationThrottled),
+		errors.Is(err, service.ErrTooManyTwoFactorAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, service.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrUnsupportedImage):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, service.ErrProductUnavailable):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
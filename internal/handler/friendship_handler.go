@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FriendshipHandler struct {
+	friendshipService *service.FriendshipService
+	logger            logger.Logger
+	cfg               *config.Config
+}
+
+func NewFriendshipHandler(cfg *config.Config, logger logger.Logger, friendshipService *service.FriendshipService) *FriendshipHandler {
+	return &FriendshipHandler{
+		friendshipService: friendshipService,
+		cfg:               cfg,
+		logger:            logger,
+	}
+}
+
+type FriendRequestRequest struct {
+	Login string `json:"login" binding:"required"`
+}
+
+// SendRequest godoc
+// @Summary Send a friend request
+// @Description Send a friend request to a user by login. If that user has already sent a request, it is accepted.
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body FriendRequestRequest true "Friend Request"
+// @Success 201 {object} models.PublicFriendship "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /friends/requests [post]
+func (h *FriendshipHandler) SendRequest(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req FriendRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordFriendshipOperation("request", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	friendship, err := h.friendshipService.SendRequest(userID, req.Login)
+	if err != nil {
+		metrics.RecordFriendshipOperation("request", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordFriendshipOperation("request", "success")
+	c.JSON(http.StatusCreated, friendship.ToPublicFor(userID))
+}
+
+// GetRequests godoc
+// @Summary Get pending friend requests
+// @Description Get incoming and outgoing pending friend requests of the authenticated user
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.FriendRequests "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /friends/requests [get]
+func (h *FriendshipHandler) GetRequests(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	incoming, outgoing, err := h.friendshipService.GetRequests(userID)
+	if err != nil {
+		metrics.RecordFriendshipOperation("read_requests", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordFriendshipOperation("read_requests", "success")
+	c.JSON(http.StatusOK, models.FriendRequests{
+		Incoming: toPublicFriendships(userID, incoming),
+		Outgoing: toPublicFriendships(userID, outgoing),
+	})
+}
+
+// Accept godoc
+// @Summary Accept a friend request
+// @Description Accept a pending friend request addressed to the authenticated user
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Friend request ID"
+// @Success 200 {object} models.PublicFriendship "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /friends/requests/{id}/accept [post]
+func (h *FriendshipHandler) Accept(c *gin.Context) {
+	userID := c.GetUint("userID")
+	friendshipID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordFriendshipOperation("accept", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid friend request ID"})
+		return
+	}
+
+	friendship, err := h.friendshipService.Accept(userID, uint(friendshipID))
+	if err != nil {
+		metrics.RecordFriendshipOperation("accept", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordFriendshipOperation("accept", "success")
+	c.JSON(http.StatusOK, friendship.ToPublicFor(userID))
+}
+
+// Decline godoc
+// @Summary Decline a friend request
+// @Description Decline a pending friend request addressed to the authenticated user, or withdraw one they sent
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Friend request ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /friends/requests/{id}/decline [post]
+func (h *FriendshipHandler) Decline(c *gin.Context) {
+	userID := c.GetUint("userID")
+	friendshipID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordFriendshipOperation("decline", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid friend request ID"})
+		return
+	}
+
+	if err := h.friendshipService.Decline(userID, uint(friendshipID)); err != nil {
+		metrics.RecordFriendshipOperation("decline", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordFriendshipOperation("decline", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// Remove godoc
+// @Summary Remove a friend
+// @Description End a friendship of the authenticated user
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Friendship ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /friends/{id} [delete]
+func (h *FriendshipHandler) Remove(c *gin.Context) {
+	userID := c.GetUint("userID")
+	friendshipID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordFriendshipOperation("remove", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid friendship ID"})
+		return
+	}
+
+	if err := h.friendshipService.Remove(userID, uint(friendshipID)); err != nil {
+		metrics.RecordFriendshipOperation("remove", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordFriendshipOperation("remove", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// GetFriends godoc
+// @Summary Get friends
+// @Description Get the friends of the authenticated user
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PublicFriendship "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /friends [get]
+func (h *FriendshipHandler) GetFriends(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	friendships, err := h.friendshipService.GetFriends(userID)
+	if err != nil {
+		metrics.RecordFriendshipOperation("read_friends", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordFriendshipOperation("read_friends", "success")
+	c.JSON(http.StatusOK, toPublicFriendships(userID, friendships))
+}
+
+// Feed godoc
+// @Summary Get friends feed
+// @Description Get recently added or updated wishes from friends, newest first
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} models.WishPage "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /feed [get]
+func (h *FriendshipHandler) Feed(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		metrics.RecordWishOperation("feed", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	wishes, next, err := h.friendshipService.Feed(userID, c.Query("cursor"), limit)
+	if err != nil {
+		metrics.RecordWishOperation("feed", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("feed", "success")
+	page := models.WishPage{
+		Items:      make([]*models.PublicWish, len(wishes)),
+		NextCursor: next,
+	}
+	for i, wish := range wishes {
+		page.Items[i] = wish.ToPublicFor(userID)
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func toPublicFriendships(userID uint, friendships []models.Friendship) []*models.PublicFriendship {
+	publicFriendships := make([]*models.PublicFriendship, len(friendships))
+	for i, friendship := range friendships {
+		publicFriendships[i] = friendship.ToPublicFor(userID)
+	}
+	return publicFriendships
+}
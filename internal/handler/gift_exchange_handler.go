@@ -0,0 +1,540 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+	"wishlist-app/pkg/money"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GiftExchangeHandler struct {
+	exchangeService *service.GiftExchangeService
+	logger          logger.Logger
+	cfg             *config.Config
+}
+
+func NewGiftExchangeHandler(cfg *config.Config, logger logger.Logger, exchangeService *service.GiftExchangeService) *GiftExchangeHandler {
+	return &GiftExchangeHandler{
+		exchangeService: exchangeService,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+type GiftExchangeRequest struct {
+	Name     string        `json:"name" binding:"required,max=100"`
+	Budget   money.Decimal `json:"budget" swaggertype:"string" example:"30.00"`
+	Currency string        `json:"currency" example:"EUR"`
+}
+
+type InvitationRequest struct {
+	Login string `json:"login" binding:"required"`
+}
+
+type ExclusionRequest struct {
+	GiverID     uint `json:"giver_id" binding:"required"`
+	RecipientID uint `json:"recipient_id" binding:"required"`
+	Mutual      bool `json:"mutual"`
+}
+
+// Create godoc
+// @Summary Create a gift exchange
+// @Description Start a Secret Santa group. The organizer joins it automatically.
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body GiftExchangeRequest true "Gift Exchange Request"
+// @Success 201 {object} models.PublicGiftExchange "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges [post]
+func (h *GiftExchangeHandler) Create(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req GiftExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordGiftExchangeOperation("create", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	budget, currency, err := parseAmount(req.Budget, req.Currency, h.cfg.Money.DefaultCurrency)
+	if err != nil {
+		metrics.RecordGiftExchangeOperation("create", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	exchange, err := h.exchangeService.Create(userID, &models.GiftExchange{Name: req.Name, BudgetMinor: budget, Currency: currency})
+	if err != nil {
+		metrics.RecordGiftExchangeOperation("create", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation("create", "success")
+	c.JSON(http.StatusCreated, exchange.ToPublicFor(userID))
+}
+
+// GetByUserID godoc
+// @Summary Get gift exchanges of authenticated user
+// @Description Get the exchanges the authenticated user organizes or was invited to
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PublicGiftExchange "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges [get]
+func (h *GiftExchangeHandler) GetByUserID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	exchanges, err := h.exchangeService.GetByUserID(userID)
+	if err != nil {
+		metrics.RecordGiftExchangeOperation("read", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation("read", "success")
+	publicExchanges := make([]*models.PublicGiftExchange, len(exchanges))
+	for i := range exchanges {
+		publicExchanges[i] = exchanges[i].ToPublicFor(userID)
+	}
+
+	c.JSON(http.StatusOK, publicExchanges)
+}
+
+// GetByID godoc
+// @Summary Get a gift exchange
+// @Description Get an exchange with its participants. Exclusion rules are only shown to the organizer and assignments are never included.
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Success 200 {object} models.PublicGiftExchange "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id} [get]
+func (h *GiftExchangeHandler) GetByID(c *gin.Context) {
+	userID := c.GetUint("userID")
+	exchangeID, ok := h.exchangeID(c, "read")
+	if !ok {
+		return
+	}
+
+	exchange, err := h.exchangeService.GetByID(userID, exchangeID)
+	if err != nil {
+		metrics.RecordGiftExchangeOperation("read", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation("read", "success")
+	c.JSON(http.StatusOK, exchange.ToPublicFor(userID))
+}
+
+// Update godoc
+// @Summary Update a gift exchange
+// @Description Update the name and budget of an exchange organized by the authenticated user
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Param request body GiftExchangeRequest true "Gift Exchange Request"
+// @Success 200 "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id} [put]
+func (h *GiftExchangeHandler) Update(c *gin.Context) {
+	userID := c.GetUint("userID")
+	exchangeID, ok := h.exchangeID(c, "update")
+	if !ok {
+		return
+	}
+
+	var req GiftExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordGiftExchangeOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	budget, currency, err := parseAmount(req.Budget, req.Currency, h.cfg.Money.DefaultCurrency)
+	if err != nil {
+		metrics.RecordGiftExchangeOperation("update", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	exchange := &models.GiftExchange{Model: gorm.Model{ID: exchangeID}, Name: req.Name, BudgetMinor: budget, Currency: currency}
+	if err := h.exchangeService.Update(userID, exchange); err != nil {
+		metrics.RecordGiftExchangeOperation("update", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation("update", "success")
+	c.Status(http.StatusOK)
+}
+
+// Delete godoc
+// @Summary Delete a gift exchange
+// @Description Delete an exchange organized by the authenticated user
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id} [delete]
+func (h *GiftExchangeHandler) Delete(c *gin.Context) {
+	h.exchangeAction(c, "delete", h.exchangeService.Delete)
+}
+
+// Invite godoc
+// @Summary Invite a user to a gift exchange
+// @Description Invite a user by login. Invited users take part in the draw once they join.
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Param request body InvitationRequest true "Invitation Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id}/participants [post]
+func (h *GiftExchangeHandler) Invite(c *gin.Context) {
+	userID := c.GetUint("userID")
+	exchangeID, ok := h.exchangeID(c, "invite")
+	if !ok {
+		return
+	}
+
+	var req InvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordGiftExchangeOperation("invite", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.exchangeService.Invite(userID, exchangeID, req.Login); err != nil {
+		metrics.RecordGiftExchangeOperation("invite", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation("invite", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveParticipant godoc
+// @Summary Remove a participant from a gift exchange
+// @Description Remove a participant before the results are revealed. A draw that included them is discarded.
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Param userId path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id}/participants/{userId} [delete]
+func (h *GiftExchangeHandler) RemoveParticipant(c *gin.Context) {
+	userID := c.GetUint("userID")
+	exchangeID, ok := h.exchangeID(c, "remove_participant")
+	if !ok {
+		return
+	}
+	participantID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		metrics.RecordGiftExchangeOperation("remove_participant", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.exchangeService.RemoveParticipant(userID, exchangeID, uint(participantID)); err != nil {
+		metrics.RecordGiftExchangeOperation("remove_participant", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation("remove_participant", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// Join godoc
+// @Summary Join a gift exchange
+// @Description Accept an invitation to an exchange. A draw made before joining is discarded.
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id}/join [post]
+func (h *GiftExchangeHandler) Join(c *gin.Context) {
+	h.exchangeAction(c, "join", h.exchangeService.Join)
+}
+
+// Leave godoc
+// @Summary Leave a gift exchange
+// @Description Decline an invitation or leave an exchange before the results are revealed
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id}/leave [post]
+func (h *GiftExchangeHandler) Leave(c *gin.Context) {
+	h.exchangeAction(c, "leave", h.exchangeService.Leave)
+}
+
+// AddExclusion godoc
+// @Summary Add an exclusion rule
+// @Description Forbid a participant from drawing another one, e.g. spouses. Mutual rules apply both ways. A current draw is discarded.
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Param request body ExclusionRequest true "Exclusion Request"
+// @Success 201 {array} models.PublicExclusion "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id}/exclusions [post]
+func (h *GiftExchangeHandler) AddExclusion(c *gin.Context) {
+	userID := c.GetUint("userID")
+	exchangeID, ok := h.exchangeID(c, "add_exclusion")
+	if !ok {
+		return
+	}
+
+	var req ExclusionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordGiftExchangeOperation("add_exclusion", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exclusions, err := h.exchangeService.AddExclusion(userID, exchangeID, req.GiverID, req.RecipientID, req.Mutual)
+	if err != nil {
+		metrics.RecordGiftExchangeOperation("add_exclusion", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation("add_exclusion", "success")
+	publicExclusions := make([]*models.PublicExclusion, len(exclusions))
+	for i, exclusion := range exclusions {
+		publicExclusions[i] = &models.PublicExclusion{
+			ID:          exclusion.ID,
+			GiverID:     exclusion.GiverID,
+			RecipientID: exclusion.RecipientID,
+		}
+	}
+	c.JSON(http.StatusCreated, publicExclusions)
+}
+
+// RemoveExclusion godoc
+// @Summary Remove an exclusion rule
+// @Description Remove an exclusion rule from an exchange organized by the authenticated user
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Param exclusionId path int true "Exclusion ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id}/exclusions/{exclusionId} [delete]
+func (h *GiftExchangeHandler) RemoveExclusion(c *gin.Context) {
+	userID := c.GetUint("userID")
+	exchangeID, ok := h.exchangeID(c, "remove_exclusion")
+	if !ok {
+		return
+	}
+	exclusionID, err := strconv.ParseUint(c.Param("exclusionId"), 10, 64)
+	if err != nil {
+		metrics.RecordGiftExchangeOperation("remove_exclusion", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exclusion ID"})
+		return
+	}
+
+	if err := h.exchangeService.RemoveExclusion(userID, exchangeID, uint(exclusionID)); err != nil {
+		metrics.RecordGiftExchangeOperation("remove_exclusion", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation("remove_exclusion", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// Draw godoc
+// @Summary Draw the gift exchange
+// @Description Randomly assign each joined participant a recipient that respects the exclusion rules. Drawing again before the reveal replaces the previous draw.
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 422 {object} map[string]string "No valid assignment"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id}/draw [post]
+func (h *GiftExchangeHandler) Draw(c *gin.Context) {
+	h.exchangeAction(c, "draw", h.exchangeService.Draw)
+}
+
+// Reveal godoc
+// @Summary Reveal the gift exchange results
+// @Description Make the current draw final so participants can see their recipients
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id}/reveal [post]
+func (h *GiftExchangeHandler) Reveal(c *gin.Context) {
+	h.exchangeAction(c, "reveal", h.exchangeService.Reveal)
+}
+
+// Assignment godoc
+// @Summary Get my gift exchange recipient
+// @Description Get the recipient drawn for the authenticated user and the recipient's wishes visible to them. Only available after the reveal.
+// @Tags exchanges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Exchange ID"
+// @Success 200 {object} models.PublicAssignment "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exchanges/{id}/assignment [get]
+func (h *GiftExchangeHandler) Assignment(c *gin.Context) {
+	userID := c.GetUint("userID")
+	exchangeID, ok := h.exchangeID(c, "assignment")
+	if !ok {
+		return
+	}
+
+	exchange, recipient, wishes, err := h.exchangeService.Assignment(userID, exchangeID)
+	if err != nil {
+		metrics.RecordGiftExchangeOperation("assignment", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation("assignment", "success")
+	assignment := &models.PublicAssignment{
+		ExchangeID: exchange.ID,
+		Budget:     exchange.FormattedBudget(),
+		Currency:   exchange.Currency,
+		Recipient:  *recipient.ToPublic(),
+		Wishes:     make([]*models.PublicWish, len(wishes)),
+	}
+	for i := range wishes {
+		assignment.Wishes[i] = wishes[i].ToPublicFor(userID)
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// exchangeAction handles bodiless actions on an exchange identified by the
+// id path parameter.
+func (h *GiftExchangeHandler) exchangeAction(c *gin.Context, operation string, action func(userID, exchangeID uint) error) {
+	userID := c.GetUint("userID")
+	exchangeID, ok := h.exchangeID(c, operation)
+	if !ok {
+		return
+	}
+
+	if err := action(userID, exchangeID); err != nil {
+		metrics.RecordGiftExchangeOperation(operation, "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordGiftExchangeOperation(operation, "success")
+	c.Status(http.StatusNoContent)
+}
+
+func (h *GiftExchangeHandler) exchangeID(c *gin.Context, operation string) (uint, bool) {
+	exchangeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordGiftExchangeOperation(operation, "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exchange ID"})
+		return 0, false
+	}
+	return uint(exchangeID), true
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImageHandler struct {
+	imageService *service.ImageService
+	logger       logger.Logger
+	cfg          *config.Config
+}
+
+func NewImageHandler(cfg *config.Config, logger logger.Logger, imageService *service.ImageService) *ImageHandler {
+	return &ImageHandler{
+		imageService: imageService,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// Upload godoc
+// @Summary Upload a wish image
+// @Description Upload a JPEG, PNG or GIF image for a wish of the authenticated user, replacing any image uploaded before. A thumbnail is generated. The uploaded image takes the place of the image URL in responses.
+// @Tags wishes
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Param image formData file true "Image file"
+// @Success 200 {object} models.PublicWish "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 413 {object} map[string]string "Request Entity Too Large"
+// @Failure 415 {object} map[string]string "Unsupported Media Type"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/image [put]
+func (h *ImageHandler) Upload(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordImageOperation("upload", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish ID"})
+		return
+	}
+
+	// Leave room for the multipart envelope around the file.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.cfg.Storage.MaxUploadBytes+64<<10)
+	header, err := c.FormFile("image")
+	if err != nil {
+		metrics.RecordImageOperation("upload", "failure")
+		if strings.Contains(err.Error(), "request body too large") {
+			c.JSON(errorStatus(service.ErrImageTooLarge), gin.H{"error": service.ErrImageTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image file required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		metrics.RecordImageOperation("upload", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	wish, err := h.imageService.Upload(c.Request.Context(), userID, uint(wishID), file)
+	if err != nil {
+		metrics.RecordImageOperation("upload", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordImageOperation("upload", "success")
+	c.JSON(http.StatusOK, wish.ToPublic())
+}
+
+// Remove godoc
+// @Summary Remove a wish image
+// @Description Delete the uploaded image of a wish of the authenticated user. The image URL set on the wish, if any, is shown again.
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Success 200 {object} models.PublicWish "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/image [delete]
+func (h *ImageHandler) Remove(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordImageOperation("remove", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish ID"})
+		return
+	}
+
+	wish, err := h.imageService.Remove(c.Request.Context(), userID, uint(wishID))
+	if err != nil {
+		metrics.RecordImageOperation("remove", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordImageOperation("remove", "success")
+	c.JSON(http.StatusOK, wish.ToPublic())
+}
+
+// Serve godoc
+// @Summary Get an uploaded image
+// @Description Download an uploaded image or thumbnail by its key, as found in the image and thumbnail URLs of wishes. With S3 storage, this redirects to a temporary presigned URL.
+// @Tags wishes
+// @Produce image/jpeg,image/png,image/gif
+// @Param key path string true "Image key"
+// @Success 200 {file} file "OK"
+// @Success 302 "Found"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /images/{key} [get]
+func (h *ImageHandler) Serve(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	url, object, err := h.imageService.Open(c.Request.Context(), key)
+	if err != nil {
+		metrics.RecordImageOperation("serve", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordImageOperation("serve", "success")
+	if url != "" {
+		c.Redirect(http.StatusFound, url)
+		return
+	}
+	defer object.Body.Close()
+
+	// Keys are never reused, so the content behind one never changes.
+	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, object.Body, map[string]string{
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
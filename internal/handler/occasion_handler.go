@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OccasionHandler struct {
+	occasionService *service.OccasionService
+	logger          logger.Logger
+	cfg             *config.Config
+}
+
+func NewOccasionHandler(cfg *config.Config, logger logger.Logger, occasionService *service.OccasionService) *OccasionHandler {
+	return &OccasionHandler{
+		occasionService: occasionService,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+type OccasionRequest struct {
+	Name       string            `json:"name" binding:"required,max=100"`
+	Date       string            `json:"date" binding:"required" example:"2025-12-24"`
+	Recurring  bool              `json:"recurring"`
+	Timezone   string            `json:"timezone" example:"Europe/Moscow"`
+	Visibility models.Visibility `json:"visibility" binding:"omitempty,oneof=private link friends public"`
+}
+
+type OccasionAttachmentRequest struct {
+	WishIDs     []uint `json:"wish_ids"`
+	WishlistIDs []uint `json:"wishlist_ids"`
+}
+
+func (r *OccasionRequest) toModel() (*models.Occasion, error) {
+	date, err := time.Parse(models.DateLayout, r.Date)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Occasion{
+		Name:       r.Name,
+		Date:       date,
+		Recurring:  r.Recurring,
+		Timezone:   r.Timezone,
+		Visibility: r.Visibility,
+	}, nil
+}
+
+// Create godoc
+// @Summary Create an occasion
+// @Description Create a dated occasion such as a birthday, optionally recurring every year
+// @Tags occasions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body OccasionRequest true "Occasion Request"
+// @Success 201 {object} models.PublicOccasion "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /occasions [post]
+func (h *OccasionHandler) Create(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req OccasionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordOccasionOperation("create", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	occasion, err := req.toModel()
+	if err != nil {
+		metrics.RecordOccasionOperation("create", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be in YYYY-MM-DD format"})
+		return
+	}
+
+	createdOccasion, err := h.occasionService.Create(userID, occasion)
+	if err != nil {
+		metrics.RecordOccasionOperation("create", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordOccasionOperation("create", "success")
+	c.JSON(http.StatusCreated, createdOccasion.ToPublicAt(time.Now()))
+}
+
+// GetByUserID godoc
+// @Summary Get occasions of authenticated user
+// @Description Get all occasions of the authenticated user with their next date
+// @Tags occasions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PublicOccasion "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /occasions [get]
+func (h *OccasionHandler) GetByUserID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	occasions, err := h.occasionService.GetByUserID(userID)
+	if err != nil {
+		metrics.RecordOccasionOperation("read", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordOccasionOperation("read", "success")
+	now := time.Now()
+	publicOccasions := make([]*models.PublicOccasion, len(occasions))
+	for i, occasion := range occasions {
+		publicOccasions[i] = occasion.ToPublicAt(now)
+	}
+
+	c.JSON(http.StatusOK, publicOccasions)
+}
+
+// Update godoc
+// @Summary Update an occasion
+// @Description Update an occasion of the authenticated user
+// @Tags occasions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Occasion ID"
+// @Param request body OccasionRequest true "Occasion Request"
+// @Success 200 "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /occasions/{id} [put]
+func (h *OccasionHandler) Update(c *gin.Context) {
+	userID := c.GetUint("userID")
+	occasionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordOccasionOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid occasion ID"})
+		return
+	}
+
+	var req OccasionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordOccasionOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	occasion, err := req.toModel()
+	if err != nil {
+		metrics.RecordOccasionOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be in YYYY-MM-DD format"})
+		return
+	}
+	occasion.Model = gorm.Model{ID: uint(occasionID)}
+
+	if err := h.occasionService.Update(userID, occasion); err != nil {
+		metrics.RecordOccasionOperation("update", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordOccasionOperation("update", "success")
+	c.Status(http.StatusOK)
+}
+
+// Delete godoc
+// @Summary Delete an occasion
+// @Description Delete an occasion. Attached wishes and lists are detached.
+// @Tags occasions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Occasion ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /occasions/{id} [delete]
+func (h *OccasionHandler) Delete(c *gin.Context) {
+	userID := c.GetUint("userID")
+	occasionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordOccasionOperation("delete", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid occasion ID"})
+		return
+	}
+
+	if err := h.occasionService.Delete(userID, uint(occasionID)); err != nil {
+		metrics.RecordOccasionOperation("delete", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordOccasionOperation("delete", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// Attach godoc
+// @Summary Attach wishes and lists to an occasion
+// @Description Attach wishes and lists of the authenticated user to one of their occasions
+// @Tags occasions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Occasion ID"
+// @Param request body OccasionAttachmentRequest true "Attachment Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /occasions/{id}/attach [post]
+func (h *OccasionHandler) Attach(c *gin.Context) {
+	h.changeAttachments(c, "attach", h.occasionService.Attach)
+}
+
+// Detach godoc
+// @Summary Detach wishes and lists from an occasion
+// @Description Detach wishes and lists of the authenticated user from one of their occasions
+// @Tags occasions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Occasion ID"
+// @Param request body OccasionAttachmentRequest true "Attachment Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /occasions/{id}/detach [post]
+func (h *OccasionHandler) Detach(c *gin.Context) {
+	h.changeAttachments(c, "detach", h.occasionService.Detach)
+}
+
+func (h *OccasionHandler) changeAttachments(c *gin.Context, operation string, change func(userID, occasionID uint, wishIDs, wishlistIDs []uint) error) {
+	userID := c.GetUint("userID")
+	occasionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordOccasionOperation(operation, "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid occasion ID"})
+		return
+	}
+
+	var req OccasionAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordOccasionOperation(operation, "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := change(userID, uint(occasionID), req.WishIDs, req.WishlistIDs); err != nil {
+		metrics.RecordOccasionOperation(operation, "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordOccasionOperation(operation, "success")
+	c.Status(http.StatusNoContent)
+}
+
+// Upcoming godoc
+// @Summary Get upcoming occasions of a user
+// @Description Get a user's upcoming occasions visible to the caller, soonest first, with a days-until countdown and the related wishes that are still available
+// @Tags occasions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Param days query int false "Look-ahead window in days (default 365)"
+// @Success 200 {array} models.UpcomingOccasion "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{username}/occasions/upcoming [get]
+func (h *OccasionHandler) Upcoming(c *gin.Context) {
+	viewerID := c.GetUint("userID")
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "0"))
+	if err != nil {
+		metrics.RecordOccasionOperation("upcoming", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days"})
+		return
+	}
+
+	now := time.Now()
+	occasions, err := h.occasionService.Upcoming(viewerID, c.Param("username"), days, now)
+	if err != nil {
+		metrics.RecordOccasionOperation("upcoming", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordOccasionOperation("upcoming", "success")
+	upcoming := make([]*models.UpcomingOccasion, len(occasions))
+	for i := range occasions {
+		upcoming[i] = occasions[i].ToUpcomingFor(viewerID, now)
+	}
+
+	c.JSON(http.StatusOK, upcoming)
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+	"wishlist-app/pkg/money"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PledgeHandler struct {
+	pledgeService *service.PledgeService
+	logger        logger.Logger
+	cfg           *config.Config
+}
+
+func NewPledgeHandler(cfg *config.Config, logger logger.Logger, pledgeService *service.PledgeService) *PledgeHandler {
+	return &PledgeHandler{
+		pledgeService: pledgeService,
+		cfg:           cfg,
+		logger:        logger,
+	}
+}
+
+type PledgeRequest struct {
+	Amount money.Decimal `json:"amount" binding:"required" swaggertype:"string" example:"25.00"`
+}
+
+// Create godoc
+// @Summary Pledge toward a wish
+// @Description Commit an amount in the wish's currency toward a group gift. The owner never sees who contributed.
+// @Tags pledges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Param request body PledgeRequest true "Pledge Request"
+// @Success 201 {object} models.PublicPledge "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/pledges [post]
+func (h *PledgeHandler) Create(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishOperation("pledge", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish ID"})
+		return
+	}
+
+	var req PledgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordWishOperation("pledge", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pledge, err := h.pledgeService.Pledge(userID, uint(wishID), string(req.Amount))
+	if err != nil {
+		metrics.RecordWishOperation("pledge", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("pledge", "success")
+	c.JSON(http.StatusCreated, pledge.ToPublic())
+}
+
+// Update godoc
+// @Summary Change a pledge
+// @Description Change the amount of the authenticated user's pledge
+// @Tags pledges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Pledge ID"
+// @Param request body PledgeRequest true "Pledge Request"
+// @Success 200 "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /pledges/{id} [put]
+func (h *PledgeHandler) Update(c *gin.Context) {
+	userID := c.GetUint("userID")
+	pledgeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishOperation("update_pledge", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pledge ID"})
+		return
+	}
+
+	var req PledgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordWishOperation("update_pledge", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.pledgeService.Update(userID, uint(pledgeID), string(req.Amount)); err != nil {
+		metrics.RecordWishOperation("update_pledge", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("update_pledge", "success")
+	c.Status(http.StatusOK)
+}
+
+// Delete godoc
+// @Summary Withdraw a pledge
+// @Description Withdraw the authenticated user's pledge
+// @Tags pledges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Pledge ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /pledges/{id} [delete]
+func (h *PledgeHandler) Delete(c *gin.Context) {
+	userID := c.GetUint("userID")
+	pledgeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishOperation("delete_pledge", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pledge ID"})
+		return
+	}
+
+	if err := h.pledgeService.Delete(userID, uint(pledgeID)); err != nil {
+		metrics.RecordWishOperation("delete_pledge", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("delete_pledge", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// GetByUserID godoc
+// @Summary Get pledges of authenticated user
+// @Description Get all pledges of the authenticated user with the funding progress of each wish
+// @Tags pledges
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PublicPledge "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /pledges [get]
+func (h *PledgeHandler) GetByUserID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	pledges, err := h.pledgeService.GetByUserID(userID)
+	if err != nil {
+		metrics.RecordWishOperation("read_pledges", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("read_pledges", "success")
+	publicPledges := make([]*models.PublicPledge, len(pledges))
+	for i, pledge := range pledges {
+		publicPledges[i] = pledge.ToPublic()
+	}
+
+	c.JSON(http.StatusOK, publicPledges)
+}
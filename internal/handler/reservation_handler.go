@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReservationHandler struct {
+	reservationService *service.ReservationService
+	logger             logger.Logger
+	cfg                *config.Config
+}
+
+func NewReservationHandler(cfg *config.Config, logger logger.Logger, reservationService *service.ReservationService) *ReservationHandler {
+	return &ReservationHandler{
+		reservationService: reservationService,
+		cfg:                cfg,
+		logger:             logger,
+	}
+}
+
+type ReservationRequest struct {
+	Quantity int `json:"quantity" binding:"omitempty,min=1" example:"2"`
+}
+
+// Reserve godoc
+// @Summary Reserve a wish
+// @Description Claim another user's wish, or part of its quantity, so nobody else buys the same gift. The quantity defaults to 1. The owner never sees reservations.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Param request body ReservationRequest false "Reservation Request"
+// @Success 201 {object} models.PublicReservation "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/reservation [post]
+func (h *ReservationHandler) Reserve(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishOperation("reserve", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish ID"})
+		return
+	}
+
+	var req ReservationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			metrics.RecordWishOperation("reserve", "failure")
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	reservation, err := h.reservationService.Reserve(userID, uint(wishID), req.Quantity)
+	if err != nil {
+		metrics.RecordWishOperation("reserve", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("reserve", "success")
+	c.JSON(http.StatusCreated, reservation.ToPublic())
+}
+
+// Unreserve godoc
+// @Summary Cancel a reservation
+// @Description Cancel the authenticated user's reservation of a wish
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/reservation [delete]
+func (h *ReservationHandler) Unreserve(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishOperation("unreserve", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish ID"})
+		return
+	}
+
+	if err := h.reservationService.Unreserve(userID, uint(wishID)); err != nil {
+		metrics.RecordWishOperation("unreserve", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("unreserve", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// GetByUserID godoc
+// @Summary Get reservations of authenticated user
+// @Description Get all wishes reserved by the authenticated user
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PublicReservation "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /reservations [get]
+func (h *ReservationHandler) GetByUserID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	reservations, err := h.reservationService.GetByUserID(userID)
+	if err != nil {
+		metrics.RecordWishOperation("read_reservations", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("read_reservations", "success")
+	publicReservations := make([]*models.PublicReservation, len(reservations))
+	for i, reservation := range reservations {
+		publicReservations[i] = reservation.ToPublic()
+	}
+
+	c.JSON(http.StatusOK, publicReservations)
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShareLinkHandler struct {
+	shareLinkService *service.ShareLinkService
+	logger           logger.Logger
+	cfg              *config.Config
+}
+
+func NewShareLinkHandler(cfg *config.Config, logger logger.Logger, shareLinkService *service.ShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		shareLinkService: shareLinkService,
+		cfg:              cfg,
+		logger:           logger,
+	}
+}
+
+type ShareLinkRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Create godoc
+// @Summary Create a share link
+// @Description Create an unguessable share link for a wishlist. The token is only returned once.
+// @Tags share-links
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wishlist ID"
+// @Param request body ShareLinkRequest false "Share Link Request"
+// @Success 201 {object} models.PublicShareLink "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /lists/{id}/share-links [post]
+func (h *ShareLinkHandler) Create(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishlistOperation("share", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist ID"})
+		return
+	}
+
+	var req ShareLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			metrics.RecordWishlistOperation("share", "failure")
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	link, token, err := h.shareLinkService.Create(userID, uint(wishlistID), req.ExpiresAt)
+	if err != nil {
+		metrics.RecordWishlistOperation("share", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("share", "success")
+	c.JSON(http.StatusCreated, link.ToPublicWithToken(token))
+}
+
+// GetByWishlistID godoc
+// @Summary List share links of a wishlist
+// @Description List all share links of a wishlist owned by the authenticated user. Tokens are not included.
+// @Tags share-links
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wishlist ID"
+// @Success 200 {array} models.PublicShareLink "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /lists/{id}/share-links [get]
+func (h *ShareLinkHandler) GetByWishlistID(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist ID"})
+		return
+	}
+
+	links, err := h.shareLinkService.GetByWishlistID(userID, uint(wishlistID))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	publicLinks := make([]*models.PublicShareLink, len(links))
+	for i, link := range links {
+		publicLinks[i] = link.ToPublic()
+	}
+
+	c.JSON(http.StatusOK, publicLinks)
+}
+
+// Revoke godoc
+// @Summary Revoke a share link
+// @Description Permanently disable a share link
+// @Tags share-links
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Share link ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /share-links/{id} [delete]
+func (h *ShareLinkHandler) Revoke(c *gin.Context) {
+	userID := c.GetUint("userID")
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishlistOperation("revoke_share", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid share link ID"})
+		return
+	}
+
+	if err := h.shareLinkService.Revoke(userID, uint(linkID)); err != nil {
+		metrics.RecordWishlistOperation("revoke_share", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("revoke_share", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// Rotate godoc
+// @Summary Rotate a share link
+// @Description Replace the token of a share link. The old token stops working immediately. Revoked links cannot be rotated.
+// @Tags share-links
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Share link ID"
+// @Param request body ShareLinkRequest false "Share Link Request"
+// @Success 200 {object} models.PublicShareLink "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /share-links/{id}/rotate [post]
+func (h *ShareLinkHandler) Rotate(c *gin.Context) {
+	userID := c.GetUint("userID")
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishlistOperation("rotate_share", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid share link ID"})
+		return
+	}
+
+	var req ShareLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			metrics.RecordWishlistOperation("rotate_share", "failure")
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	link, token, err := h.shareLinkService.Rotate(userID, uint(linkID), req.ExpiresAt)
+	if err != nil {
+		metrics.RecordWishlistOperation("rotate_share", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("rotate_share", "success")
+	c.JSON(http.StatusOK, link.ToPublicWithToken(token))
+}
+
+// Resolve godoc
+// @Summary Open a share link
+// @Description Get the shared wishlist and the wishes the token grants access to
+// @Tags share-links
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param token path string true "Share token"
+// @Success 200 {object} models.PublicWishlist "OK"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /shared/{token} [get]
+func (h *ShareLinkHandler) Resolve(c *gin.Context) {
+	viewerID := c.GetUint("userID")
+
+	wishlist, err := h.shareLinkService.Resolve(viewerID, c.Param("token"))
+	if err != nil {
+		metrics.RecordWishlistOperation("read_shared", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("read_shared", "success")
+	c.JSON(http.StatusOK, wishlist.ToPublicFor(viewerID))
+}
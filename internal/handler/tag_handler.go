@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tagService *service.TagService
+	logger     logger.Logger
+	cfg        *config.Config
+}
+
+func NewTagHandler(cfg *config.Config, logger logger.Logger, tagService *service.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+type TagRequest struct {
+	Name string `json:"name" binding:"required,max=50" example:"books"`
+}
+
+// Create godoc
+// @Summary Create a tag
+// @Description Create a tag for grouping the authenticated user's wishes. Names are case-insensitive.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body TagRequest true "Tag Request"
+// @Success 201 {object} models.TagCount "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tags [post]
+func (h *TagHandler) Create(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordTagOperation("create", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.Create(userID, req.Name)
+	if err != nil {
+		metrics.RecordTagOperation("create", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordTagOperation("create", "success")
+	c.JSON(http.StatusCreated, &models.TagCount{ID: tag.ID, Name: tag.Name})
+}
+
+// GetByUserID godoc
+// @Summary Get tags of authenticated user
+// @Description Get all tags of the authenticated user with the number of active wishes carrying each
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.TagCount "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tags [get]
+func (h *TagHandler) GetByUserID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	counts, err := h.tagService.GetCounts(userID)
+	if err != nil {
+		metrics.RecordTagOperation("read", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordTagOperation("read", "success")
+	c.JSON(http.StatusOK, counts)
+}
+
+// Rename godoc
+// @Summary Rename a tag
+// @Description Rename a tag of the authenticated user
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Tag ID"
+// @Param request body TagRequest true "Tag Request"
+// @Success 200 {object} models.TagCount "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tags/{id} [put]
+func (h *TagHandler) Rename(c *gin.Context) {
+	userID := c.GetUint("userID")
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordTagOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag ID"})
+		return
+	}
+
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordTagOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.Rename(userID, uint(tagID), req.Name)
+	if err != nil {
+		metrics.RecordTagOperation("update", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordTagOperation("update", "success")
+	c.JSON(http.StatusOK, &models.TagCount{ID: tag.ID, Name: tag.Name})
+}
+
+// Delete godoc
+// @Summary Delete a tag
+// @Description Delete a tag of the authenticated user and remove it from all wishes
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Tag ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /tags/{id} [delete]
+func (h *TagHandler) Delete(c *gin.Context) {
+	userID := c.GetUint("userID")
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordTagOperation("delete", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag ID"})
+		return
+	}
+
+	if err := h.tagService.Delete(userID, uint(tagID)); err != nil {
+		metrics.RecordTagOperation("delete", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordTagOperation("delete", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// Facets godoc
+// @Summary Get tag and category counts of a user
+// @Description Get the tags and categories of a user's wishes visible to the caller, with counts, for building filters
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Success 200 {object} models.WishFacets "OK"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{username}/tags [get]
+func (h *TagHandler) Facets(c *gin.Context) {
+	viewerID := c.GetUint("userID")
+
+	facets, err := h.tagService.GetFacets(viewerID, c.Param("username"))
+	if err != nil {
+		metrics.RecordTagOperation("facets", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordTagOperation("facets", "success")
+	c.JSON(http.StatusOK, facets)
+}
+
+// Categories godoc
+// @Summary List categories
+// @Description List the fixed system categories a wish can belong to
+// @Tags tags
+// @Produce json
+// @Success 200 {array} string "OK"
+// @Router /categories [get]
+func (h *TagHandler) Categories(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Categories)
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+	logger           logger.Logger
+	cfg              *config.Config
+}
+
+func NewTwoFactorHandler(cfg *config.Config, logger logger.Logger, twoFactorService *service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		cfg:              cfg,
+		logger:           logger,
+	}
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/Wishlist:alice?algorithm=SHA1&digits=6&issuer=Wishlist&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3m9q-xa2rt"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+// Status godoc
+// @Summary Get two-factor status
+// @Description Get whether two-factor authentication is on for the authenticated user and how many recovery codes are left
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.TwoFactorStatus "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /2fa [get]
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	status, err := h.twoFactorService.Status(c.GetUint("userID"))
+	if err != nil {
+		metrics.RecordAuthRequest("2fa_status", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("2fa_status", "success")
+	c.JSON(http.StatusOK, status)
+}
+
+// Enroll godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret for the authenticated user. Add it to an authenticator app by scanning the otpauth URI as a QR code or typing the secret, then confirm with a code. Enrolling again before confirming replaces the secret.
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} TOTPEnrollmentResponse "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	enrollment, err := h.twoFactorService.Enroll(c.GetUint("userID"))
+	if err != nil {
+		metrics.RecordAuthRequest("2fa_enroll", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("2fa_enroll", "success")
+	c.JSON(http.StatusOK, TOTPEnrollmentResponse{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+	})
+}
+
+// Confirm godoc
+// @Summary Confirm two-factor enrollment
+// @Description Turn two-factor authentication on with a code from the authenticator app. Returns ten one-time recovery codes, which are shown only this once.
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body TwoFactorCodeRequest true "Code Request"
+// @Success 200 {object} RecoveryCodesResponse "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordAuthRequest("2fa_confirm", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(c.GetUint("userID"), req.Code)
+	if err != nil {
+		metrics.RecordAuthRequest("2fa_confirm", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("2fa_confirm", "success")
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off. Requires the password and a code from the authenticator app or a recovery code. Remaining recovery codes are deleted.
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body DisableTwoFactorRequest true "Disable Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 429 {object} map[string]string "Too Many Requests"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordAuthRequest("2fa_disable", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.GetUint("userID"), req.Password, req.Code); err != nil {
+		metrics.RecordAuthRequest("2fa_disable", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordAuthRequest("2fa_disable", "success")
+	c.Status(http.StatusNoContent)
+}
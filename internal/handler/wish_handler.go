@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -11,41 +14,95 @@ import (
 	"wishlist-app/internal/service"
 	"wishlist-app/pkg/logger"
 	"wishlist-app/pkg/metrics"
+	"wishlist-app/pkg/money"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxSearchLength bounds the length of search queries in bytes.
+const maxSearchLength = 200
+
 type WishHandler struct {
-	wishService *service.WishService
-	logger      logger.Logger
-	cfg         *config.Config
+	wishService    *service.WishService
+	productService *service.ProductService
+	imageService   *service.ImageService
+	logger         logger.Logger
+	cfg            *config.Config
 }
 
-func NewWishHandler(cfg *config.Config, logger logger.Logger, wishService *service.WishService) *WishHandler {
+func NewWishHandler(cfg *config.Config, logger logger.Logger, wishService *service.WishService, productService *service.ProductService, imageService *service.ImageService) *WishHandler {
 	return &WishHandler{
-		wishService: wishService,
-		cfg:         cfg,
-		logger:      logger,
+		wishService:    wishService,
+		productService: productService,
+		imageService:   imageService,
+		cfg:            cfg,
+		logger:         logger,
 	}
 }
 
 type CreateWishRequest struct {
-	Title    string  `json:"title" binding:"required"`
-	Comment  string  `json:"comment"`
-	ImageURL string  `json:"image_url"`
-	Price    float64 `json:"price"`
+	WishlistID *uint             `json:"wishlist_id"`
+	Title      string            `json:"title" binding:"required_without=ProductURL"`
+	Comment    string            `json:"comment"`
+	ImageURL   string            `json:"image_url"`
+	ProductURL string            `json:"product_url" binding:"omitempty,max=2048" example:"https://shop.example.com/products/42"`
+	Price      money.Decimal     `json:"price" swaggertype:"string" example:"24.99"`
+	PriceMin   money.Decimal     `json:"price_min" swaggertype:"string" example:"50"`
+	PriceMax   money.Decimal     `json:"price_max" swaggertype:"string" example:"150"`
+	Currency   string            `json:"currency" example:"EUR"`
+	Quantity   int               `json:"quantity" binding:"omitempty,min=1" example:"6"`
+	Visibility models.Visibility `json:"visibility" binding:"omitempty,oneof=private link friends public"`
+	Priority   models.Priority   `json:"priority" binding:"omitempty,oneof=must_have want nice_to_have"`
+	Category   models.Category   `json:"category" example:"books"`
+	Tags       []string          `json:"tags" binding:"omitempty,max=20,dive,max=50"`
 }
 
 type UpdateWishRequest struct {
-	Title    string  `json:"title"`
-	Comment  string  `json:"comment"`
-	ImageURL string  `json:"image_url"`
-	Price    float64 `json:"price"`
+	WishlistID   *uint             `json:"wishlist_id"`
+	Title        string            `json:"title"`
+	Comment      string            `json:"comment"`
+	ImageURL     string            `json:"image_url"`
+	ProductURL   string            `json:"product_url" binding:"omitempty,max=2048" example:"https://shop.example.com/products/42"`
+	Price        money.Decimal     `json:"price" swaggertype:"string" example:"24.99"`
+	PriceMin     money.Decimal     `json:"price_min" swaggertype:"string" example:"50"`
+	PriceMax     money.Decimal     `json:"price_max" swaggertype:"string" example:"150"`
+	PriceUnknown bool              `json:"price_unknown"`
+	Currency     string            `json:"currency" example:"EUR"`
+	Quantity     int               `json:"quantity" binding:"omitempty,min=1" example:"6"`
+	Visibility   models.Visibility `json:"visibility" binding:"omitempty,oneof=private link friends public"`
+	Priority     models.Priority   `json:"priority" binding:"omitempty,oneof=must_have want nice_to_have"`
+	Category     models.Category   `json:"category" example:"books"`
+	Tags         []string          `json:"tags" binding:"omitempty,max=20,dive,max=50"`
+}
+
+type ProductPreviewRequest struct {
+	URL string `json:"url" binding:"required,max=2048" example:"https://shop.example.com/products/42"`
+}
+
+type ReceivedRequest struct {
+	Received *int `json:"received" binding:"required,min=0" example:"2"`
+}
+
+type CopyWishRequest struct {
+	WishlistID *uint `json:"wishlist_id"`
+}
+
+type ThankYouNoteRequest struct {
+	ThankYouNote string `json:"thank_you_note" binding:"max=1000"`
+}
+
+type WishMoveRequest struct {
+	WishID  uint  `json:"wish_id" binding:"required"`
+	AfterID *uint `json:"after_id"`
+}
+
+type ReorderWishesRequest struct {
+	Moves []WishMoveRequest `json:"moves" binding:"required,min=1,dive"`
 }
 
 // Create godoc
 // @Summary Create a new wish
-// @Description Create a new wish for the authenticated user
+// @Description Create a new wish for the authenticated user. With a product URL, the title, image and an unknown price are filled in from the product page where not given.
 // @Tags wishes
 // @Accept json
 // @Produce json
@@ -54,7 +111,9 @@ type UpdateWishRequest struct {
 // @Success 201 {object} models.PublicWish "Created"
 // @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
 // @Failure 500 {object} map[string]string "Internal Server Error"
+// @Failure 502 {object} map[string]string "Bad Gateway"
 // @Router /wishes [post]
 func (h *WishHandler) Create(c *gin.Context) {
 	userID := c.GetUint("userID")
@@ -67,17 +126,35 @@ func (h *WishHandler) Create(c *gin.Context) {
 	}
 
 	wish := &models.Wish{
-		UserID:   userID,
-		Title:    req.Title,
-		Comment:  req.Comment,
-		ImageURL: req.ImageURL,
-		Price:    req.Price,
+		UserID:     userID,
+		WishlistID: req.WishlistID,
+		Title:      req.Title,
+		Comment:    req.Comment,
+		ImageURL:   req.ImageURL,
+		ProductURL: req.ProductURL,
+		Quantity:   req.Quantity,
+		Visibility: req.Visibility,
+		Priority:   req.Priority,
+		Category:   req.Category,
+		Tags:       tagsFromNames(req.Tags),
+	}
+
+	if err := setPrice(wish, req.Price, req.PriceMin, req.PriceMax, req.Currency, h.cfg.Money.DefaultCurrency); err != nil {
+		metrics.RecordWishOperation("create", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.productService.Prefill(c.Request.Context(), wish); err != nil {
+		metrics.RecordWishOperation("create", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
 
 	createdWish, err := h.wishService.Create(userID, wish)
 	if err != nil {
 		metrics.RecordWishOperation("create", "failure")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -85,9 +162,41 @@ func (h *WishHandler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, createdWish.ToPublic())
 }
 
+// PreviewProduct godoc
+// @Summary Preview a product page
+// @Description Fetch a product page and extract the title, description, image and price from its schema.org, OpenGraph and Twitter card metadata, to fill in a new wish
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body ProductPreviewRequest true "Product Preview Request"
+// @Success 200 {object} product.Metadata "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 502 {object} map[string]string "Bad Gateway"
+// @Router /wishes/preview [post]
+func (h *WishHandler) PreviewProduct(c *gin.Context) {
+	var req ProductPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordWishOperation("preview", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	meta, err := h.productService.Preview(c.Request.Context(), req.URL)
+	if err != nil {
+		metrics.RecordWishOperation("preview", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("preview", "success")
+	c.JSON(http.StatusOK, meta)
+}
+
 // Update godoc
 // @Summary Update a wish
-// @Description Update an existing wish for the authenticated user
+// @Description Update an existing wish for the authenticated user. The price is kept unless a price, a price range or price_unknown is sent. Prices without a currency are in the wish's current currency, which cannot change while the wish has pledges.
 // @Tags wishes
 // @Accept json
 // @Produce json
@@ -97,6 +206,9 @@ func (h *WishHandler) Create(c *gin.Context) {
 // @Success 200 "OK"
 // @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /wishes/{id} [put]
 func (h *WishHandler) Update(c *gin.Context) {
@@ -116,16 +228,56 @@ func (h *WishHandler) Update(c *gin.Context) {
 	}
 
 	wish := &models.Wish{
-		Model:    gorm.Model{ID: uint(wishID)},
-		Title:    req.Title,
-		Comment:  req.Comment,
-		ImageURL: req.ImageURL,
-		Price:    req.Price,
+		Model:      gorm.Model{ID: uint(wishID)},
+		WishlistID: req.WishlistID,
+		Title:      req.Title,
+		Comment:    req.Comment,
+		ImageURL:   req.ImageURL,
+		ProductURL: req.ProductURL,
+		Quantity:   req.Quantity,
+		Visibility: req.Visibility,
+		Priority:   req.Priority,
+		Category:   req.Category,
+		Tags:       tagsFromNames(req.Tags),
+	}
+
+	existing, err := h.wishService.GetByID(userID, uint(wishID))
+	if err != nil {
+		metrics.RecordWishOperation("update", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// Amounts sent without a currency are in the wish's current one.
+	currency := h.cfg.Money.DefaultCurrency
+	if existing.Currency != "" {
+		currency = existing.Currency
+	}
+
+	hasPrice := req.Price != "" || req.PriceMin != "" || req.PriceMax != ""
+	switch {
+	case req.PriceUnknown && hasPrice:
+		err = service.ErrInvalidPriceRange
+	case req.PriceUnknown:
+		wish.PriceUnknown = true
+	case !hasPrice:
+		// Without any price fields the price stays as it is.
+		wish.PriceMinor = existing.PriceMinor
+		wish.PriceMaxMinor = existing.PriceMaxMinor
+		wish.PriceUnknown = existing.PriceUnknown
+		wish.Currency = existing.Currency
+	default:
+		err = setPrice(wish, req.Price, req.PriceMin, req.PriceMax, req.Currency, currency)
+	}
+	if err != nil {
+		metrics.RecordWishOperation("update", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
 
 	if err := h.wishService.Update(userID, wish); err != nil {
 		metrics.RecordWishOperation("update", "failure")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -135,7 +287,7 @@ func (h *WishHandler) Update(c *gin.Context) {
 
 // Delete godoc
 // @Summary Delete a wish
-// @Description Delete an existing wish for the authenticated user
+// @Description Delete an existing wish for the authenticated user, along with its uploaded image
 // @Tags wishes
 // @Accept json
 // @Produce json
@@ -144,6 +296,7 @@ func (h *WishHandler) Update(c *gin.Context) {
 // @Success 204 "No Content"
 // @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /wishes/{id} [delete]
 func (h *WishHandler) Delete(c *gin.Context) {
@@ -155,38 +308,187 @@ func (h *WishHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	wish, err := h.wishService.GetByID(userID, uint(wishID))
+	if err != nil {
+		metrics.RecordWishOperation("delete", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := h.wishService.Delete(userID, uint(wishID)); err != nil {
 		metrics.RecordWishOperation("delete", "failure")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	// The wish is gone either way, so files that could not be removed are
+	// only logged.
+	if err := h.imageService.DeleteImages(c.Request.Context(), wish); err != nil {
+		h.logger.Errorf("Failed to delete images of wish %d: %v", wish.ID, err)
+	}
+
 	metrics.RecordWishOperation("delete", "success")
 	c.Status(http.StatusNoContent)
 }
 
 // GetByUserID godoc
 // @Summary Get wishes for authenticated user
-// @Description Get all wishes for the authenticated user
+// @Description Get the wishes of the authenticated user in the given lifecycle state (active by default), in their manual order unless sorted otherwise, one page at a time. Prices in other currencies are compared using the exchange rates.
 // @Tags wishes
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {array} models.PublicWish "OK"
+// @Param status query string false "Wish status" Enums(active, received, archived)
+// @Param tag query string false "Tag name"
+// @Param category query string false "Category"
+// @Param min_price query string false "Only wishes whose price range reaches this amount"
+// @Param max_price query string false "Only wishes whose price range starts at or below this amount"
+// @Param has_image query bool false "Only wishes with (true) or without (false) an image"
+// @Param created_since query string false "Only wishes created at or after this RFC 3339 time or date"
+// @Param currency query string false "ISO 4217 currency of the price filters and sorting"
+// @Param sort query string false "Sort order, the manual order by default" Enums(created_asc, created_desc, updated_asc, updated_desc, price_asc, price_desc, title_asc, title_desc)
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} models.WishPage "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /wishes [get]
 func (h *WishHandler) GetByUserID(c *gin.Context) {
 	userID := c.GetUint("userID")
 
-	wishes, err := h.wishService.GetByUserID(userID)
+	status := models.WishStatus(c.Query("status"))
+	switch status {
+	case "", models.WishActive, models.WishReceived, models.WishArchived:
+	default:
+		metrics.RecordWishOperation("read", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	filter, err := h.wishFilter(c)
 	if err != nil {
 		metrics.RecordWishOperation("read", "failure")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	filter.Status = status
+	limit, err := queryLimit(c)
+	if err != nil {
+		metrics.RecordWishOperation("read", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	wishes, next, err := h.wishService.GetByUserID(userID, filter, c.Query("cursor"), limit)
+	if err != nil {
+		metrics.RecordWishOperation("read", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	metrics.RecordWishOperation("read", "success")
+	page := models.WishPage{
+		Items:      make([]*models.PublicWish, len(wishes)),
+		NextCursor: next,
+	}
+	for i, wish := range wishes {
+		page.Items[i] = wish.ToPublic()
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Search godoc
+// @Summary Search wishes
+// @Description Full-text search over the titles and comments of the authenticated user's own wishes and the active wishes of others visible to them, most relevant first. The query supports "quoted phrases", OR and -excluded words. Headlines and snippets are HTML-escaped with the matching words in <mark> tags.
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param q query string true "Search query"
+// @Param owner query string false "Only wishes of the user with this username"
+// @Param tag query string false "Tag name"
+// @Param category query string false "Category"
+// @Param min_price query string false "Only wishes whose price range reaches this amount"
+// @Param max_price query string false "Only wishes whose price range starts at or below this amount"
+// @Param currency query string false "ISO 4217 currency of the price filters"
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} models.WishSearchPage "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /search [get]
+func (h *WishHandler) Search(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	query := c.Query("q")
+	if len(query) > maxSearchLength {
+		metrics.RecordWishOperation("search", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "search query too long"})
+		return
+	}
+	limit, err := queryLimit(c)
+	if err != nil {
+		metrics.RecordWishOperation("search", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	filter, err := h.wishFilter(c)
+	if err != nil {
+		metrics.RecordWishOperation("search", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	search := models.WishSearch{Query: query, Owner: c.Query("owner"), Filter: filter}
+	results, next, err := h.wishService.Search(userID, search, c.Query("cursor"), limit)
+	if err != nil {
+		metrics.RecordWishOperation("search", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("search", "success")
+	page := models.WishSearchPage{
+		Items:      make([]*models.PublicWishSearchResult, len(results)),
+		NextCursor: next,
+	}
+	for i, result := range results {
+		page.Items[i] = &models.PublicWishSearchResult{
+			Wish:     result.Wish.ToPublicFor(userID),
+			Rank:     result.Rank,
+			Headline: result.Headline,
+			Snippet:  result.Snippet,
+		}
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// History godoc
+// @Summary Get received gifts
+// @Description Get the wishes the authenticated user has received, most recent first, with when they arrived and the thank-you note
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PublicWish "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /history [get]
+func (h *WishHandler) History(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	wishes, err := h.wishService.GetHistory(userID)
+	if err != nil {
+		metrics.RecordWishOperation("history", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("history", "success")
 	publicWishes := make([]*models.PublicWish, len(wishes))
 	for i, wish := range wishes {
 		publicWishes[i] = wish.ToPublic()
@@ -195,31 +497,441 @@ func (h *WishHandler) GetByUserID(c *gin.Context) {
 	c.JSON(http.StatusOK, publicWishes)
 }
 
+// Copy godoc
+// @Summary Save someone else's wish
+// @Description Copy a wish the caller can see on another user's list into one of the caller's lists, the default list unless wishlist_id is given. The copy keeps a reference to the original, whose owner sees how many people saved it.
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Param request body CopyWishRequest false "Copy Wish Request"
+// @Success 201 {object} models.PublicWish "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/copy [post]
+func (h *WishHandler) Copy(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, ok := h.wishID(c, "copy")
+	if !ok {
+		return
+	}
+
+	var req CopyWishRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			metrics.RecordWishOperation("copy", "failure")
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	wish, err := h.wishService.Copy(userID, wishID, req.WishlistID)
+	if err != nil {
+		metrics.RecordWishOperation("copy", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// The copy is saved either way; without the image it still links to the
+	// product.
+	if copied, err := h.imageService.CopySourceImage(c.Request.Context(), wish); err != nil {
+		h.logger.Errorf("Failed to copy the image of wish %d to wish %d: %v", *wish.SourceWishID, wish.ID, err)
+	} else {
+		wish = copied
+	}
+
+	metrics.RecordWishOperation("copy", "success")
+	c.JSON(http.StatusCreated, wish.ToPublic())
+}
+
+// Receive godoc
+// @Summary Mark a wish as received
+// @Description Mark an active wish as received, optionally with a thank-you note shown to the gifters. Received wishes leave public views but stay in the history.
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Param request body ThankYouNoteRequest false "Thank-you Note"
+// @Success 200 {object} models.PublicWish "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/receive [post]
+func (h *WishHandler) Receive(c *gin.Context) {
+	wishID, ok := h.wishID(c, "receive")
+	if !ok {
+		return
+	}
+
+	var req ThankYouNoteRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			metrics.RecordWishOperation("receive", "failure")
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	h.respondWish(c, "receive", func(userID uint) (*models.Wish, error) {
+		return h.wishService.Receive(userID, wishID, req.ThankYouNote)
+	})
+}
+
+// Archive godoc
+// @Summary Archive a wish
+// @Description Hide a wish without deleting it
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Success 200 {object} models.PublicWish "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/archive [post]
+func (h *WishHandler) Archive(c *gin.Context) {
+	wishID, ok := h.wishID(c, "archive")
+	if !ok {
+		return
+	}
+
+	h.respondWish(c, "archive", func(userID uint) (*models.Wish, error) {
+		return h.wishService.Archive(userID, wishID)
+	})
+}
+
+// Restore godoc
+// @Summary Restore a wish
+// @Description Make a received or archived wish active again
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Success 200 {object} models.PublicWish "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/restore [post]
+func (h *WishHandler) Restore(c *gin.Context) {
+	wishID, ok := h.wishID(c, "restore")
+	if !ok {
+		return
+	}
+
+	h.respondWish(c, "restore", func(userID uint) (*models.Wish, error) {
+		return h.wishService.Restore(userID, wishID)
+	})
+}
+
+// SetThankYouNote godoc
+// @Summary Set the thank-you note of a received wish
+// @Description Change the thank-you note shown to the gifters of a received wish
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Param request body ThankYouNoteRequest true "Thank-you Note"
+// @Success 200 {object} models.PublicWish "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 409 {object} map[string]string "Conflict"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/thank-you-note [put]
+func (h *WishHandler) SetThankYouNote(c *gin.Context) {
+	wishID, ok := h.wishID(c, "thank_you_note")
+	if !ok {
+		return
+	}
+
+	var req ThankYouNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordWishOperation("thank_you_note", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondWish(c, "thank_you_note", func(userID uint) (*models.Wish, error) {
+		return h.wishService.SetThankYouNote(userID, wishID, req.ThankYouNote)
+	})
+}
+
+// SetReceived godoc
+// @Summary Set the received count of a wish
+// @Description Record how many items of a wish the authenticated owner has already received
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wish ID"
+// @Param request body ReceivedRequest true "Received Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/{id}/received [put]
+func (h *WishHandler) SetReceived(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishOperation("received", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish ID"})
+		return
+	}
+
+	var req ReceivedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordWishOperation("received", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.wishService.SetReceived(userID, uint(wishID), *req.Received); err != nil {
+		metrics.RecordWishOperation("received", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("received", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// Reorder godoc
+// @Summary Reorder wishes
+// @Description Apply drag-and-drop moves to the manual order of the authenticated user's wishes. Each move places a wish right after after_id, or first when after_id is omitted. Moves are applied in order.
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body ReorderWishesRequest true "Reorder Wishes Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /wishes/reorder [post]
+func (h *WishHandler) Reorder(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req ReorderWishesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordWishOperation("reorder", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	moves := make([]service.WishMove, len(req.Moves))
+	for i, move := range req.Moves {
+		moves[i] = service.WishMove{WishID: move.WishID, AfterID: move.AfterID}
+	}
+
+	if err := h.wishService.Reorder(userID, moves); err != nil {
+		metrics.RecordWishOperation("reorder", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation("reorder", "success")
+	c.Status(http.StatusNoContent)
+}
+
 // GetByUsername godoc
 // @Summary Get wishes by username
-// @Description Get all public wishes for a specific user by username
+// @Description Get the wishes of a specific user that the caller is allowed to see, in the owner's manual order unless sorted otherwise, one page at a time. Authenticated viewers other than the owner also see reservation status.
 // @Tags wishes
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
 // @Param username path string true "Username"
-// @Success 200 {array} models.PublicWish "OK"
+// @Param tag query string false "Tag name"
+// @Param category query string false "Category"
+// @Param min_price query string false "Only wishes whose price range reaches this amount"
+// @Param max_price query string false "Only wishes whose price range starts at or below this amount"
+// @Param has_image query bool false "Only wishes with (true) or without (false) an image"
+// @Param created_since query string false "Only wishes created at or after this RFC 3339 time or date"
+// @Param currency query string false "ISO 4217 currency of the price filters and sorting"
+// @Param sort query string false "Sort order, the manual order by default" Enums(created_asc, created_desc, updated_asc, updated_desc, price_asc, price_desc, title_asc, title_desc)
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} models.WishPage "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /wishes/{username} [get]
 func (h *WishHandler) GetByUsername(c *gin.Context) {
+	viewerID := c.GetUint("userID")
 	username := c.Param("username")
 
-	wishes, err := h.wishService.GetByUsername(username)
+	filter, err := h.wishFilter(c)
 	if err != nil {
 		metrics.RecordWishOperation("read", "failure")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	limit, err := queryLimit(c)
+	if err != nil {
+		metrics.RecordWishOperation("read", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	wishes, next, err := h.wishService.GetByUsername(viewerID, username, filter, c.Query("cursor"), limit)
+	if err != nil {
+		metrics.RecordWishOperation("read", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	metrics.RecordWishOperation("read", "success")
-	publicWishes := make([]*models.PublicWish, len(wishes))
+	page := models.WishPage{
+		Items:      make([]*models.PublicWish, len(wishes)),
+		NextCursor: next,
+	}
 	for i, wish := range wishes {
-		publicWishes[i] = wish.ToPublic()
+		page.Items[i] = wish.ToPublicFor(viewerID)
 	}
 
-	c.JSON(http.StatusOK, publicWishes)
+	c.JSON(http.StatusOK, page)
+}
+
+// setPrice sets the price of the wish from a request. A price is exact, while
+// price_min and price_max give a range; without either the price is unknown.
+// A price of zero marks a wish as free.
+func setPrice(wish *models.Wish, price, lower, upper money.Decimal, currency, fallback string) error {
+	var err error
+	switch {
+	case price != "" && (lower != "" || upper != ""):
+		return service.ErrInvalidPriceRange
+	case price != "":
+		wish.PriceMinor, wish.Currency, err = parseAmount(price, currency, fallback)
+		wish.PriceMaxMinor = wish.PriceMinor
+	case lower != "" || upper != "":
+		if lower == "" || upper == "" {
+			return service.ErrInvalidPriceRange
+		}
+		if wish.PriceMinor, wish.Currency, err = parseAmount(lower, currency, fallback); err != nil {
+			return err
+		}
+		wish.PriceMaxMinor, _, err = parseAmount(upper, currency, fallback)
+	default:
+		wish.PriceUnknown = true
+	}
+	return err
+}
+
+// wishFilter reads the tag, category, price and sort query parameters. Prices
+// are in the given currency, or the default one.
+func (h *WishHandler) wishFilter(c *gin.Context) (models.WishFilter, error) {
+	filter := models.WishFilter{
+		Tag:      c.Query("tag"),
+		Category: models.Category(c.Query("category")),
+		Sort:     models.WishSort(c.Query("sort")),
+		Currency: strings.ToUpper(c.Query("currency")),
+	}
+	if filter.Currency == "" {
+		filter.Currency = h.cfg.Money.DefaultCurrency
+	}
+
+	var err error
+	if filter.MinPrice, err = queryAmount(c, "min_price", filter.Currency); err != nil {
+		return filter, err
+	}
+	if filter.MaxPrice, err = queryAmount(c, "max_price", filter.Currency); err != nil {
+		return filter, err
+	}
+	if value := c.Query("has_image"); value != "" {
+		hasImage, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("%w: has_image must be true or false", service.ErrInvalidFilter)
+		}
+		filter.HasImage = &hasImage
+	}
+	if value := c.Query("created_since"); value != "" {
+		since, err := parseSince(value)
+		if err != nil {
+			return filter, fmt.Errorf("%w: created_since must be an RFC 3339 time or a date", service.ErrInvalidFilter)
+		}
+		filter.CreatedSince = &since
+	}
+	return filter, nil
+}
+
+// parseSince accepts a full RFC 3339 time or a date, meaning its start in UTC.
+func parseSince(value string) (time.Time, error) {
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// queryLimit reads the page size of a paginated list. Zero means the default.
+func queryLimit(c *gin.Context) (int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid limit", service.ErrInvalidFilter)
+	}
+	return limit, nil
+}
+
+func queryAmount(c *gin.Context, param, currency string) (*int64, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	amount, _, err := parseAmount(money.Decimal(value), currency, "")
+	if err != nil {
+		return nil, err
+	}
+	return &amount, nil
+}
+
+func (h *WishHandler) wishID(c *gin.Context, operation string) (uint, bool) {
+	wishID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishOperation(operation, "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish ID"})
+		return 0, false
+	}
+	return uint(wishID), true
+}
+
+// respondWish runs an owner action on a wish and responds with the result.
+func (h *WishHandler) respondWish(c *gin.Context, operation string, action func(userID uint) (*models.Wish, error)) {
+	wish, err := action(c.GetUint("userID"))
+	if err != nil {
+		metrics.RecordWishOperation(operation, "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishOperation(operation, "success")
+	c.JSON(http.StatusOK, wish.ToPublic())
+}
+
+// tagsFromNames keeps a missing tag list nil, so updates without tags leave
+// the wish's tags unchanged.
+func tagsFromNames(names []string) []models.Tag {
+	if names == nil {
+		return nil
+	}
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{Name: name}
+	}
+	return tags
 }
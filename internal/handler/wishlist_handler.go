@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WishlistHandler struct {
+	wishlistService *service.WishlistService
+	logger          logger.Logger
+	cfg             *config.Config
+}
+
+func NewWishlistHandler(cfg *config.Config, logger logger.Logger, wishlistService *service.WishlistService) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: wishlistService,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+type CreateWishlistRequest struct {
+	Title         string            `json:"title" binding:"required,max=100"`
+	Description   string            `json:"description" binding:"max=1000"`
+	CoverImageURL string            `json:"cover_image_url"`
+	Visibility    models.Visibility `json:"visibility" binding:"omitempty,oneof=private link friends public"`
+}
+
+type UpdateWishlistRequest struct {
+	Title         string            `json:"title" binding:"required,max=100"`
+	Description   string            `json:"description" binding:"max=1000"`
+	CoverImageURL string            `json:"cover_image_url"`
+	Position      int               `json:"position"`
+	Visibility    models.Visibility `json:"visibility" binding:"omitempty,oneof=private link friends public"`
+}
+
+// Create godoc
+// @Summary Create a new wishlist
+// @Description Create a new named wishlist for the authenticated user
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body CreateWishlistRequest true "Create Wishlist Request"
+// @Success 201 {object} models.PublicWishlist "Created"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /lists [post]
+func (h *WishlistHandler) Create(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req CreateWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordWishlistOperation("create", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlist := &models.Wishlist{
+		Title:         req.Title,
+		Description:   req.Description,
+		CoverImageURL: req.CoverImageURL,
+		Visibility:    req.Visibility,
+	}
+
+	createdWishlist, err := h.wishlistService.Create(userID, wishlist)
+	if err != nil {
+		metrics.RecordWishlistOperation("create", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("create", "success")
+	c.JSON(http.StatusCreated, createdWishlist.ToPublic())
+}
+
+// Update godoc
+// @Summary Update a wishlist
+// @Description Update title, description, cover image, position and visibility of a wishlist
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wishlist ID"
+// @Param request body UpdateWishlistRequest true "Update Wishlist Request"
+// @Success 200 "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /lists/{id} [put]
+func (h *WishlistHandler) Update(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishlistOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist ID"})
+		return
+	}
+
+	var req UpdateWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordWishlistOperation("update", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlist := &models.Wishlist{
+		Model:         gorm.Model{ID: uint(wishlistID)},
+		Title:         req.Title,
+		Description:   req.Description,
+		CoverImageURL: req.CoverImageURL,
+		Position:      req.Position,
+		Visibility:    req.Visibility,
+	}
+
+	if err := h.wishlistService.Update(userID, wishlist); err != nil {
+		metrics.RecordWishlistOperation("update", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("update", "success")
+	c.Status(http.StatusOK)
+}
+
+type CoOrganizerRequest struct {
+	Login string `json:"login" example:"alice"`
+}
+
+// SetCoOrganizer godoc
+// @Summary Set the co-organizer of a wishlist
+// @Description Designate another user as co-organizer of a wishlist. The co-organizer may delete comments in the threads on its wishes. An empty login removes the co-organizer.
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wishlist ID"
+// @Param request body CoOrganizerRequest true "Co-organizer Request"
+// @Success 200 {object} models.PublicWishlist "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /lists/{id}/co-organizer [put]
+func (h *WishlistHandler) SetCoOrganizer(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishlistOperation("set_co_organizer", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist ID"})
+		return
+	}
+
+	var req CoOrganizerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		metrics.RecordWishlistOperation("set_co_organizer", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlist, err := h.wishlistService.SetCoOrganizer(userID, uint(wishlistID), req.Login)
+	if err != nil {
+		metrics.RecordWishlistOperation("set_co_organizer", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("set_co_organizer", "success")
+	c.JSON(http.StatusOK, wishlist.ToPublic())
+}
+
+// Delete godoc
+// @Summary Delete a wishlist
+// @Description Delete a wishlist. Its wishes are moved to the default list.
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wishlist ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /lists/{id} [delete]
+func (h *WishlistHandler) Delete(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishlistOperation("delete", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist ID"})
+		return
+	}
+
+	if err := h.wishlistService.Delete(userID, uint(wishlistID)); err != nil {
+		metrics.RecordWishlistOperation("delete", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("delete", "success")
+	c.Status(http.StatusNoContent)
+}
+
+// GetByUserID godoc
+// @Summary Get wishlists for authenticated user
+// @Description Get all wishlists of the authenticated user in their display order
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.PublicWishlist "OK"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /lists [get]
+func (h *WishlistHandler) GetByUserID(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	wishlists, err := h.wishlistService.GetByUserID(userID)
+	if err != nil {
+		metrics.RecordWishlistOperation("read", "failure")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("read", "success")
+	c.JSON(http.StatusOK, toPublicWishlists(wishlists))
+}
+
+// GetByUsername godoc
+// @Summary Get wishlists by username
+// @Description Get the wishlists of a specific user that the caller is allowed to see
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Success 200 {array} models.PublicWishlist "OK"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{username}/lists [get]
+func (h *WishlistHandler) GetByUsername(c *gin.Context) {
+	viewerID := c.GetUint("userID")
+	username := c.Param("username")
+
+	wishlists, err := h.wishlistService.GetByUsername(viewerID, username)
+	if err != nil {
+		metrics.RecordWishlistOperation("read", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("read", "success")
+	c.JSON(http.StatusOK, toPublicWishlists(wishlists))
+}
+
+// GetByID godoc
+// @Summary Get a wishlist with its wishes
+// @Description Get a single wishlist and the wishes on it that the caller is allowed to see. Authenticated viewers other than the owner also see reservation status.
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Wishlist ID"
+// @Success 200 {object} models.PublicWishlist "OK"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /lists/{id} [get]
+func (h *WishlistHandler) GetByID(c *gin.Context) {
+	viewerID := c.GetUint("userID")
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		metrics.RecordWishlistOperation("read", "failure")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist ID"})
+		return
+	}
+
+	wishlist, err := h.wishlistService.GetWithWishes(viewerID, uint(wishlistID))
+	if err != nil {
+		metrics.RecordWishlistOperation("read", "failure")
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.RecordWishlistOperation("read", "success")
+	c.JSON(http.StatusOK, wishlist.ToPublicFor(viewerID))
+}
+
+func toPublicWishlists(wishlists []models.Wishlist) []*models.PublicWishlist {
+	publicWishlists := make([]*models.PublicWishlist, len(wishlists))
+	for i, wishlist := range wishlists {
+		publicWishlists[i] = wishlist.ToPublic()
+	}
+	return publicWishlists
+}
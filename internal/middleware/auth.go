@@ -1,16 +1,18 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"wishlist-app/internal/config"
 	"wishlist-app/internal/service"
 	"wishlist-app/pkg/logger"
 )
 
-func Auth(cfg *config.Config, logger logger.Logger) gin.HandlerFunc {
+// Auth requires a valid access token of an active session and identifies the
+// caller by it.
+func Auth(authService *service.AuthService, logger logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -24,8 +26,7 @@ func Auth(cfg *config.Config, logger logger.Logger) gin.HandlerFunc {
 			return
 		}
 
-		authService := service.NewAuthService(nil, cfg)
-		claims, err := authService.ValidateToken(tokenString)
+		claims, err := authService.Authenticate(tokenString)
 		if err != nil {
 			logger.Warnf("Invalid token: %v", err)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
@@ -33,6 +34,47 @@ func Auth(cfg *config.Config, logger logger.Logger) gin.HandlerFunc {
 		}
 
 		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
+		c.Next()
+	}
+}
+
+// OptionalAuth identifies the caller when a valid bearer token is present and
+// lets anonymous requests through otherwise.
+func OptionalAuth(authService *service.AuthService, logger logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if authHeader == "" || tokenString == authHeader {
+			c.Next()
+			return
+		}
+
+		claims, err := authService.Authenticate(tokenString)
+		if err != nil {
+			logger.Debugf("Ignoring invalid token on public route: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Set("userID", claims.UserID)
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail lets only users with a verified email address through.
+// It must run after Auth, for routes of features that email the user.
+func RequireVerifiedEmail(emailService *service.EmailService, logger logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := emailService.VerifiedEmail(c.GetUint("userID")); err != nil {
+			if errors.Is(err, service.ErrEmailNotVerified) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+			logger.Errorf("Failed to check email verification: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WishComment is a message in the thread gifters keep on a wish. Like
+// reservations, threads are never shown to the owner of the wish.
+type WishComment struct {
+	gorm.Model
+	WishID   uint   `gorm:"not null;index"`
+	UserID   uint   `gorm:"not null;index"`
+	Body     string `gorm:"size:2000;not null"`
+	EditedAt *time.Time
+	User     User `gorm:"foreignKey:UserID"`
+}
+
+type PublicWishComment struct {
+	ID        uint       `json:"id"`
+	WishID    uint       `json:"wish_id"`
+	Body      string     `json:"body"`
+	User      PublicUser `json:"user"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+func (c *WishComment) ToPublic() *PublicWishComment {
+	return &PublicWishComment{
+		ID:        c.ID,
+		WishID:    c.WishID,
+		Body:      c.Body,
+		User:      *c.User.ToPublic(),
+		CreatedAt: c.CreatedAt,
+		EditedAt:  c.EditedAt,
+	}
+}
+
+// WishCommentPage is one page of a wish's comment thread, oldest first.
+type WishCommentPage struct {
+	Items      []*PublicWishComment `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// ExchangeRate is the amount of a currency worth one unit of the base currency
+// shared by all rates. The rate is kept as the exact decimal text it was
+// loaded from.
+type ExchangeRate struct {
+	Currency  string `gorm:"type:varchar(3);primaryKey"`
+	Rate      string `gorm:"size:40;not null"`
+	UpdatedAt time.Time
+}
+
+type PublicExchangeRate struct {
+	Currency  string    `json:"currency" example:"USD"`
+	Rate      string    `json:"rate" example:"1.0842"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r *ExchangeRate) ToPublic() *PublicExchangeRate {
+	return &PublicExchangeRate{
+		Currency:  r.Currency,
+		Rate:      r.Rate,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// WishTotal is the combined price range of the items a user still wishes for,
+// converted into a single currency. Wishes with an unknown price are only
+// counted.
+type WishTotal struct {
+	Min      string `json:"min" example:"199.90"`
+	Max      string `json:"max" example:"289.90"`
+	Currency string `json:"currency" example:"EUR"`
+	Wishes   int    `json:"wishes"`
+	Unknown  int    `json:"unknown"`
+}
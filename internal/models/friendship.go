@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+)
+
+type FriendshipStatus string
+
+const (
+	FriendshipPending  FriendshipStatus = "pending"
+	FriendshipAccepted FriendshipStatus = "accepted"
+)
+
+// Friendship is a mutual relationship between two users. It starts as a
+// pending request from the requester and becomes accepted once the addressee
+// agrees. Declined requests are deleted.
+type Friendship struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	RequesterID uint             `gorm:"not null;index"`
+	AddresseeID uint             `gorm:"not null;index"`
+	Status      FriendshipStatus `gorm:"type:varchar(16);not null;default:pending"`
+	AcceptedAt  *time.Time
+	Requester   User `gorm:"foreignKey:RequesterID"`
+	Addressee   User `gorm:"foreignKey:AddresseeID"`
+}
+
+type PublicFriendship struct {
+	ID         uint             `json:"id"`
+	User       PublicUser       `json:"user"`
+	Status     FriendshipStatus `json:"status"`
+	CreatedAt  time.Time        `json:"created_at"`
+	AcceptedAt *time.Time       `json:"accepted_at,omitempty"`
+}
+
+// OtherUser returns the participant of the friendship who is not userID.
+func (f *Friendship) OtherUser(userID uint) *User {
+	if f.RequesterID == userID {
+		return &f.Addressee
+	}
+	return &f.Requester
+}
+
+// ToPublicFor returns the friendship as seen by one of its participants.
+func (f *Friendship) ToPublicFor(userID uint) *PublicFriendship {
+	return &PublicFriendship{
+		ID:         f.ID,
+		User:       *f.OtherUser(userID).ToPublic(),
+		Status:     f.Status,
+		CreatedAt:  f.CreatedAt,
+		AcceptedAt: f.AcceptedAt,
+	}
+}
+
+type FriendRequests struct {
+	Incoming []*PublicFriendship `json:"incoming"`
+	Outgoing []*PublicFriendship `json:"outgoing"`
+}
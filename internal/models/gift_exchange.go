@@ -0,0 +1,145 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"wishlist-app/pkg/money"
+)
+
+type ExchangeStatus string
+
+const (
+	ExchangeOpen     ExchangeStatus = "open"
+	ExchangeDrawn    ExchangeStatus = "drawn"
+	ExchangeRevealed ExchangeStatus = "revealed"
+)
+
+type ParticipantStatus string
+
+const (
+	ParticipantInvited ParticipantStatus = "invited"
+	ParticipantJoined  ParticipantStatus = "joined"
+)
+
+// GiftExchange is a Secret Santa group run by an organizer. Once drawn, each
+// joined participant is assigned one recipient; participants only learn their
+// recipient after the organizer reveals the results.
+type GiftExchange struct {
+	gorm.Model
+	OrganizerID  uint           `gorm:"not null;index"`
+	Name         string         `gorm:"not null"`
+	BudgetMinor  int64          `gorm:"not null;default:0"`
+	Currency     string         `gorm:"type:varchar(3);not null;default:''"`
+	Status       ExchangeStatus `gorm:"type:varchar(16);not null;default:open"`
+	DrawnAt      *time.Time
+	RevealedAt   *time.Time
+	Organizer    User                  `gorm:"foreignKey:OrganizerID"`
+	Participants []ExchangeParticipant `gorm:"foreignKey:ExchangeID"`
+	Exclusions   []ExchangeExclusion   `gorm:"foreignKey:ExchangeID"`
+}
+
+type ExchangeParticipant struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ExchangeID  uint              `gorm:"not null;uniqueIndex:idx_exchange_participant"`
+	UserID      uint              `gorm:"not null;uniqueIndex:idx_exchange_participant;index"`
+	Status      ParticipantStatus `gorm:"type:varchar(16);not null;default:invited"`
+	RecipientID *uint
+	User        User  `gorm:"foreignKey:UserID"`
+	Recipient   *User `gorm:"foreignKey:RecipientID"`
+}
+
+// ExchangeExclusion forbids the giver from drawing the recipient.
+type ExchangeExclusion struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	ExchangeID  uint `gorm:"not null;index"`
+	GiverID     uint `gorm:"not null"`
+	RecipientID uint `gorm:"not null"`
+}
+
+type PublicGiftExchange struct {
+	ID           uint                 `json:"id"`
+	Name         string               `json:"name"`
+	Budget       string               `json:"budget,omitempty" example:"30.00"`
+	Currency     string               `json:"currency,omitempty" example:"EUR"`
+	Status       ExchangeStatus       `json:"status"`
+	DrawnAt      *time.Time           `json:"drawn_at,omitempty"`
+	RevealedAt   *time.Time           `json:"revealed_at,omitempty"`
+	Organizer    PublicUser           `json:"organizer"`
+	Participants []*PublicParticipant `json:"participants"`
+	Exclusions   []*PublicExclusion   `json:"exclusions,omitempty"`
+}
+
+type PublicParticipant struct {
+	User   PublicUser        `json:"user"`
+	Status ParticipantStatus `json:"status"`
+}
+
+type PublicExclusion struct {
+	ID          uint `json:"id"`
+	GiverID     uint `json:"giver_id"`
+	RecipientID uint `json:"recipient_id"`
+}
+
+type PublicAssignment struct {
+	ExchangeID uint          `json:"exchange_id"`
+	Budget     string        `json:"budget,omitempty"`
+	Currency   string        `json:"currency,omitempty"`
+	Recipient  PublicUser    `json:"recipient"`
+	Wishes     []*PublicWish `json:"wishes"`
+}
+
+// FormattedBudget returns the budget as a decimal string, or an empty string
+// if the exchange has no budget.
+func (e *GiftExchange) FormattedBudget() string {
+	if e.BudgetMinor == 0 {
+		return ""
+	}
+	return money.Format(e.BudgetMinor, e.Currency)
+}
+
+// Participant returns the participant record of the user, if any.
+func (e *GiftExchange) Participant(userID uint) *ExchangeParticipant {
+	for i := range e.Participants {
+		if e.Participants[i].UserID == userID {
+			return &e.Participants[i]
+		}
+	}
+	return nil
+}
+
+// ToPublicFor returns the exchange as seen by the viewer. Assignments are
+// never included; exclusion rules are only shown to the organizer.
+func (e *GiftExchange) ToPublicFor(viewerID uint) *PublicGiftExchange {
+	public := &PublicGiftExchange{
+		ID:           e.ID,
+		Name:         e.Name,
+		Budget:       e.FormattedBudget(),
+		Currency:     e.Currency,
+		Status:       e.Status,
+		DrawnAt:      e.DrawnAt,
+		RevealedAt:   e.RevealedAt,
+		Organizer:    *e.Organizer.ToPublic(),
+		Participants: make([]*PublicParticipant, len(e.Participants)),
+	}
+	for i, participant := range e.Participants {
+		public.Participants[i] = &PublicParticipant{
+			User:   *participant.User.ToPublic(),
+			Status: participant.Status,
+		}
+	}
+	if viewerID == e.OrganizerID {
+		public.Exclusions = make([]*PublicExclusion, len(e.Exclusions))
+		for i, exclusion := range e.Exclusions {
+			public.Exclusions[i] = &PublicExclusion{
+				ID:          exclusion.ID,
+				GiverID:     exclusion.GiverID,
+				RecipientID: exclusion.RecipientID,
+			}
+		}
+	}
+	return public
+}
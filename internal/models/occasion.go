@@ -0,0 +1,129 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const DateLayout = "2006-01-02"
+
+// Occasion is a dated event such as a birthday that wishes and lists can be
+// attached to. Recurring occasions repeat every year on the same day.
+type Occasion struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;index"`
+	Name       string     `gorm:"not null"`
+	Date       time.Time  `gorm:"type:date;not null"`
+	Recurring  bool       `gorm:"not null;default:false"`
+	Timezone   string     `gorm:"not null;default:UTC"`
+	Visibility Visibility `gorm:"type:varchar(16);not null;default:friends"`
+	User       User       `gorm:"foreignKey:UserID"`
+	Wishes     []Wish     `gorm:"-"`
+}
+
+type PublicOccasion struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Date       string     `json:"date"`
+	Recurring  bool       `json:"recurring"`
+	Timezone   string     `json:"timezone"`
+	Visibility Visibility `json:"visibility"`
+	NextDate   string     `json:"next_date,omitempty"`
+	DaysUntil  *int       `json:"days_until,omitempty"`
+	User       PublicUser `json:"user"`
+}
+
+type UpcomingOccasion struct {
+	PublicOccasion
+	Wishes []*PublicWish `json:"wishes"`
+}
+
+// Location returns the occasion's time zone, falling back to UTC.
+func (o *Occasion) Location() *time.Location {
+	loc, err := time.LoadLocation(o.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// NextOccurrence returns the first day of the occasion on or after the day
+// that now falls on in the occasion's time zone. The second result is false if
+// a one-off occasion has already passed. Recurring occasions on February 29
+// fall on February 28 in non-leap years.
+func (o *Occasion) NextOccurrence(now time.Time) (time.Time, bool) {
+	local := now.In(o.Location())
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	date := time.Date(o.Date.Year(), o.Date.Month(), o.Date.Day(), 0, 0, 0, 0, time.UTC)
+
+	if !o.Recurring {
+		return date, !date.Before(today)
+	}
+
+	next := anniversary(date, today.Year())
+	if next.Before(today) {
+		next = anniversary(date, today.Year()+1)
+	}
+	return next, true
+}
+
+// DaysUntil returns the number of calendar days from now until the next
+// occurrence, in the occasion's time zone.
+func (o *Occasion) DaysUntil(now time.Time) (int, bool) {
+	next, ok := o.NextOccurrence(now)
+	if !ok {
+		return 0, false
+	}
+	local := now.In(o.Location())
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	return int(next.Sub(today).Hours() / 24), true
+}
+
+func anniversary(date time.Time, year int) time.Time {
+	day := date.Day()
+	if date.Month() == time.February && day == 29 && !isLeapYear(year) {
+		day = 28
+	}
+	return time.Date(year, date.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func (o *Occasion) ToPublic() *PublicOccasion {
+	return &PublicOccasion{
+		ID:         o.ID,
+		Name:       o.Name,
+		Date:       o.Date.Format(DateLayout),
+		Recurring:  o.Recurring,
+		Timezone:   o.Timezone,
+		Visibility: o.Visibility,
+		User:       *o.User.ToPublic(),
+	}
+}
+
+// ToUpcomingFor returns the occasion with its countdown and the related wishes
+// as seen by the viewer.
+func (o *Occasion) ToUpcomingFor(viewerID uint, now time.Time) *UpcomingOccasion {
+	upcoming := &UpcomingOccasion{
+		PublicOccasion: *o.ToPublicAt(now),
+		Wishes:         make([]*PublicWish, len(o.Wishes)),
+	}
+	for i := range o.Wishes {
+		upcoming.Wishes[i] = o.Wishes[i].ToPublicFor(viewerID)
+	}
+	return upcoming
+}
+
+// ToPublicAt includes the next occurrence and countdown as of now.
+func (o *Occasion) ToPublicAt(now time.Time) *PublicOccasion {
+	public := o.ToPublic()
+	if next, ok := o.NextOccurrence(now); ok {
+		days, _ := o.DaysUntil(now)
+		public.NextDate = next.Format(DateLayout)
+		public.DaysUntil = &days
+	}
+	return public
+}
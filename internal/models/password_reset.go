@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PasswordResetToken lets a user who forgot their password choose a new one.
+// Only the SHA-256 hash of the token is stored, and each token works once.
+type PasswordResetToken struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+}
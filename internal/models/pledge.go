@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"wishlist-app/pkg/money"
+)
+
+// Pledge is a contribution a gifter commits toward a group gift. Like
+// reservations, pledges are never exposed to the owner of the wish. The amount
+// is in minor units of the wish's currency.
+type Pledge struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	WishID      uint  `gorm:"not null;uniqueIndex:idx_pledge_wish_user"`
+	UserID      uint  `gorm:"not null;uniqueIndex:idx_pledge_wish_user;index"`
+	AmountMinor int64 `gorm:"not null;default:0"`
+	Wish        Wish  `gorm:"foreignKey:WishID"`
+	User        User  `gorm:"foreignKey:UserID"`
+}
+
+type PublicPledge struct {
+	ID        uint        `json:"id"`
+	Amount    string      `json:"amount" example:"25.00"`
+	Currency  string      `json:"currency" example:"EUR"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Wish      *PublicWish `json:"wish"`
+}
+
+func (p *Pledge) ToPublic() *PublicPledge {
+	return &PublicPledge{
+		ID:        p.ID,
+		Amount:    money.Format(p.AmountMinor, p.Wish.Currency),
+		Currency:  p.Wish.Currency,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+		Wish:      p.Wish.ToPublicFor(p.UserID),
+	}
+}
@@ -0,0 +1,10 @@
+package models
+
+// Priority tells gifters how much the owner wants a wish.
+type Priority string
+
+const (
+	PriorityMustHave   Priority = "must_have"
+	PriorityWant       Priority = "want"
+	PriorityNiceToHave Priority = "nice_to_have"
+)
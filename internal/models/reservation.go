@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// Reservation marks a wish, or part of its quantity, as claimed by a gifter.
+// It is never exposed to the owner of the wish.
+type Reservation struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	Quantity  int  `gorm:"not null;default:1"`
+	WishID    uint `gorm:"not null;uniqueIndex:idx_reservation_wish_user"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_reservation_wish_user;index"`
+	Wish      Wish `gorm:"foreignKey:WishID"`
+	User      User `gorm:"foreignKey:UserID"`
+}
+
+// ReservationStatus tells a gifter whether a wish is still available. A wish
+// counts as reserved once its whole quantity is received or claimed, or its
+// pledges cover the price. Pledged amounts are in the wish's currency.
+type ReservationStatus struct {
+	Reserved     bool   `json:"reserved"`
+	ReservedByMe bool   `json:"reserved_by_me"`
+	MyQuantity   int    `json:"my_quantity,omitempty"`
+	Pledged      string `json:"pledged,omitempty"`
+	Contributors int    `json:"contributors,omitempty"`
+	MyPledge     string `json:"my_pledge,omitempty"`
+}
+
+type PublicReservation struct {
+	ID         uint        `json:"id"`
+	ReservedAt time.Time   `json:"reserved_at"`
+	Quantity   int         `json:"quantity"`
+	Wish       *PublicWish `json:"wish"`
+}
+
+func (r *Reservation) ToPublic() *PublicReservation {
+	return &PublicReservation{
+		ID:         r.ID,
+		ReservedAt: r.CreatedAt,
+		Quantity:   r.Quantity,
+		Wish:       r.Wish.ToPublicFor(r.UserID),
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Session is one login of a user. Its refresh tokens form a family: each
+// refresh replaces the current token with a new one, and revoking the session
+// invalidates all of them together with the access tokens issued for it.
+// A session expires together with its latest refresh token, so one that is
+// not refreshed within the refresh token lifetime ends by itself.
+type Session struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	UserID     uint   `gorm:"not null;index"`
+	UserAgent  string `gorm:"size:255"`
+	IP         string `gorm:"size:45"`
+	LastSeenAt time.Time
+	ExpiresAt  time.Time `gorm:"index"`
+	RevokedAt  *time.Time
+}
+
+// PublicSession is how a session is shown to its user. Current marks the
+// session of the request.
+type PublicSession struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+// Active reports whether the session has neither been revoked nor expired.
+func (s *Session) Active() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+func (s *Session) ToPublic(currentSessionID uint) *PublicSession {
+	return &PublicSession{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		Current:    s.ID == currentSessionID,
+	}
+}
+
+// RefreshToken is stored as a SHA-256 hash. A token that was exchanged for a
+// new one keeps its row with UsedAt set, so that presenting it again can be
+// recognised as reuse.
+type RefreshToken struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	SessionID uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	Session   Session `gorm:"foreignKey:SessionID"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// ShareLink grants link-level access to a wishlist to anyone holding its
+// token. Only a hash of the token is stored.
+type ShareLink struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uint   `gorm:"not null;index"`
+	WishlistID uint   `gorm:"not null;index"`
+	TokenHash  string `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	Wishlist   Wishlist `gorm:"foreignKey:WishlistID"`
+}
+
+type PublicShareLink struct {
+	ID         uint       `json:"id"`
+	WishlistID uint       `json:"wishlist_id"`
+	Token      string     `json:"token,omitempty"`
+	Path       string     `json:"path,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Active     bool       `json:"active"`
+}
+
+func (l *ShareLink) Active(now time.Time) bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	return l.ExpiresAt == nil || now.Before(*l.ExpiresAt)
+}
+
+func (l *ShareLink) ToPublic() *PublicShareLink {
+	return &PublicShareLink{
+		ID:         l.ID,
+		WishlistID: l.WishlistID,
+		CreatedAt:  l.CreatedAt,
+		ExpiresAt:  l.ExpiresAt,
+		RevokedAt:  l.RevokedAt,
+		Active:     l.Active(time.Now()),
+	}
+}
+
+// ToPublicWithToken includes the plain token, which is only known right after
+// the link is created or rotated.
+func (l *ShareLink) ToPublicWithToken(token string) *PublicShareLink {
+	public := l.ToPublic()
+	public.Token = token
+	public.Path = "/api/shared/" + token
+	return public
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Tag is a user-defined label for grouping the user's own wishes.
+type Tag struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_tag_user_name"`
+	Name      string `gorm:"size:50;not null;uniqueIndex:idx_tag_user_name"`
+}
+
+// Category is one of the fixed system categories a wish can belong to.
+type Category string
+
+const (
+	CategoryBooks       Category = "books"
+	CategoryTech        Category = "tech"
+	CategoryExperiences Category = "experiences"
+	CategoryClothing    Category = "clothing"
+	CategoryHome        Category = "home"
+	CategoryToys        Category = "toys"
+	CategoryBeauty      Category = "beauty"
+	CategorySports      Category = "sports"
+	CategoryFood        Category = "food"
+	CategoryOther       Category = "other"
+)
+
+var Categories = []Category{
+	CategoryBooks,
+	CategoryTech,
+	CategoryExperiences,
+	CategoryClothing,
+	CategoryHome,
+	CategoryToys,
+	CategoryBeauty,
+	CategorySports,
+	CategoryFood,
+	CategoryOther,
+}
+
+func (c Category) Valid() bool {
+	for _, category := range Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTagName makes tag names case-insensitive and trims spaces.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// TagCount is a tag with the number of wishes carrying it.
+type TagCount struct {
+	ID    uint   `json:"id,omitempty"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type CategoryCount struct {
+	Category Category `json:"category"`
+	Count    int64    `json:"count"`
+}
+
+// WishFacets lists the tags and categories of a set of wishes with their
+// counts, for building filters.
+type WishFacets struct {
+	Tags       []TagCount      `json:"tags"`
+	Categories []CategoryCount `json:"categories"`
+}
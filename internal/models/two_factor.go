@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// RecoveryCode lets a user who lost their authenticator log in once. Only
+// a slow, per-user salted hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"size:64;not null;uniqueIndex"`
+	UsedAt    *time.Time
+}
+
+// LoginChallenge records a login challenge on the server, so that each
+// challenge completes at most one login. Only the hash of its ID is stored.
+type LoginChallenge struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+}
+
+// TwoFactorStatus is what a user sees about their two-factor authentication.
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
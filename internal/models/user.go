@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -8,7 +10,57 @@ type User struct {
 	gorm.Model
 	Login        string `gorm:"uniqueIndex;not null"`
 	PasswordHash string `gorm:"not null"`
+	Currency     string `gorm:"type:varchar(3);not null;default:''"`
+	IsAdmin      bool   `gorm:"not null;default:false"`
 	Wishes       []Wish
+
+	// Email is optional. Password reset links are only sent to it once it
+	// has been verified.
+	Email              *string `gorm:"size:254;uniqueIndex"`
+	EmailVerifiedAt    *time.Time
+	VerificationSentAt *time.Time
+
+	// TOTPSecret is set when the user starts enrolling in two-factor
+	// authentication, which takes effect once TOTPEnabledAt is set.
+	// TOTPLastStep is the time step of the last accepted code, so that no
+	// code is accepted twice. Too many wrong codes within the lockout
+	// duration from TOTPAttemptsSince lock the second factor until
+	// TOTPLockedUntil.
+	TOTPSecret         string `gorm:"size:64"`
+	TOTPEnabledAt      *time.Time
+	TOTPLastStep       int64 `gorm:"not null;default:0"`
+	TOTPFailedAttempts int   `gorm:"not null;default:0"`
+	TOTPAttemptsSince  *time.Time
+	TOTPLockedUntil    *time.Time
+}
+
+// EmailVerified reports whether the user has an email address and proved
+// they own it.
+func (u *User) EmailVerified() bool {
+	return u.Email != nil && u.EmailVerifiedAt != nil
+}
+
+// TwoFactorEnabled reports whether logging in requires a second factor.
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// EmailStatus is what a user sees about their own email address.
+type EmailStatus struct {
+	Email              string     `json:"email,omitempty"`
+	Verified           bool       `json:"verified"`
+	VerificationSentAt *time.Time `json:"verification_sent_at,omitempty"`
+}
+
+func (u *User) ToEmailStatus() *EmailStatus {
+	status := &EmailStatus{
+		Verified:           u.EmailVerified(),
+		VerificationSentAt: u.VerificationSentAt,
+	}
+	if u.Email != nil {
+		status.Email = *u.Email
+	}
+	return status
 }
 
 type PublicUser struct {
@@ -0,0 +1,68 @@
+package models
+
+// Visibility controls who can see a wish or a wishlist.
+type Visibility string
+
+const (
+	VisibilityPrivate Visibility = "private"
+	VisibilityLink    Visibility = "link"
+	VisibilityFriends Visibility = "friends"
+	VisibilityPublic  Visibility = "public"
+)
+
+// Access is the set of visibilities a viewer may read on another user's
+// content. Audiences are granted separately rather than ranked: being a
+// friend does not open link-only content, and holding a share link does not
+// open friends-only content.
+type Access uint8
+
+const (
+	AccessPublic Access = 1 << iota
+	accessFriends
+	accessLink
+	accessPrivate
+)
+
+const (
+	AccessFriend = AccessPublic | accessFriends
+	AccessLink   = AccessPublic | accessLink
+	AccessOwner  = AccessPublic | accessFriends | accessLink | accessPrivate
+)
+
+var grants = map[Visibility]Access{
+	VisibilityPublic:  AccessPublic,
+	VisibilityFriends: accessFriends,
+	VisibilityLink:    accessLink,
+	VisibilityPrivate: accessPrivate,
+}
+
+func (v Visibility) Valid() bool {
+	_, ok := grants[v]
+	return ok
+}
+
+// With returns the access of a viewer who holds both grants.
+func (a Access) With(other Access) Access {
+	return a | other
+}
+
+// CanSee reports whether content with the given visibility is readable.
+// Unknown visibilities are treated as private.
+func (a Access) CanSee(v Visibility) bool {
+	grant, ok := grants[v]
+	if !ok {
+		grant = accessPrivate
+	}
+	return a&grant != 0
+}
+
+// Visibilities returns every visibility level readable with this access.
+func (a Access) Visibilities() []Visibility {
+	visibilities := make([]Visibility, 0, len(grants))
+	for _, v := range []Visibility{VisibilityPublic, VisibilityFriends, VisibilityLink, VisibilityPrivate} {
+		if a.CanSee(v) {
+			visibilities = append(visibilities, v)
+		}
+	}
+	return visibilities
+}
@@ -1,35 +1,336 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
+	"wishlist-app/pkg/money"
 )
 
+// Wish is something a user would like to receive. Its price ranges from
+// PriceMinor to PriceMaxMinor in minor units of Currency; both bounds are equal
+// for an exact price and zero when the price is unknown. An uploaded image,
+// stored under ImageKey, takes the place of the external ImageURL. A wish
+// saved from another user's list remembers where it came from; SavedBy is the
+// number of other users who saved this wish, when it was loaded.
 type Wish struct {
 	gorm.Model
-	UserID   uint   `gorm:"not null"`
-	Title    string `gorm:"not null"`
-	Comment  string `gorm:"size:500"`
-	ImageURL string
-	Price    float64
-	User     User `gorm:"foreignKey:UserID"`
+	UserID        uint   `gorm:"not null;index:idx_wishes_user_rank,priority:1"`
+	WishlistID    *uint  `gorm:"index"`
+	OccasionID    *uint  `gorm:"index"`
+	Title         string `gorm:"not null"`
+	Comment       string `gorm:"size:500"`
+	ImageURL      string
+	ImageKey      string     `gorm:"size:255;not null;default:''"`
+	ThumbnailKey  string     `gorm:"size:255;not null;default:''"`
+	ProductURL    string     `gorm:"size:2048"`
+	PriceMinor    int64      `gorm:"not null;default:0"`
+	PriceMaxMinor int64      `gorm:"not null;default:0"`
+	PriceUnknown  bool       `gorm:"not null;default:false"`
+	Currency      string     `gorm:"type:varchar(3);not null;default:''"`
+	Quantity      int        `gorm:"not null;default:1"`
+	Received      int        `gorm:"not null;default:0"`
+	Visibility    Visibility `gorm:"type:varchar(16);not null;default:public"`
+	Priority      Priority   `gorm:"type:varchar(16);not null;default:want"`
+	Status        WishStatus `gorm:"type:varchar(16);not null;default:active;index"`
+	ReceivedAt    *time.Time
+	ThankYouNote  string        `gorm:"size:1000"`
+	Rank          string        `gorm:"not null;default:'';index:idx_wishes_user_rank,priority:2"`
+	Category      Category      `gorm:"type:varchar(32);not null;default:'';index"`
+	Tags          []Tag         `gorm:"many2many:wish_tags"`
+	User          User          `gorm:"foreignKey:UserID"`
+	Wishlist      *Wishlist     `gorm:"foreignKey:WishlistID"`
+	Reservations  []Reservation `gorm:"foreignKey:WishID"`
+	Pledges       []Pledge      `gorm:"foreignKey:WishID"`
+	SourceWishID  *uint         `gorm:"index"`
+	SourceUserID  *uint
+	SourceUser    *User `gorm:"foreignKey:SourceUserID"`
+	SavedBy       int   `gorm:"-"`
 }
 
+// ImagePath is where uploaded images are served, followed by their key.
+const ImagePath = "/api/images/"
+
 type PublicWish struct {
-	ID       uint       `json:"id"`
-	Title    string     `json:"title"`
-	Comment  string     `json:"comment,omitempty"`
-	ImageURL string     `json:"image_url,omitempty"`
-	Price    float64    `json:"price,omitempty"`
-	User     PublicUser `json:"user"`
+	ID           uint               `json:"id"`
+	WishlistID   uint               `json:"wishlist_id,omitempty"`
+	OccasionID   *uint              `json:"occasion_id,omitempty"`
+	Title        string             `json:"title"`
+	Comment      string             `json:"comment,omitempty"`
+	ImageURL     string             `json:"image_url,omitempty"`
+	ThumbnailURL string             `json:"thumbnail_url,omitempty"`
+	ProductURL   string             `json:"product_url,omitempty"`
+	Price        string             `json:"price,omitempty" example:"24.99"`
+	PriceMin     string             `json:"price_min,omitempty" example:"50.00"`
+	PriceMax     string             `json:"price_max,omitempty" example:"150.00"`
+	PriceUnknown bool               `json:"price_unknown"`
+	Currency     string             `json:"currency,omitempty" example:"EUR"`
+	Quantity     int                `json:"quantity"`
+	Received     int                `json:"received"`
+	Remaining    *int               `json:"remaining,omitempty"`
+	Visibility   Visibility         `json:"visibility"`
+	Priority     Priority           `json:"priority"`
+	Category     Category           `json:"category,omitempty"`
+	Tags         []string           `json:"tags"`
+	Status       WishStatus         `json:"status"`
+	ReceivedAt   *time.Time         `json:"received_at,omitempty"`
+	ThankYouNote string             `json:"thank_you_note,omitempty"`
+	User         PublicUser         `json:"user"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	Reservation  *ReservationStatus `json:"reservation,omitempty"`
+	SourceWishID *uint              `json:"source_wish_id,omitempty"`
+	SavedFrom    *PublicUser        `json:"saved_from,omitempty"`
+	SavedBy      int                `json:"saved_by,omitempty"`
 }
 
+// ToPublic returns the owner-safe view of the wish, without any reservation
+// information.
 func (w *Wish) ToPublic() *PublicWish {
-	return &PublicWish{
-		ID:       w.ID,
-		Title:    w.Title,
-		Comment:  w.Comment,
-		ImageURL: w.ImageURL,
-		Price:    w.Price,
-		User:     *w.User.ToPublic(),
+	public := &PublicWish{
+		ID:           w.ID,
+		Title:        w.Title,
+		Comment:      w.Comment,
+		ImageURL:     w.ImageURL,
+		ProductURL:   w.ProductURL,
+		PriceUnknown: w.PriceUnknown,
+		Quantity:     w.Quantity,
+		Received:     w.Received,
+		OccasionID:   w.OccasionID,
+		Visibility:   w.Visibility,
+		Priority:     w.Priority,
+		Category:     w.Category,
+		Tags:         make([]string, len(w.Tags)),
+		Status:       w.Status,
+		ReceivedAt:   w.ReceivedAt,
+		ThankYouNote: w.ThankYouNote,
+		User:         *w.User.ToPublic(),
+		CreatedAt:    w.CreatedAt,
+		UpdatedAt:    w.UpdatedAt,
+		SourceWishID: w.SourceWishID,
+		SavedBy:      w.SavedBy,
+	}
+	switch {
+	case w.PriceUnknown:
+	case w.PriceMinor == w.PriceMaxMinor:
+		public.Price = money.Format(w.PriceMinor, w.Currency)
+		public.Currency = w.Currency
+	default:
+		public.PriceMin = money.Format(w.PriceMinor, w.Currency)
+		public.PriceMax = money.Format(w.PriceMaxMinor, w.Currency)
+		public.Currency = w.Currency
+	}
+	if w.ImageKey != "" {
+		public.ImageURL = ImagePath + w.ImageKey
+		public.ThumbnailURL = ImagePath + w.ThumbnailKey
+	}
+	for i, tag := range w.Tags {
+		public.Tags[i] = tag.Name
+	}
+	if w.WishlistID != nil {
+		public.WishlistID = *w.WishlistID
+	}
+	if w.SourceUser != nil {
+		public.SavedFrom = w.SourceUser.ToPublic()
+	}
+	return public
+}
+
+// VisibleWith reports whether a viewer with the given access can see both the
+// wish and its list, if the list is loaded.
+func (w *Wish) VisibleWith(access Access) bool {
+	if !access.CanSee(w.Visibility) {
+		return false
+	}
+	return w.Wishlist == nil || access.CanSee(w.Wishlist.Visibility)
+}
+
+// PledgedAmount returns the sum of the loaded pledges in minor units of the
+// wish's currency.
+func (w *Wish) PledgedAmount() int64 {
+	var total int64
+	for _, pledge := range w.Pledges {
+		total += pledge.AmountMinor
 	}
+	return total
+}
+
+// FullyFunded reports whether the loaded pledges cover the price, or the upper
+// end of its range.
+func (w *Wish) FullyFunded() bool {
+	return len(w.Pledges) > 0 && w.PledgedAmount() >= w.PriceMaxMinor
+}
+
+// Claimed returns the quantity claimed by the loaded reservations.
+func (w *Wish) Claimed() int {
+	claimed := 0
+	for _, reservation := range w.Reservations {
+		claimed += max(reservation.Quantity, 1)
+	}
+	return claimed
+}
+
+// Remaining returns how many items are neither received nor claimed by the
+// loaded reservations. A wish without a quantity counts as a single item.
+func (w *Wish) Remaining() int {
+	return max(max(w.Quantity, 1)-w.Received-w.Claimed(), 0)
+}
+
+// Reserved reports whether the whole quantity is received or claimed by the
+// loaded reservations, or the wish is fully funded by the loaded pledges.
+func (w *Wish) Reserved() bool {
+	return w.Remaining() == 0 || w.FullyFunded()
+}
+
+// Active reports whether the wish is still wanted. A wish that has not been
+// saved yet has no status and counts as active.
+func (w *Wish) Active() bool {
+	return w.Status == "" || w.Status == WishActive
+}
+
+// GivenBy reports whether the user reserved or pledged toward the wish
+// according to the loaded reservations and pledges.
+func (w *Wish) GivenBy(userID uint) bool {
+	if userID == 0 {
+		return false
+	}
+	for _, reservation := range w.Reservations {
+		if reservation.UserID == userID {
+			return true
+		}
+	}
+	for _, pledge := range w.Pledges {
+		if pledge.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ToPublicFor returns the view of the wish for the given viewer. Reservation
+// status is only included for authenticated viewers other than the owner, the
+// thank-you note only for the gifters it is addressed to, and how many people
+// saved the wish only for the owner.
+func (w *Wish) ToPublicFor(viewerID uint) *PublicWish {
+	public := w.ToPublic()
+	if viewerID == w.UserID {
+		return public
+	}
+	public.SavedBy = 0
+	if !w.GivenBy(viewerID) {
+		public.ThankYouNote = ""
+	}
+	if viewerID == 0 {
+		return public
+	}
+
+	status := &ReservationStatus{}
+	for _, reservation := range w.Reservations {
+		if reservation.UserID == viewerID {
+			status.ReservedByMe = true
+			status.MyQuantity = reservation.Quantity
+		}
+	}
+	for _, pledge := range w.Pledges {
+		status.Contributors++
+		if pledge.UserID == viewerID {
+			status.MyPledge = money.Format(pledge.AmountMinor, w.Currency)
+		}
+	}
+	if status.Contributors > 0 {
+		status.Pledged = money.Format(w.PledgedAmount(), w.Currency)
+	}
+	status.Reserved = w.Reserved()
+	public.Reservation = status
+
+	remaining := w.Remaining()
+	if w.FullyFunded() {
+		remaining = 0
+	}
+	public.Remaining = &remaining
+
+	return public
+}
+
+// WishFilter narrows down and orders a list of wishes. Empty fields match any
+// wish. MinPrice and MaxPrice are in minor units of Currency and match wishes
+// whose price range overlaps them; wishes with an unknown price never match.
+type WishFilter struct {
+	Status       WishStatus
+	Tag          string
+	Category     Category
+	MinPrice     *int64
+	MaxPrice     *int64
+	Currency     string
+	HasImage     *bool
+	CreatedSince *time.Time
+	Sort         WishSort
+}
+
+// HasPrice reports whether the filter compares prices.
+func (f WishFilter) HasPrice() bool {
+	return f.MinPrice != nil || f.MaxPrice != nil || f.Sort == SortPriceAsc || f.Sort == SortPriceDesc
+}
+
+// WishSort orders a list of wishes. Prices sort ascending by the lower end of
+// their range and descending by the upper end; unknown prices sort last.
+// Titles sort case-insensitively.
+type WishSort string
+
+const (
+	SortManual      WishSort = ""
+	SortCreatedAsc  WishSort = "created_asc"
+	SortCreatedDesc WishSort = "created_desc"
+	SortUpdatedAsc  WishSort = "updated_asc"
+	SortUpdatedDesc WishSort = "updated_desc"
+	SortPriceAsc    WishSort = "price_asc"
+	SortPriceDesc   WishSort = "price_desc"
+	SortTitleAsc    WishSort = "title_asc"
+	SortTitleDesc   WishSort = "title_desc"
+)
+
+func (s WishSort) Valid() bool {
+	switch s {
+	case SortManual, SortCreatedAsc, SortCreatedDesc, SortUpdatedAsc, SortUpdatedDesc,
+		SortPriceAsc, SortPriceDesc, SortTitleAsc, SortTitleDesc:
+		return true
+	}
+	return false
+}
+
+// WishPage is one page of a cursor-paginated list of wishes.
+type WishPage struct {
+	Items      []*PublicWish `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// WishSearch is a full-text search over the wishes a viewer can see,
+// optionally limited to the wishes of one user and narrowed by the filter.
+type WishSearch struct {
+	Query  string
+	Owner  string
+	Filter WishFilter
+}
+
+// WishSearchResult is a wish matching a search with its relevance and its
+// title and comment, where matching words are wrapped in <mark> tags and all
+// other text is HTML-escaped. The comment is cut down to the matching parts.
+type WishSearchResult struct {
+	Wish     Wish
+	Rank     float64
+	Headline string
+	Snippet  string
+}
+
+type PublicWishSearchResult struct {
+	Wish     *PublicWish `json:"wish"`
+	Rank     float64     `json:"rank" example:"0.6079"`
+	Headline string      `json:"headline" example:"Wireless <mark>headphones</mark>"`
+	Snippet  string      `json:"snippet,omitempty" example:"… noise cancelling <mark>headphones</mark> in black …"`
+}
+
+// WishSearchPage is one page of search results, most relevant first.
+type WishSearchPage struct {
+	Items      []*PublicWishSearchResult `json:"items"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
 }
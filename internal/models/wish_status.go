@@ -0,0 +1,27 @@
+package models
+
+// WishStatus is the lifecycle state of a wish. Only active wishes are shown
+// to other users.
+type WishStatus string
+
+const (
+	WishActive   WishStatus = "active"
+	WishReceived WishStatus = "received"
+	WishArchived WishStatus = "archived"
+)
+
+var wishTransitions = map[WishStatus][]WishStatus{
+	WishActive:   {WishReceived, WishArchived},
+	WishReceived: {WishActive, WishArchived},
+	WishArchived: {WishActive},
+}
+
+// CanTransition reports whether a wish may move from status s to next.
+func (s WishStatus) CanTransition(next WishStatus) bool {
+	for _, allowed := range wishTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+const DefaultWishlistTitle = "My wishes"
+
+// Wishlist is a named list of wishes. Its owner may designate a co-organizer,
+// who helps gifters coordinate and moderates the comment threads on its
+// wishes.
+type Wishlist struct {
+	gorm.Model
+	UserID        uint   `gorm:"not null;index"`
+	Title         string `gorm:"not null"`
+	Description   string `gorm:"size:1000"`
+	CoverImageURL string
+	Position      int        `gorm:"not null;default:0"`
+	IsDefault     bool       `gorm:"not null;default:false"`
+	Visibility    Visibility `gorm:"type:varchar(16);not null;default:public"`
+	OccasionID    *uint      `gorm:"index"`
+	CoOrganizerID *uint      `gorm:"index"`
+	User          User       `gorm:"foreignKey:UserID"`
+	CoOrganizer   *User      `gorm:"foreignKey:CoOrganizerID"`
+	Wishes        []Wish
+}
+
+type PublicWishlist struct {
+	ID            uint          `json:"id"`
+	Title         string        `json:"title"`
+	Description   string        `json:"description,omitempty"`
+	CoverImageURL string        `json:"cover_image_url,omitempty"`
+	Position      int           `json:"position"`
+	IsDefault     bool          `json:"is_default"`
+	Visibility    Visibility    `json:"visibility"`
+	OccasionID    *uint         `json:"occasion_id,omitempty"`
+	User          PublicUser    `json:"user"`
+	CoOrganizer   *PublicUser   `json:"co_organizer,omitempty"`
+	Wishes        []*PublicWish `json:"wishes,omitempty"`
+}
+
+func (l *Wishlist) ToPublic() *PublicWishlist {
+	public := &PublicWishlist{
+		ID:            l.ID,
+		Title:         l.Title,
+		Description:   l.Description,
+		CoverImageURL: l.CoverImageURL,
+		Position:      l.Position,
+		IsDefault:     l.IsDefault,
+		Visibility:    l.Visibility,
+		OccasionID:    l.OccasionID,
+		User:          *l.User.ToPublic(),
+	}
+	if l.CoOrganizer != nil {
+		public.CoOrganizer = l.CoOrganizer.ToPublic()
+	}
+	return public
+}
+
+// ToPublicFor returns the list together with its wishes as seen by the viewer.
+func (l *Wishlist) ToPublicFor(viewerID uint) *PublicWishlist {
+	public := l.ToPublic()
+	public.Wishes = make([]*PublicWish, len(l.Wishes))
+	for i := range l.Wishes {
+		l.Wishes[i].User = l.User
+		public.Wishes[i] = l.Wishes[i].ToPublicFor(viewerID)
+	}
+	return public
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"wishlist-app/internal/models"
+	"wishlist-app/pkg/pagination"
+
+	"gorm.io/gorm"
+)
+
+type CommentRepositoryInterface interface {
+	Create(comment *models.WishComment) error
+	GetByID(id uint) (*models.WishComment, error)
+	Update(comment *models.WishComment) error
+	Delete(id uint) error
+	GetByWishID(wishID uint, cursor *pagination.Cursor, limit int) ([]models.WishComment, error)
+}
+
+type CommentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+func (r *CommentRepository) Create(comment *models.WishComment) error {
+	if err := r.db.Create(comment).Error; err != nil {
+		return err
+	}
+	return r.db.First(&comment.User, comment.UserID).Error
+}
+
+func (r *CommentRepository) GetByID(id uint) (*models.WishComment, error) {
+	var comment models.WishComment
+	if err := r.db.Preload("User").First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *CommentRepository) Update(comment *models.WishComment) error {
+	return r.db.Model(comment).Select("body", "edited_at").Updates(comment).Error
+}
+
+func (r *CommentRepository) Delete(id uint) error {
+	return r.db.Delete(&models.WishComment{}, id).Error
+}
+
+// GetByWishID returns up to limit comments on the wish after the cursor, in
+// the order they were written.
+func (r *CommentRepository) GetByWishID(wishID uint, cursor *pagination.Cursor, limit int) ([]models.WishComment, error) {
+	query := r.db.Preload("User").Where("wish_id = ?", wishID)
+	if cursor != nil {
+		query = query.Where("id > ?", cursor.ID)
+	}
+
+	var comments []models.WishComment
+	if err := query.Order("id").Limit(limit).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
@@ -19,7 +19,7 @@ func NewDB(cfg *config.Config, log logger.Logger) (*gorm.DB, error) {
 		cfg.DB.SSLMode,
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn))
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 
 	logger, err := logger.New(cfg.LogLevel)
 	if err != nil {
@@ -34,13 +34,65 @@ func NewDB(cfg *config.Config, log logger.Logger) (*gorm.DB, error) {
 		logger.Info("Database debug logging enabled")
 	}
 
+	hasPriceRanges := db.Migrator().HasColumn(&models.Wish{}, "price_max_minor")
+	hasSessionExpiry := db.Migrator().HasColumn(&models.Session{}, "expires_at")
+
 	if err := db.AutoMigrate(
 		&models.User{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.PasswordResetToken{},
+		&models.RecoveryCode{},
+		&models.LoginChallenge{},
+		&models.Occasion{},
+		&models.Wishlist{},
+		&models.Tag{},
 		&models.Wish{},
+		&models.Reservation{},
+		&models.ShareLink{},
+		&models.Pledge{},
+		&models.WishComment{},
+		&models.Friendship{},
+		&models.GiftExchange{},
+		&models.ExchangeParticipant{},
+		&models.ExchangeExclusion{},
+		&models.ExchangeRate{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	if err := migrateDefaultWishlists(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate default wishlists: %w", err)
+	}
+
+	if err := migrateFriendshipIndex(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate friendships: %w", err)
+	}
+
+	if err := migrateWishRanks(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate wish ranks: %w", err)
+	}
+
+	if err := migrateMoney(db, cfg.Money.DefaultCurrency); err != nil {
+		return nil, fmt.Errorf("failed to migrate prices: %w", err)
+	}
+
+	if !hasPriceRanges {
+		if err := migratePriceRanges(db); err != nil {
+			return nil, fmt.Errorf("failed to migrate price ranges: %w", err)
+		}
+	}
+
+	if !hasSessionExpiry {
+		if err := migrateSessionExpiry(db); err != nil {
+			return nil, fmt.Errorf("failed to migrate session expiry: %w", err)
+		}
+	}
+
+	if err := migrateSearch(db, cfg.Search.Language); err != nil {
+		return nil, fmt.Errorf("failed to migrate search: %w", err)
+	}
+
 	logger.Info("Database connection established and migrations applied")
 	return db, nil
 }
@@ -0,0 +1,17 @@
+package repository
+
+import "errors"
+
+var (
+	ErrAlreadyReserved    = errors.New("wish is already reserved")
+	ErrAlreadyClaimed     = errors.New("wish is already claimed by this user")
+	ErrQuantityExceeded   = errors.New("claim exceeds the remaining quantity")
+	ErrHasPledges         = errors.New("wish has pledges")
+	ErrAlreadyPledged     = errors.New("wish is already pledged by this user")
+	ErrPledgeExceedsPrice = errors.New("pledges exceed the price")
+	ErrTokenReused        = errors.New("refresh token was already used")
+	ErrResetTokenUsed     = errors.New("password reset token was already used")
+	ErrCodeReused         = errors.New("authentication code was already used")
+	ErrTooManyAttempts    = errors.New("too many wrong authentication codes")
+	ErrChallengeUsed      = errors.New("login challenge was already used")
+)
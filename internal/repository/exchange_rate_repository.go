@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ExchangeRateRepositoryInterface interface {
+	GetAll() ([]models.ExchangeRate, error)
+	ReplaceAll(rates []models.ExchangeRate) error
+}
+
+type ExchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+func (r *ExchangeRateRepository) GetAll() ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	if err := r.db.Order("currency").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// ReplaceAll swaps the whole rate table in one transaction, so conversions
+// never mix rates from different files.
+func (r *ExchangeRateRepository) ReplaceAll(rates []models.ExchangeRate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ExchangeRate{}).Error; err != nil {
+			return err
+		}
+		if len(rates) == 0 {
+			return nil
+		}
+		return tx.Create(&rates).Error
+	})
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type FriendshipRepositoryInterface interface {
+	Create(friendship *models.Friendship) error
+	GetByID(id uint) (*models.Friendship, error)
+	GetBetween(userID, otherID uint) (*models.Friendship, error)
+	Update(friendship *models.Friendship) error
+	Delete(id uint) error
+	AreFriends(userID, otherID uint) (bool, error)
+	GetFriends(userID uint) ([]models.Friendship, error)
+	GetIncoming(userID uint) ([]models.Friendship, error)
+	GetOutgoing(userID uint) ([]models.Friendship, error)
+}
+
+type FriendshipRepository struct {
+	db *gorm.DB
+}
+
+func NewFriendshipRepository(db *gorm.DB) *FriendshipRepository {
+	return &FriendshipRepository{db: db}
+}
+
+func (r *FriendshipRepository) Create(friendship *models.Friendship) error {
+	return r.db.Create(friendship).Error
+}
+
+func (r *FriendshipRepository) GetByID(id uint) (*models.Friendship, error) {
+	var friendship models.Friendship
+	if err := r.db.Preload("Requester").Preload("Addressee").First(&friendship, id).Error; err != nil {
+		return nil, err
+	}
+	return &friendship, nil
+}
+
+// GetBetween returns the friendship or pending request between two users in
+// either direction.
+func (r *FriendshipRepository) GetBetween(userID, otherID uint) (*models.Friendship, error) {
+	var friendship models.Friendship
+	if err := r.db.
+		Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)", userID, otherID, otherID, userID).
+		First(&friendship).Error; err != nil {
+		return nil, err
+	}
+	return &friendship, nil
+}
+
+func (r *FriendshipRepository) Update(friendship *models.Friendship) error {
+	return r.db.Save(friendship).Error
+}
+
+func (r *FriendshipRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Friendship{}, id).Error
+}
+
+func (r *FriendshipRepository) AreFriends(userID, otherID uint) (bool, error) {
+	friendship, err := r.GetBetween(userID, otherID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return friendship.Status == models.FriendshipAccepted, nil
+}
+
+func (r *FriendshipRepository) GetFriends(userID uint) ([]models.Friendship, error) {
+	var friendships []models.Friendship
+	if err := r.db.
+		Preload("Requester").
+		Preload("Addressee").
+		Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, models.FriendshipAccepted).
+		Order("accepted_at DESC").
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+	return friendships, nil
+}
+
+func (r *FriendshipRepository) GetIncoming(userID uint) ([]models.Friendship, error) {
+	var friendships []models.Friendship
+	if err := r.db.
+		Preload("Requester").
+		Preload("Addressee").
+		Where("addressee_id = ? AND status = ?", userID, models.FriendshipPending).
+		Order("created_at DESC").
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+	return friendships, nil
+}
+
+func (r *FriendshipRepository) GetOutgoing(userID uint) ([]models.Friendship, error) {
+	var friendships []models.Friendship
+	if err := r.db.
+		Preload("Requester").
+		Preload("Addressee").
+		Where("requester_id = ? AND status = ?", userID, models.FriendshipPending).
+		Order("created_at DESC").
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+	return friendships, nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type GiftExchangeRepositoryInterface interface {
+	Create(exchange *models.GiftExchange) error
+	GetByID(id uint) (*models.GiftExchange, error)
+	Update(exchange *models.GiftExchange) error
+	Delete(id uint) error
+	GetByUserID(userID uint) ([]models.GiftExchange, error)
+	AddParticipant(participant *models.ExchangeParticipant) error
+	UpdateParticipant(participant *models.ExchangeParticipant) error
+	RemoveParticipant(exchangeID, userID uint) error
+	AddExclusion(exclusion *models.ExchangeExclusion) error
+	RemoveExclusion(exchangeID, exclusionID uint) error
+	SaveDraw(exchange *models.GiftExchange) error
+	ResetDraw(exchangeID uint) error
+}
+
+type GiftExchangeRepository struct {
+	db *gorm.DB
+}
+
+func NewGiftExchangeRepository(db *gorm.DB) *GiftExchangeRepository {
+	return &GiftExchangeRepository{db: db}
+}
+
+// Create stores the exchange together with its initial participants.
+func (r *GiftExchangeRepository) Create(exchange *models.GiftExchange) error {
+	return r.db.Create(exchange).Error
+}
+
+func (r *GiftExchangeRepository) GetByID(id uint) (*models.GiftExchange, error) {
+	var exchange models.GiftExchange
+	err := r.db.
+		Preload("Organizer").
+		Preload("Participants", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Participants.User").
+		Preload("Participants.Recipient").
+		Preload("Exclusions", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&exchange, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &exchange, nil
+}
+
+func (r *GiftExchangeRepository) Update(exchange *models.GiftExchange) error {
+	return r.db.Omit("Organizer", "Participants", "Exclusions").Save(exchange).Error
+}
+
+func (r *GiftExchangeRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("exchange_id = ?", id).Delete(&models.ExchangeExclusion{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("exchange_id = ?", id).Delete(&models.ExchangeParticipant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.GiftExchange{}, id).Error
+	})
+}
+
+// GetByUserID returns the exchanges the user organizes or was invited to.
+func (r *GiftExchangeRepository) GetByUserID(userID uint) ([]models.GiftExchange, error) {
+	var exchanges []models.GiftExchange
+	err := r.db.
+		Preload("Organizer").
+		Preload("Participants", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Participants.User").
+		Where("organizer_id = ? OR id IN (?)", userID,
+			r.db.Model(&models.ExchangeParticipant{}).Select("exchange_id").Where("user_id = ?", userID)).
+		Order("created_at DESC").
+		Find(&exchanges).Error
+	if err != nil {
+		return nil, err
+	}
+	return exchanges, nil
+}
+
+func (r *GiftExchangeRepository) AddParticipant(participant *models.ExchangeParticipant) error {
+	return r.db.Omit("User", "Recipient").Create(participant).Error
+}
+
+func (r *GiftExchangeRepository) UpdateParticipant(participant *models.ExchangeParticipant) error {
+	return r.db.Omit("User", "Recipient").Save(participant).Error
+}
+
+// RemoveParticipant deletes the participant and every exclusion rule that
+// mentions them.
+func (r *GiftExchangeRepository) RemoveParticipant(exchangeID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("exchange_id = ? AND (giver_id = ? OR recipient_id = ?)", exchangeID, userID, userID).
+			Delete(&models.ExchangeExclusion{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("exchange_id = ? AND user_id = ?", exchangeID, userID).Delete(&models.ExchangeParticipant{}).Error
+	})
+}
+
+func (r *GiftExchangeRepository) AddExclusion(exclusion *models.ExchangeExclusion) error {
+	return r.db.Create(exclusion).Error
+}
+
+func (r *GiftExchangeRepository) RemoveExclusion(exchangeID, exclusionID uint) error {
+	result := r.db.Where("exchange_id = ?", exchangeID).Delete(&models.ExchangeExclusion{}, exclusionID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SaveDraw stores the exchange status and every participant's recipient in
+// one transaction, so a redraw never leaves a mix of old and new assignments.
+func (r *GiftExchangeRepository) SaveDraw(exchange *models.GiftExchange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, participant := range exchange.Participants {
+			if err := tx.Model(&models.ExchangeParticipant{}).Where("id = ?", participant.ID).
+				Update("recipient_id", participant.RecipientID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.GiftExchange{}).Where("id = ?", exchange.ID).
+			Updates(map[string]interface{}{"status": exchange.Status, "drawn_at": exchange.DrawnAt}).Error
+	})
+}
+
+// ResetDraw discards the current assignments and reopens the exchange.
+func (r *GiftExchangeRepository) ResetDraw(exchangeID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ExchangeParticipant{}).Where("exchange_id = ?", exchangeID).
+			Update("recipient_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.GiftExchange{}).Where("id = ?", exchangeID).
+			Updates(map[string]interface{}{"status": models.ExchangeOpen, "drawn_at": nil}).Error
+	})
+}
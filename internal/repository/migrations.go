@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/pkg/money"
+	"wishlist-app/pkg/rank"
+)
+
+// migrateDefaultWishlists makes sure every user has at most one default list
+// and moves wishes created before lists existed into it.
+func migrateDefaultWishlists(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_wishlists_default
+			ON wishlists (user_id) WHERE is_default AND deleted_at IS NULL`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`INSERT INTO wishlists (created_at, updated_at, user_id, title, position, is_default)
+			SELECT NOW(), NOW(), users.id, ?, 0, TRUE FROM users
+			WHERE EXISTS (
+				SELECT 1 FROM wishes WHERE wishes.user_id = users.id AND wishes.wishlist_id IS NULL
+			) AND NOT EXISTS (
+				SELECT 1 FROM wishlists WHERE wishlists.user_id = users.id AND wishlists.is_default AND wishlists.deleted_at IS NULL
+			)`, models.DefaultWishlistTitle).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`UPDATE wishes SET wishlist_id = wishlists.id FROM wishlists
+			WHERE wishes.wishlist_id IS NULL AND wishlists.user_id = wishes.user_id
+			AND wishlists.is_default AND wishlists.deleted_at IS NULL`).Error
+	})
+}
+
+// migrateFriendshipIndex allows only one friendship row per pair of users,
+// regardless of who sent the request.
+func migrateFriendshipIndex(db *gorm.DB) error {
+	return db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_friendships_pair
+		ON friendships (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id))`).Error
+}
+
+// migrateWishRanks gives every wish a manual order rank. Users with unranked
+// wishes, e.g. created before manual ordering existed, get evenly spaced ranks
+// that keep their ranked wishes first and the rest in creation order.
+func migrateWishRanks(db *gorm.DB) error {
+	var userIDs []uint
+	if err := db.Model(&models.Wish{}).Where("rank = ''").Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var wishIDs []uint
+			if err := tx.Model(&models.Wish{}).
+				Where("user_id = ?", userID).
+				Order(`rank = '', rank COLLATE "C", created_at, id`).
+				Pluck("id", &wishIDs).Error; err != nil {
+				return err
+			}
+
+			for i, key := range rank.Spread(len(wishIDs)) {
+				if err := tx.Model(&models.Wish{}).Where("id = ?", wishIDs[i]).UpdateColumn("rank", key).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateMoney converts the float prices, pledges and budgets stored before
+// currencies existed into minor units of the given currency and drops the old
+// columns. Going through numeric keeps e.g. 19.99 from becoming 1998.
+func migrateMoney(db *gorm.DB, currency string) error {
+	scale := 1
+	for i := 0; i < money.Digits(currency); i++ {
+		scale *= 10
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+
+		if migrator.HasColumn(&models.Wish{}, "price") {
+			if err := tx.Exec(`UPDATE wishes SET price_minor = ROUND(COALESCE(price, 0)::numeric * ?), currency = ?
+				WHERE currency = ''`, scale, currency).Error; err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&models.Wish{}, "price"); err != nil {
+				return err
+			}
+		}
+
+		if migrator.HasColumn(&models.Pledge{}, "amount") {
+			if err := tx.Exec(`UPDATE pledges SET amount_minor = ROUND(amount::numeric * ?)`, scale).Error; err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&models.Pledge{}, "amount"); err != nil {
+				return err
+			}
+		}
+
+		if migrator.HasColumn(&models.GiftExchange{}, "budget") {
+			if err := tx.Exec(`UPDATE gift_exchanges SET budget_minor = ROUND(COALESCE(budget, 0)::numeric * ?), currency = ?
+				WHERE currency = ''`, scale, currency).Error; err != nil {
+				return err
+			}
+			if err := migrator.DropColumn(&models.GiftExchange{}, "budget"); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// migratePriceRanges turns the single prices from before price ranges existed
+// into exact ranges. A zero price used to mean "not set" and becomes unknown.
+// It only runs once, when the range columns are first added.
+func migratePriceRanges(db *gorm.DB) error {
+	return db.Exec(`UPDATE wishes SET price_max_minor = price_minor, price_unknown = (price_minor = 0)`).Error
+}
+
+// migrateSessionExpiry lets the sessions from before sessions expired end
+// with their latest refresh token.
+func migrateSessionExpiry(db *gorm.DB) error {
+	return db.Exec(`UPDATE sessions SET expires_at = COALESCE(
+		(SELECT MAX(expires_at) FROM refresh_tokens WHERE refresh_tokens.session_id = sessions.id), NOW())`).Error
+}
+
+var searchConfigPattern = regexp.MustCompile(`^[a-z_]+$`)
+
+// migrateSearch maintains the full-text search vector of wishes, a generated
+// column weighting titles above comments, and its GIN index. The text search
+// configuration it was built with is kept in the column comment, so that
+// changing the configuration rebuilds it.
+func migrateSearch(db *gorm.DB, config string) error {
+	var exists bool
+	if err := db.Raw(`SELECT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = ?)`, config).Scan(&exists).Error; err != nil {
+		return err
+	}
+	if !exists || !searchConfigPattern.MatchString(config) {
+		return fmt.Errorf("unknown text search configuration %q", config)
+	}
+
+	var current *string
+	if err := db.Raw(`SELECT col_description(attrelid, attnum) FROM pg_attribute
+		WHERE attrelid = 'wishes'::regclass AND attname = 'search_vector' AND NOT attisdropped`).Scan(&current).Error; err != nil {
+		return err
+	}
+	if current != nil && *current == config {
+		return nil
+	}
+
+	// The configuration name is checked above, and DDL cannot take
+	// parameters.
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range []string{
+			`DROP INDEX IF EXISTS idx_wishes_search`,
+			`ALTER TABLE wishes DROP COLUMN IF EXISTS search_vector`,
+			`ALTER TABLE wishes ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+				setweight(to_tsvector('` + config + `', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('` + config + `', coalesce(comment, '')), 'B')) STORED`,
+			`CREATE INDEX idx_wishes_search ON wishes USING GIN (search_vector)`,
+			`COMMENT ON COLUMN wishes.search_vector IS '` + config + `'`,
+		} {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
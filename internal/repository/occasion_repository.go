@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OccasionRepositoryInterface interface {
+	Create(occasion *models.Occasion) error
+	GetByID(id uint) (*models.Occasion, error)
+	Update(occasion *models.Occasion) error
+	Delete(id uint) error
+	GetByUserID(userID uint, visibilities []models.Visibility) ([]models.Occasion, error)
+	Attach(occasionID, userID uint, wishIDs, wishlistIDs []uint) error
+	Detach(occasionID, userID uint, wishIDs, wishlistIDs []uint) error
+}
+
+type OccasionRepository struct {
+	db *gorm.DB
+}
+
+func NewOccasionRepository(db *gorm.DB) *OccasionRepository {
+	return &OccasionRepository{db: db}
+}
+
+func (r *OccasionRepository) Create(occasion *models.Occasion) error {
+	return r.db.Create(occasion).Error
+}
+
+func (r *OccasionRepository) GetByID(id uint) (*models.Occasion, error) {
+	var occasion models.Occasion
+	if err := r.db.Preload("User").First(&occasion, id).Error; err != nil {
+		return nil, err
+	}
+	return &occasion, nil
+}
+
+func (r *OccasionRepository) Update(occasion *models.Occasion) error {
+	return r.db.Save(occasion).Error
+}
+
+// Delete removes the occasion and detaches every wish and list from it.
+func (r *OccasionRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Wish{}).Where("occasion_id = ?", id).Update("occasion_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Wishlist{}).Where("occasion_id = ?", id).Update("occasion_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Occasion{}, id).Error
+	})
+}
+
+func (r *OccasionRepository) GetByUserID(userID uint, visibilities []models.Visibility) ([]models.Occasion, error) {
+	var occasions []models.Occasion
+	if err := r.db.Preload("User").Where("user_id = ? AND visibility IN ?", userID, visibilities).Order("date, id").Find(&occasions).Error; err != nil {
+		return nil, err
+	}
+	return occasions, nil
+}
+
+// Attach links the user's wishes and lists with the given IDs to the occasion.
+// IDs belonging to other users are ignored.
+func (r *OccasionRepository) Attach(occasionID, userID uint, wishIDs, wishlistIDs []uint) error {
+	return r.setOccasion(&occasionID, userID, wishIDs, wishlistIDs, nil)
+}
+
+// Detach unlinks the user's wishes and lists with the given IDs from the
+// occasion.
+func (r *OccasionRepository) Detach(occasionID, userID uint, wishIDs, wishlistIDs []uint) error {
+	return r.setOccasion(nil, userID, wishIDs, wishlistIDs, &occasionID)
+}
+
+func (r *OccasionRepository) setOccasion(occasionID *uint, userID uint, wishIDs, wishlistIDs []uint, currentID *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(wishIDs) > 0 {
+			query := tx.Model(&models.Wish{}).Where("id IN ? AND user_id = ?", wishIDs, userID)
+			if currentID != nil {
+				query = query.Where("occasion_id = ?", *currentID)
+			}
+			if err := query.Update("occasion_id", occasionID).Error; err != nil {
+				return err
+			}
+		}
+		if len(wishlistIDs) > 0 {
+			query := tx.Model(&models.Wishlist{}).Where("id IN ? AND user_id = ?", wishlistIDs, userID)
+			if currentID != nil {
+				query = query.Where("occasion_id = ?", *currentID)
+			}
+			if err := query.Update("occasion_id", occasionID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"time"
+
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepositoryInterface interface {
+	Create(token *models.PasswordResetToken) error
+	GetByHash(hash string) (*models.PasswordResetToken, error)
+	Consume(token *models.PasswordResetToken, passwordHash string) error
+}
+
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (r *PasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *PasswordResetRepository) GetByHash(hash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Consume sets the new password of the token's user in one transaction with
+// using up the token. The user's other outstanding reset tokens are used up
+// as well, and all of their sessions are revoked. Of two concurrent resets
+// with the same token only one succeeds; the other gets ErrResetTokenUsed.
+func (r *PasswordResetRepository) Consume(token *models.PasswordResetToken, passwordHash string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrResetTokenUsed
+		}
+
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", token.UserID).
+			Update("password_hash", passwordHash).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", token.UserID).
+			Update("revoked_at", now).Error
+	})
+}
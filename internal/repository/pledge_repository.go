@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PledgeRepositoryInterface interface {
+	Create(pledge *models.Pledge) error
+	GetByID(id uint) (*models.Pledge, error)
+	Update(pledge *models.Pledge) error
+	Delete(id uint) error
+	GetByUserID(userID uint) ([]models.Pledge, error)
+}
+
+type PledgeRepository struct {
+	db *gorm.DB
+}
+
+func NewPledgeRepository(db *gorm.DB) *PledgeRepository {
+	return &PledgeRepository{db: db}
+}
+
+func (r *PledgeRepository) Create(pledge *models.Pledge) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkPledge(tx, pledge); err != nil {
+			return err
+		}
+		return tx.Create(pledge).Error
+	})
+}
+
+func (r *PledgeRepository) GetByID(id uint) (*models.Pledge, error) {
+	var pledge models.Pledge
+	if err := r.db.First(&pledge, id).Error; err != nil {
+		return nil, err
+	}
+	return &pledge, nil
+}
+
+func (r *PledgeRepository) Update(pledge *models.Pledge) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkPledge(tx, pledge); err != nil {
+			return err
+		}
+		return tx.Model(pledge).Update("amount_minor", pledge.AmountMinor).Error
+	})
+}
+
+func (r *PledgeRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Pledge{}, id).Error
+}
+
+func (r *PledgeRepository) GetByUserID(userID uint) ([]models.Pledge, error) {
+	var pledges []models.Pledge
+	if err := r.db.
+		Joins("JOIN wishes ON wishes.id = pledges.wish_id AND wishes.deleted_at IS NULL").
+		Preload("Wish.User").
+		Preload("Wish.Wishlist").
+		Preload("Wish.Reservations").
+		Preload("Wish.Pledges").
+		Where("pledges.user_id = ?", userID).
+		Order("pledges.created_at DESC").
+		Find(&pledges).Error; err != nil {
+		return nil, err
+	}
+	return pledges, nil
+}
+
+// checkPledge locks the wish row and verifies that the pledge fits into what
+// is left of the price, or the upper end of its range, so concurrent pledges
+// cannot overfund a wish.
+func checkPledge(tx *gorm.DB, pledge *models.Pledge) error {
+	var wish models.Wish
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "price_max_minor").First(&wish, pledge.WishID).Error; err != nil {
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&models.Reservation{}).Where("wish_id = ?", pledge.WishID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAlreadyReserved
+	}
+
+	if pledge.ID == 0 {
+		if err := tx.Model(&models.Pledge{}).Where("wish_id = ? AND user_id = ?", pledge.WishID, pledge.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyPledged
+		}
+	}
+
+	var pledged int64
+	if err := tx.Model(&models.Pledge{}).
+		Where("wish_id = ? AND id <> ?", pledge.WishID, pledge.ID).
+		Select("COALESCE(SUM(amount_minor), 0)").
+		Scan(&pledged).Error; err != nil {
+		return err
+	}
+	if pledged+pledge.AmountMinor > wish.PriceMaxMinor {
+		return ErrPledgeExceedsPrice
+	}
+
+	return nil
+}
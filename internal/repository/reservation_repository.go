@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReservationRepositoryInterface interface {
+	Create(reservation *models.Reservation) error
+	Delete(wishID, userID uint) (bool, error)
+	GetByUserID(userID uint) ([]models.Reservation, error)
+}
+
+type ReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// Create stores the reservation while holding a row lock on the wish, so
+// gifters claiming the same wish at the same moment cannot claim more than
+// the remaining quantity between them.
+func (r *ReservationRepository) Create(reservation *models.Reservation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var wish models.Wish
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "quantity", "received").First(&wish, reservation.WishID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.Reservation{}).Where("wish_id = ? AND user_id = ?", reservation.WishID, reservation.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyClaimed
+		}
+
+		var claimed int64
+		if err := tx.Model(&models.Reservation{}).Where("wish_id = ?", reservation.WishID).Select("COALESCE(SUM(quantity), 0)").Scan(&claimed).Error; err != nil {
+			return err
+		}
+		remaining := int64(wish.Quantity-wish.Received) - claimed
+		if remaining <= 0 {
+			return ErrAlreadyReserved
+		}
+		if int64(reservation.Quantity) > remaining {
+			return ErrQuantityExceeded
+		}
+
+		if err := tx.Model(&models.Pledge{}).Where("wish_id = ?", reservation.WishID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrHasPledges
+		}
+
+		return tx.Create(reservation).Error
+	})
+}
+
+func (r *ReservationRepository) Delete(wishID, userID uint) (bool, error) {
+	result := r.db.Where("wish_id = ? AND user_id = ?", wishID, userID).Delete(&models.Reservation{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *ReservationRepository) GetByUserID(userID uint) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	if err := r.db.
+		Joins("JOIN wishes ON wishes.id = reservations.wish_id AND wishes.deleted_at IS NULL").
+		Preload("Wish.User").
+		Preload("Wish.Wishlist").
+		Preload("Wish.Reservations").
+		Preload("Wish.Pledges").
+		Where("reservations.user_id = ?", userID).
+		Order("reservations.created_at DESC").
+		Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"time"
+
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepositoryInterface interface {
+	Create(session *models.Session, token *models.RefreshToken) error
+	GetByID(id uint) (*models.Session, error)
+	GetByUserID(userID uint) ([]models.Session, error)
+	Touch(id uint, seenAt time.Time) error
+	Revoke(id uint) error
+	RevokeOthers(userID, keepID uint) error
+	GetRefreshToken(hash string) (*models.RefreshToken, error)
+	Rotate(used *models.RefreshToken, next *models.RefreshToken) error
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create stores a new session together with its first refresh token.
+func (r *SessionRepository) Create(session *models.Session, token *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+func (r *SessionRepository) GetByID(id uint) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByUserID returns the active sessions of the user, most recently used
+// first.
+func (r *SessionRepository) GetByUserID(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC, id DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch records that the session was used.
+func (r *SessionRepository) Touch(id uint, seenAt time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("last_seen_at", seenAt).Error
+}
+
+// Revoke ends the session. Revoking it again keeps the original time.
+func (r *SessionRepository) Revoke(id uint) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeOthers ends all sessions of the user except keepID.
+func (r *SessionRepository) RevokeOthers(userID, keepID uint) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// GetRefreshToken returns the token with the given hash and its session,
+// whether or not it was used already.
+func (r *SessionRepository) GetRefreshToken(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Preload("Session").Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate marks the used token and stores the next one of its session, which
+// then expires with the next token. Of two concurrent rotations of the same
+// token only one succeeds; the other gets ErrTokenReused.
+func (r *SessionRepository) Rotate(used *models.RefreshToken, next *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", used.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTokenReused
+		}
+		next.SessionID = used.SessionID
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Session{}).
+			Where("id = ?", used.SessionID).
+			Update("expires_at", next.ExpiresAt).Error
+	})
+}
+
+// DeleteExpired deletes the sessions that expired or were revoked before the
+// given time, together with their refresh tokens, and the expired refresh
+// tokens of the remaining sessions. It returns the number of sessions
+// deleted.
+func (r *SessionRepository) DeleteExpired(before time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ended := tx.Model(&models.Session{}).
+			Select("id").
+			Where("expires_at <= ? OR revoked_at <= ?", before, before)
+		if err := tx.Where("session_id IN (?) OR expires_at <= ?", ended, before).
+			Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("expires_at <= ? OR revoked_at <= ?", before, before).
+			Delete(&models.Session{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
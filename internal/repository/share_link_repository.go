@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ShareLinkRepositoryInterface interface {
+	Create(link *models.ShareLink) error
+	GetByID(id uint) (*models.ShareLink, error)
+	GetByTokenHash(tokenHash string) (*models.ShareLink, error)
+	GetByWishlistID(wishlistID uint) ([]models.ShareLink, error)
+	Update(link *models.ShareLink) error
+}
+
+type ShareLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewShareLinkRepository(db *gorm.DB) *ShareLinkRepository {
+	return &ShareLinkRepository{db: db}
+}
+
+func (r *ShareLinkRepository) Create(link *models.ShareLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *ShareLinkRepository) GetByID(id uint) (*models.ShareLink, error) {
+	var link models.ShareLink
+	if err := r.db.First(&link, id).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ShareLinkRepository) GetByTokenHash(tokenHash string) (*models.ShareLink, error) {
+	var link models.ShareLink
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ShareLinkRepository) GetByWishlistID(wishlistID uint) ([]models.ShareLink, error) {
+	var links []models.ShareLink
+	if err := r.db.Where("wishlist_id = ?", wishlistID).Order("created_at DESC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *ShareLinkRepository) Update(link *models.ShareLink) error {
+	return r.db.Save(link).Error
+}
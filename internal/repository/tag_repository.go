@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepositoryInterface interface {
+	Create(tag *models.Tag) error
+	GetByID(id uint) (*models.Tag, error)
+	Update(tag *models.Tag) error
+	Delete(id uint) error
+	FindOrCreate(userID uint, names []string) ([]models.Tag, error)
+	GetCounts(userID uint) ([]models.TagCount, error)
+	GetFacets(userID uint, visibilities []models.Visibility) (*models.WishFacets, error)
+}
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *TagRepository) GetByID(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepository) Update(tag *models.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+// Delete removes the tag from every wish and then deletes it.
+func (r *TagRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM wish_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Tag{}, id).Error
+	})
+}
+
+// FindOrCreate returns the user's tags with the given names, creating the
+// ones that do not exist yet.
+func (r *TagRepository) FindOrCreate(userID uint, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{UserID: userID, Name: name}
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	var existing []models.Tag
+	if err := r.db.Where("user_id = ? AND name IN ?", userID, names).Order("name").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// GetCounts returns all of the user's tags with the number of active wishes
+// carrying each of them.
+func (r *TagRepository) GetCounts(userID uint) ([]models.TagCount, error) {
+	var counts []models.TagCount
+	if err := r.db.Model(&models.Tag{}).
+		Select("tags.id, tags.name, COUNT(wishes.id) AS count").
+		Joins("LEFT JOIN wish_tags ON wish_tags.tag_id = tags.id").
+		Joins("LEFT JOIN wishes ON wishes.id = wish_tags.wish_id AND wishes.deleted_at IS NULL AND wishes.status = ?", models.WishActive).
+		Where("tags.user_id = ?", userID).
+		Group("tags.id, tags.name").
+		Order("tags.name").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// GetFacets counts the tags and categories of the user's active wishes whose
+// own visibility and list visibility are both among the given ones. Tags
+// that only appear on hidden wishes are left out.
+func (r *TagRepository) GetFacets(userID uint, visibilities []models.Visibility) (*models.WishFacets, error) {
+	visible := func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins("JOIN wishlists ON wishlists.id = wishes.wishlist_id AND wishlists.deleted_at IS NULL").
+			Where("wishes.user_id = ? AND wishes.status = ?", userID, models.WishActive).
+			Where("wishes.visibility IN ? AND wishlists.visibility IN ?", visibilities, visibilities)
+	}
+
+	facets := &models.WishFacets{Tags: []models.TagCount{}, Categories: []models.CategoryCount{}}
+	if err := r.db.Model(&models.Wish{}).
+		Scopes(visible).
+		Select("tags.name, COUNT(*) AS count").
+		Joins("JOIN wish_tags ON wish_tags.wish_id = wishes.id").
+		Joins("JOIN tags ON tags.id = wish_tags.tag_id").
+		Group("tags.name").
+		Order("tags.name").
+		Scan(&facets.Tags).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Model(&models.Wish{}).
+		Scopes(visible).
+		Select("wishes.category, COUNT(*) AS count").
+		Where("wishes.category <> ''").
+		Group("wishes.category").
+		Order("wishes.category").
+		Scan(&facets.Categories).Error; err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"time"
+
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepositoryInterface interface {
+	Enable(user *models.User, codes []models.RecoveryCode) error
+	Disable(userID uint) error
+	UseStep(userID uint, step int64) error
+	UseRecoveryCode(userID uint, hash string) error
+	CountRecoveryCodes(userID uint) (int64, error)
+	ReserveAttempt(userID uint, limit int, lockout time.Duration) error
+	ResetFailedAttempts(userID uint) error
+	CreateChallenge(challenge *models.LoginChallenge) error
+	GetChallenge(tokenHash string) (*models.LoginChallenge, error)
+	UseChallenge(id uint) error
+}
+
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// Enable stores the two-factor columns of the confirmed user and replaces
+// their recovery codes.
+func (r *TwoFactorRepository) Enable(user *models.User, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).
+			Select("totp_enabled_at", "totp_last_step", "totp_failed_attempts", "totp_attempts_since", "totp_locked_until").
+			Updates(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Disable turns two-factor authentication off and deletes the recovery codes.
+func (r *TwoFactorRepository) Disable(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_secret":          "",
+			"totp_enabled_at":      nil,
+			"totp_last_step":       0,
+			"totp_failed_attempts": 0,
+			"totp_attempts_since":  nil,
+			"totp_locked_until":    nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// UseStep records that a code of the given time step was accepted. Steps
+// must increase, so a code that was already used gets ErrCodeReused.
+func (r *TwoFactorRepository) UseStep(userID uint, step int64) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCodeReused
+	}
+	return nil
+}
+
+// UseRecoveryCode uses up the user's recovery code with the given hash. A
+// code that does not exist or was used already gets ErrCodeReused.
+func (r *TwoFactorRepository) UseRecoveryCode(userID uint, hash string) error {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCodeReused
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// ReserveAttempt counts an attempt at a code before the code is checked, in
+// a single statement, so parallel guesses cannot exceed the limit. Attempts
+// are counted from the first one after the last success, and counting starts
+// over once the lockout duration has passed since then, so occasional typos
+// never add up to a lockout. The attempt that reaches the limit within that
+// time locks the user out until the lockout has passed, after which counting
+// starts over too. Attempts while locked out get ErrTooManyAttempts.
+func (r *TwoFactorRepository) ReserveAttempt(userID uint, limit int, lockout time.Duration) error {
+	now := time.Now()
+	fresh := "(totp_attempts_since IS NULL OR totp_attempts_since <= ? OR totp_locked_until <= ?)"
+	next := "CASE WHEN " + fresh + " THEN 1 ELSE totp_failed_attempts + 1 END"
+	windowStart := now.Add(-lockout)
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND (totp_locked_until IS NULL OR totp_locked_until <= ?)", userID, now).
+		Updates(map[string]interface{}{
+			"totp_failed_attempts": gorm.Expr(next, windowStart, now),
+			"totp_attempts_since":  gorm.Expr("CASE WHEN "+fresh+" THEN ?::timestamptz ELSE totp_attempts_since END", windowStart, now, now),
+			"totp_locked_until":    gorm.Expr("CASE WHEN "+next+" >= ? THEN ?::timestamptz END", windowStart, now, limit, now.Add(lockout)),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTooManyAttempts
+	}
+	return nil
+}
+
+// ResetFailedAttempts clears the attempts and any lockout after a correct
+// code.
+func (r *TwoFactorRepository) ResetFailedAttempts(userID uint) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"totp_failed_attempts": 0,
+			"totp_attempts_since":  nil,
+			"totp_locked_until":    nil,
+		}).Error
+}
+
+func (r *TwoFactorRepository) CreateChallenge(challenge *models.LoginChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+func (r *TwoFactorRepository) GetChallenge(tokenHash string) (*models.LoginChallenge, error) {
+	var challenge models.LoginChallenge
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&challenge).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// UseChallenge marks the challenge as used. A challenge that was used
+// already gets ErrChallengeUsed, so concurrent logins with the same
+// challenge cannot both succeed.
+func (r *TwoFactorRepository) UseChallenge(id uint) error {
+	result := r.db.Model(&models.LoginChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChallengeUsed
+	}
+	return nil
+}
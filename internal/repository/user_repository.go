@@ -11,7 +11,10 @@ import (
 
 type UserRepositoryInterface interface {
 	Create(user *models.User) error
+	GetByID(id uint) (*models.User, error)
+	Update(user *models.User, columns ...string) error
 	FindByLogin(login string) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
 	Exists(login string) (bool, error)
 }
 
@@ -30,6 +33,26 @@ func (r *UserRepository) Create(user *models.User) error {
 	return err
 }
 
+func (r *UserRepository) GetByID(id uint) (*models.User, error) {
+	start := time.Now()
+	var user models.User
+	err := r.db.First(&user, id).Error
+	metrics.RecordDatabaseQuery("select", "users", time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update writes the named columns of the user, including zero values. Other
+// columns are left alone, so that concurrent changes to them are not undone.
+func (r *UserRepository) Update(user *models.User, columns ...string) error {
+	start := time.Now()
+	err := r.db.Model(user).Select(columns).Updates(user).Error
+	metrics.RecordDatabaseQuery("update", "users", time.Since(start).Seconds())
+	return err
+}
+
 func (r *UserRepository) FindByLogin(login string) (*models.User, error) {
 	start := time.Now()
 	var user models.User
@@ -41,6 +64,17 @@ func (r *UserRepository) FindByLogin(login string) (*models.User, error) {
 	return &user, nil
 }
 
+func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
+	start := time.Now()
+	var user models.User
+	err := r.db.Where("email = ?", email).First(&user).Error
+	metrics.RecordDatabaseQuery("select", "users", time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *UserRepository) Exists(login string) (bool, error) {
 	start := time.Now()
 	var count int64
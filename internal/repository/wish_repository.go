@@ -1,9 +1,19 @@
 package repository
 
 import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
 	"wishlist-app/internal/models"
+	"wishlist-app/pkg/money"
+	"wishlist-app/pkg/pagination"
+	"wishlist-app/pkg/rank"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WishRepositoryInterface interface {
@@ -11,25 +21,243 @@ type WishRepositoryInterface interface {
 	GetByID(id uint) (*models.Wish, error)
 	Update(wish *models.Wish) error
 	Delete(id uint) error
-	GetByUserID(userID uint) ([]models.Wish, error)
-	GetByUsername(username string) ([]models.Wish, error)
+	GetByUserID(userID uint, filter models.WishFilter, after *pagination.Cursor, limit int) ([]models.Wish, *pagination.Cursor, error)
+	GetReceived(userID uint) ([]models.Wish, error)
+	GetByUsername(username string, visibilities []models.Visibility, filter models.WishFilter, after *pagination.Cursor, limit int) ([]models.Wish, *pagination.Cursor, error)
+	GetByWishlistID(wishlistID uint, visibilities []models.Visibility) ([]models.Wish, error)
+	GetFeed(userID uint, cursor *pagination.Cursor, limit int) ([]models.Wish, error)
+	Search(viewerID uint, search models.WishSearch, cursor *pagination.Cursor, limit int) ([]models.WishSearchResult, error)
+	GetByOccasionIDs(occasionIDs []uint, visibilities []models.Visibility) ([]models.Wish, error)
+	NextRank(userID uint, after string) (string, error)
+	UpdateRank(id uint, rank string) error
+	ReplaceTags(wish *models.Wish, tags []models.Tag) error
+	HasPledges(wishID uint) (bool, error)
+}
+
+func applyWishFilter(query *gorm.DB, f models.WishFilter) *gorm.DB {
+	if f.Status != "" {
+		query = query.Where("wishes.status = ?", f.Status)
+	}
+	if f.Category != "" {
+		query = query.Where("wishes.category = ?", f.Category)
+	}
+	if f.Tag != "" {
+		query = query.Where(`wishes.id IN (SELECT wish_tags.wish_id FROM wish_tags
+			JOIN tags ON tags.id = wish_tags.tag_id WHERE tags.name = ?)`, f.Tag)
+	}
+	if f.HasImage != nil {
+		hasImage := "(COALESCE(wishes.image_url, '') <> '' OR wishes.image_key <> '')"
+		if !*f.HasImage {
+			hasImage = "NOT " + hasImage
+		}
+		query = query.Where(hasImage)
+	}
+	if f.CreatedSince != nil {
+		query = query.Where("wishes.created_at >= ?", *f.CreatedSince)
+	}
+	if !f.HasPrice() {
+		return query
+	}
+
+	query = query.
+		Joins("LEFT JOIN exchange_rates AS from_rate ON from_rate.currency = wishes.currency").
+		Joins("LEFT JOIN exchange_rates AS to_rate ON to_rate.currency = ?", f.Currency)
+	if f.MinPrice != nil {
+		query = query.Where("? >= ?", priceIn("price_max_minor", f.Currency), *f.MinPrice)
+	}
+	if f.MaxPrice != nil {
+		query = query.Where("? <= ?", priceIn("price_minor", f.Currency), *f.MaxPrice)
+	}
+	return query
+}
+
+// wishOrder is the sort key of a list of wishes, compared as text cast to
+// keyType when paging. Wishes with the same key are ordered by ID in the same
+// direction, and NULL keys come last.
+type wishOrder struct {
+	key     clause.Expr
+	keyType string
+	desc    bool
+}
+
+func orderFor(f models.WishFilter) wishOrder {
+	switch f.Sort {
+	case models.SortCreatedAsc, models.SortCreatedDesc:
+		return wishOrder{gorm.Expr("wishes.created_at"), "timestamptz", f.Sort == models.SortCreatedDesc}
+	case models.SortUpdatedAsc, models.SortUpdatedDesc:
+		return wishOrder{gorm.Expr("wishes.updated_at"), "timestamptz", f.Sort == models.SortUpdatedDesc}
+	case models.SortTitleAsc, models.SortTitleDesc:
+		return wishOrder{gorm.Expr("lower(wishes.title)"), "text", f.Sort == models.SortTitleDesc}
+	case models.SortPriceAsc:
+		return wishOrder{priceIn("price_minor", f.Currency), "numeric", false}
+	case models.SortPriceDesc:
+		return wishOrder{priceIn("price_max_minor", f.Currency), "numeric", true}
+	}
+	// Ranks compare byte by byte, so the database collation must not be used.
+	return wishOrder{gorm.Expr(`wishes.rank COLLATE "C"`), `text COLLATE "C"`, false}
 }
 
+// pageWishes runs a query over wishes built with applyWishFilter and returns
+// the page of at most limit wishes after the cursor, in the order of the
+// filter, with their owner, tags, reservations and pledges. The cursor of the
+// next page is nil on the last page; a zero limit returns every wish.
+//
+// Pages continue from the sort key of the last wish rather than an offset, so
+// wishes added or removed in between do not shift later pages.
+func (r *WishRepository) pageWishes(query *gorm.DB, f models.WishFilter, after *pagination.Cursor, limit int) ([]models.Wish, *pagination.Cursor, error) {
+	order := orderFor(f)
+	direction, compare := "ASC", ">"
+	if order.desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if after != nil {
+		if after.Sort != string(f.Sort) {
+			return nil, nil, pagination.ErrInvalidCursor
+		}
+		if after.Null {
+			query = query.Where(clause.Expr{
+				SQL:  "? IS NULL AND wishes.id " + compare + " ?",
+				Vars: []interface{}{order.key, after.ID},
+			})
+		} else {
+			query = query.Where(clause.Expr{
+				SQL:  fmt.Sprintf("(? %[1]s ?::%[2]s OR (? = ?::%[2]s AND wishes.id %[1]s ?) OR ? IS NULL)", compare, order.keyType),
+				Vars: []interface{}{order.key, after.Value, order.key, after.Value, after.ID, order.key},
+			})
+		}
+	}
+
+	query = query.
+		Select("wishes.id, (?)::text AS sort_key", order.key).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:  fmt.Sprintf("? %[1]s NULLS LAST, wishes.id %[1]s", direction),
+			Vars: []interface{}{order.key},
+		}})
+	if limit > 0 {
+		query = query.Limit(limit + 1)
+	}
+
+	var keys []struct {
+		ID      uint
+		SortKey *string
+	}
+	if err := query.Scan(&keys).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var next *pagination.Cursor
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+		last := keys[limit-1]
+		next = &pagination.Cursor{ID: last.ID, Sort: string(f.Sort), Null: last.SortKey == nil}
+		if last.SortKey != nil {
+			next.Value = *last.SortKey
+		}
+	}
+	if len(keys) == 0 {
+		return []models.Wish{}, nil, nil
+	}
+
+	ids := make([]uint, len(keys))
+	for i, key := range keys {
+		ids[i] = key.ID
+	}
+	var found []models.Wish
+	if err := r.db.
+		Preload("User").
+		Preload("Tags").
+		Preload("Reservations").
+		Preload("Pledges").
+		Preload("SourceUser").
+		Where("id IN ?", ids).
+		Find(&found).Error; err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[uint]models.Wish, len(found))
+	for _, wish := range found {
+		byID[wish.ID] = wish
+	}
+	wishes := make([]models.Wish, 0, len(keys))
+	for _, id := range ids {
+		if wish, ok := byID[id]; ok {
+			wishes = append(wishes, wish)
+		}
+	}
+	return wishes, next, nil
+}
+
+// priceIn converts a price column of wishes, in minor units of the wish's
+// currency, into minor units of the given currency using the exchange rates
+// joined by applyWishFilter. It is NULL when the price is unknown or a rate is
+// missing.
+func priceIn(column, currency string) clause.Expr {
+	return gorm.Expr(`CASE WHEN wishes.price_unknown THEN NULL
+		WHEN wishes.currency = ? THEN wishes.`+column+`::numeric
+		ELSE wishes.`+column+` * to_rate.rate::numeric / from_rate.rate::numeric * ? / `+minorUnitScale+` END`,
+		currency, minorUnitsPerUnit(currency))
+}
+
+// minorUnitScale is an SQL expression for the number of minor units in one
+// unit of wishes.currency.
+var minorUnitScale = func() string {
+	var sql strings.Builder
+	sql.WriteString("(CASE wishes.currency")
+	for _, code := range money.Currencies() {
+		fmt.Fprintf(&sql, " WHEN '%s' THEN %d", code, minorUnitsPerUnit(code))
+	}
+	sql.WriteString(" END)")
+	return sql.String()
+}()
+
+func minorUnitsPerUnit(currency string) int64 {
+	scale := int64(1)
+	for i := 0; i < money.Digits(currency); i++ {
+		scale *= 10
+	}
+	return scale
+}
+
+// rankOrder sorts wishes by their manual order. Ranks compare byte by byte,
+// so the database collation must not be used.
+const rankOrder = `wishes.rank COLLATE "C", wishes.id`
+
 type WishRepository struct {
-	db *gorm.DB
+	db           *gorm.DB
+	searchConfig string
 }
 
-func NewWishRepository(db *gorm.DB) *WishRepository {
-	return &WishRepository{db: db}
+// NewWishRepository returns a repository that searches wishes with the given
+// PostgreSQL text search configuration, the one migrateSearch built the
+// search vectors with.
+func NewWishRepository(db *gorm.DB, searchConfig string) *WishRepository {
+	return &WishRepository{db: db, searchConfig: searchConfig}
 }
 
+// Create stores the wish at the end of its owner's manual order. The owner's
+// row stays locked until the wish is stored, so wishes created at the same
+// moment cannot be given the same rank.
 func (r *WishRepository) Create(wish *models.Wish) error {
-	return r.db.Create(wish).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, wish.UserID).Error; err != nil {
+			return err
+		}
+
+		last, err := lastRank(tx, wish.UserID)
+		if err != nil {
+			return err
+		}
+		if wish.Rank, err = rank.After(last); err != nil {
+			return err
+		}
+		return tx.Create(wish).Error
+	})
 }
 
 func (r *WishRepository) GetByID(id uint) (*models.Wish, error) {
 	var wish models.Wish
-	if err := r.db.Preload("User").First(&wish, id).Error; err != nil {
+	if err := r.db.Preload("User").Preload("Wishlist").Preload("Tags").Preload("SourceUser").First(&wish, id).Error; err != nil {
 		return nil, err
 	}
 	return &wish, nil
@@ -43,18 +271,308 @@ func (r *WishRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Wish{}, id).Error
 }
 
-func (r *WishRepository) GetByUserID(userID uint) ([]models.Wish, error) {
+// GetByUserID returns a page of the user's wishes matching the filter, with
+// the number of people who saved each of them.
+func (r *WishRepository) GetByUserID(userID uint, filter models.WishFilter, after *pagination.Cursor, limit int) ([]models.Wish, *pagination.Cursor, error) {
+	query := applyWishFilter(r.db.Model(&models.Wish{}).Where("wishes.user_id = ?", userID), filter)
+	wishes, next, err := r.pageWishes(query, filter, after, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := r.countSaves(wishes); err != nil {
+		return nil, nil, err
+	}
+	return wishes, next, nil
+}
+
+// countSaves sets SavedBy of the wishes to the number of users who have a
+// copy of them. Copies that were deleted no longer count.
+func (r *WishRepository) countSaves(wishes []models.Wish) error {
+	if len(wishes) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(wishes))
+	for i := range wishes {
+		ids[i] = wishes[i].ID
+	}
+
+	var counts []struct {
+		SourceWishID uint
+		SavedBy      int
+	}
+	if err := r.db.Model(&models.Wish{}).
+		Select("source_wish_id, COUNT(DISTINCT user_id) AS saved_by").
+		Where("source_wish_id IN ?", ids).
+		Group("source_wish_id").
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+
+	savedBy := make(map[uint]int, len(counts))
+	for _, count := range counts {
+		savedBy[count.SourceWishID] = count.SavedBy
+	}
+	for i := range wishes {
+		wishes[i].SavedBy = savedBy[wishes[i].ID]
+	}
+	return nil
+}
+
+// GetReceived returns the user's wishes that were marked as received, most
+// recently received first, including ones archived afterwards.
+func (r *WishRepository) GetReceived(userID uint) ([]models.Wish, error) {
 	var wishes []models.Wish
-	if err := r.db.Preload("User").Where("user_id = ?", userID).Find(&wishes).Error; err != nil {
+	if err := r.db.
+		Preload("User").
+		Preload("Tags").
+		Where("user_id = ? AND received_at IS NOT NULL", userID).
+		Order("received_at DESC, id DESC").
+		Find(&wishes).Error; err != nil {
 		return nil, err
 	}
 	return wishes, nil
 }
 
-func (r *WishRepository) GetByUsername(username string) ([]models.Wish, error) {
+// GetByUsername returns a page of the user's active wishes matching the
+// filter whose own visibility and list visibility are both among the given
+// ones.
+func (r *WishRepository) GetByUsername(username string, visibilities []models.Visibility, filter models.WishFilter, after *pagination.Cursor, limit int) ([]models.Wish, *pagination.Cursor, error) {
+	filter.Status = models.WishActive
+	query := applyWishFilter(r.db.Model(&models.Wish{}).
+		Joins("JOIN users ON users.id = wishes.user_id AND users.deleted_at IS NULL").
+		Joins("JOIN wishlists ON wishlists.id = wishes.wishlist_id AND wishlists.deleted_at IS NULL").
+		Where("users.login = ?", username).
+		Where("wishes.visibility IN ? AND wishlists.visibility IN ?", visibilities, visibilities), filter)
+	return r.pageWishes(query, filter, after, limit)
+}
+
+func (r *WishRepository) GetByWishlistID(wishlistID uint, visibilities []models.Visibility) ([]models.Wish, error) {
+	var wishes []models.Wish
+	if err := r.db.
+		Preload("User").
+		Preload("Tags").
+		Preload("Reservations").
+		Preload("Pledges").
+		Where("wishlist_id = ? AND visibility IN ? AND status = ?", wishlistID, visibilities, models.WishActive).
+		Order(rankOrder).
+		Find(&wishes).Error; err != nil {
+		return nil, err
+	}
+	return wishes, nil
+}
+
+// GetFeed returns the most recently updated wishes of the user's friends that
+// are visible to friends, newest first, starting after the cursor.
+func (r *WishRepository) GetFeed(userID uint, cursor *pagination.Cursor, limit int) ([]models.Wish, error) {
+	visibilities := models.AccessFriend.Visibilities()
+	query := r.db.
+		Joins(`JOIN friendships ON friendships.status = ? AND (
+			(friendships.requester_id = ? AND friendships.addressee_id = wishes.user_id) OR
+			(friendships.addressee_id = ? AND friendships.requester_id = wishes.user_id))`,
+			models.FriendshipAccepted, userID, userID).
+		Joins("JOIN wishlists ON wishlists.id = wishes.wishlist_id AND wishlists.deleted_at IS NULL").
+		Preload("User").
+		Preload("Tags").
+		Preload("Reservations").
+		Preload("Pledges").
+		Where("wishes.visibility IN ? AND wishlists.visibility IN ?", visibilities, visibilities).
+		Where("wishes.status = ?", models.WishActive)
+
+	if cursor != nil {
+		updatedAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		query = query.Where("(wishes.updated_at, wishes.id) < (?, ?)", updatedAt, cursor.ID)
+	}
+
+	var wishes []models.Wish
+	if err := query.Order("wishes.updated_at DESC, wishes.id DESC").Limit(limit).Find(&wishes).Error; err != nil {
+		return nil, err
+	}
+	return wishes, nil
+}
+
+// Markers that ts_headline puts around matching words. Control characters
+// cannot be confused with the text, which is escaped before they are turned
+// into <mark> tags.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+var highlighter = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// Search returns a page of the wishes matching the query that the viewer can
+// see, most relevant first, starting after the cursor. Those are the viewer's
+// own wishes and the active wishes of other users that are public, or visible
+// to friends if the viewer is one.
+func (r *WishRepository) Search(viewerID uint, search models.WishSearch, cursor *pagination.Cursor, limit int) ([]models.WishSearchResult, error) {
+	tsquery := gorm.Expr("websearch_to_tsquery(?::regconfig, ?)", r.searchConfig, search.Query)
+	public, friends := models.AccessPublic.Visibilities(), models.AccessFriend.Visibilities()
+
+	matches := applyWishFilter(r.db.Model(&models.Wish{}), search.Filter).
+		Select("wishes.id, ts_rank(wishes.search_vector, ?) AS rank", tsquery).
+		Joins("JOIN wishlists ON wishlists.id = wishes.wishlist_id AND wishlists.deleted_at IS NULL").
+		Where("wishes.search_vector @@ ?", tsquery).
+		Where(`wishes.user_id = ? OR (wishes.status = ? AND (
+			(wishes.visibility IN ? AND wishlists.visibility IN ?) OR
+			(wishes.visibility IN ? AND wishlists.visibility IN ? AND EXISTS (
+				SELECT 1 FROM friendships WHERE friendships.status = ? AND (
+					(friendships.requester_id = ? AND friendships.addressee_id = wishes.user_id) OR
+					(friendships.addressee_id = ? AND friendships.requester_id = wishes.user_id))))))`,
+			viewerID, models.WishActive, public, public, friends, friends,
+			models.FriendshipAccepted, viewerID, viewerID)
+	if search.Owner != "" {
+		matches = matches.Where("wishes.user_id IN (SELECT id FROM users WHERE login = ? AND deleted_at IS NULL)", search.Owner)
+	}
+
+	page := r.db.Table("(?) AS matches", matches)
+	if cursor != nil {
+		rank, err := strconv.ParseFloat(cursor.Value, 64)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		page = page.Where("(matches.rank, matches.id) < (?, ?)", rank, cursor.ID)
+	}
+	page = page.Order("matches.rank DESC, matches.id DESC").Limit(limit)
+
+	// Headlines are only computed for the rows of the page.
+	var rows []struct {
+		ID       uint
+		Rank     float64
+		Headline string
+		Snippet  string
+	}
+	if err := r.db.Table("(?) AS page", page).
+		Select(`page.id, page.rank,
+			ts_headline(?::regconfig, wishes.title, ?, ?) AS headline,
+			ts_headline(?::regconfig, wishes.comment, ?, ?) AS snippet`,
+			r.searchConfig, tsquery, headlineOptions(`HighlightAll=true`),
+			r.searchConfig, tsquery, headlineOptions(`MaxFragments=2, MinWords=5, MaxWords=20, FragmentDelimiter=" … "`)).
+		Joins("JOIN wishes ON wishes.id = page.id").
+		Order("page.rank DESC, page.id DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
 	var wishes []models.Wish
-	if err := r.db.Joins("User").Where("users.login = ?", username).Find(&wishes).Error; err != nil {
+	if err := r.db.
+		Preload("User").
+		Preload("Tags").
+		Preload("Reservations").
+		Preload("Pledges").
+		Where("id IN ?", ids).
+		Find(&wishes).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Wish, len(wishes))
+	for _, wish := range wishes {
+		byID[wish.ID] = wish
+	}
+
+	results := make([]models.WishSearchResult, 0, len(rows))
+	for _, row := range rows {
+		wish, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, models.WishSearchResult{
+			Wish:     wish,
+			Rank:     row.Rank,
+			Headline: highlight(row.Headline),
+			Snippet:  highlight(row.Snippet),
+		})
+	}
+	return results, nil
+}
+
+func headlineOptions(options string) string {
+	return fmt.Sprintf(`StartSel="%s", StopSel="%s", %s`, highlightStart, highlightStop, options)
+}
+
+// highlight escapes a headline for use in HTML and marks the matches.
+func highlight(headline string) string {
+	return highlighter.Replace(html.EscapeString(headline))
+}
+
+// GetByOccasionIDs returns visible wishes attached to any of the occasions,
+// either directly or through their list.
+func (r *WishRepository) GetByOccasionIDs(occasionIDs []uint, visibilities []models.Visibility) ([]models.Wish, error) {
+	var wishes []models.Wish
+	if err := r.db.
+		Joins("JOIN wishlists ON wishlists.id = wishes.wishlist_id AND wishlists.deleted_at IS NULL").
+		Preload("User").
+		Preload("Wishlist").
+		Preload("Tags").
+		Preload("Reservations").
+		Preload("Pledges").
+		Where("wishes.occasion_id IN ? OR wishlists.occasion_id IN ?", occasionIDs, occasionIDs).
+		Where("wishes.visibility IN ? AND wishlists.visibility IN ?", visibilities, visibilities).
+		Where("wishes.status = ?", models.WishActive).
+		Order(rankOrder).
+		Find(&wishes).Error; err != nil {
 		return nil, err
 	}
 	return wishes, nil
 }
+
+// lastRank returns the highest rank among the user's wishes, or an empty
+// string if the user has none.
+func lastRank(db *gorm.DB, userID uint) (string, error) {
+	var ranks []string
+	if err := db.Model(&models.Wish{}).
+		Where("user_id = ?", userID).
+		Order(`rank COLLATE "C" DESC`).
+		Limit(1).
+		Pluck("rank", &ranks).Error; err != nil {
+		return "", err
+	}
+	if len(ranks) == 0 {
+		return "", nil
+	}
+	return ranks[0], nil
+}
+
+// NextRank returns the lowest rank of the user's wishes that sorts after the
+// given one, or an empty string if there is none.
+func (r *WishRepository) NextRank(userID uint, after string) (string, error) {
+	var ranks []string
+	if err := r.db.Model(&models.Wish{}).
+		Where(`user_id = ? AND rank COLLATE "C" > ?`, userID, after).
+		Order(`rank COLLATE "C"`).
+		Limit(1).
+		Pluck("rank", &ranks).Error; err != nil {
+		return "", err
+	}
+	if len(ranks) == 0 {
+		return "", nil
+	}
+	return ranks[0], nil
+}
+
+// UpdateRank moves a single wish without touching any other row.
+func (r *WishRepository) UpdateRank(id uint, rank string) error {
+	return r.db.Model(&models.Wish{}).Where("id = ?", id).Update("rank", rank).Error
+}
+
+// ReplaceTags sets the wish's tags to exactly the given ones.
+func (r *WishRepository) ReplaceTags(wish *models.Wish, tags []models.Tag) error {
+	return r.db.Model(wish).Association("Tags").Replace(tags)
+}
+
+// HasPledges reports whether anyone has pledged toward the wish.
+func (r *WishRepository) HasPledges(wishID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&models.Pledge{}).Where("wish_id = ?", wishID).Limit(1).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
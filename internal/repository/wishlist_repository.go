@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+
+	"wishlist-app/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type WishlistRepositoryInterface interface {
+	Create(wishlist *models.Wishlist) error
+	GetByID(id uint) (*models.Wishlist, error)
+	Update(wishlist *models.Wishlist) error
+	Delete(id, defaultID uint) error
+	GetByUserID(userID uint) ([]models.Wishlist, error)
+	GetByUsername(username string, visibilities []models.Visibility) ([]models.Wishlist, error)
+	GetOrCreateDefault(userID uint) (*models.Wishlist, error)
+}
+
+type WishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) *WishlistRepository {
+	return &WishlistRepository{db: db}
+}
+
+// Create appends the list after the user's existing lists.
+func (r *WishlistRepository) Create(wishlist *models.Wishlist) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var position int
+		if err := tx.Model(&models.Wishlist{}).
+			Where("user_id = ?", wishlist.UserID).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Scan(&position).Error; err != nil {
+			return err
+		}
+		wishlist.Position = position
+		return tx.Create(wishlist).Error
+	})
+}
+
+func (r *WishlistRepository) GetByID(id uint) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	if err := r.db.Preload("User").Preload("CoOrganizer").First(&wishlist, id).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *WishlistRepository) Update(wishlist *models.Wishlist) error {
+	return r.db.Save(wishlist).Error
+}
+
+// Delete removes the list and moves its wishes to the list with defaultID.
+func (r *WishlistRepository) Delete(id, defaultID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Wish{}).Where("wishlist_id = ?", id).Update("wishlist_id", defaultID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Wishlist{}, id).Error
+	})
+}
+
+func (r *WishlistRepository) GetByUserID(userID uint) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	if err := r.db.Preload("User").Preload("CoOrganizer").Where("user_id = ?", userID).Order("position, id").Find(&wishlists).Error; err != nil {
+		return nil, err
+	}
+	return wishlists, nil
+}
+
+func (r *WishlistRepository) GetByUsername(username string, visibilities []models.Visibility) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	if err := r.db.
+		Joins("JOIN users ON users.id = wishlists.user_id AND users.deleted_at IS NULL").
+		Preload("User").
+		Preload("CoOrganizer").
+		Where("users.login = ? AND wishlists.visibility IN ?", username, visibilities).
+		Order("wishlists.position, wishlists.id").
+		Find(&wishlists).Error; err != nil {
+		return nil, err
+	}
+	return wishlists, nil
+}
+
+// GetOrCreateDefault returns the user's default list, creating it on first use.
+func (r *WishlistRepository) GetOrCreateDefault(userID uint) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := r.db.Where("user_id = ? AND is_default", userID).First(&wishlist).Error
+	if err == nil {
+		return &wishlist, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	wishlist = models.Wishlist{
+		UserID:    userID,
+		Title:     models.DefaultWishlistTitle,
+		IsDefault: true,
+	}
+	if err := r.Create(&wishlist); err != nil {
+		// Another request may have created the default list concurrently.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			if err := r.db.Where("user_id = ? AND is_default", userID).First(&wishlist).Error; err != nil {
+				return nil, err
+			}
+			return &wishlist, nil
+		}
+		return nil, err
+	}
+	return &wishlist, nil
+}
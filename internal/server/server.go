@@ -2,6 +2,7 @@ package server
 
 import (
 	"net/http"
+	"time"
 	"wishlist-app/internal/repository"
 	"wishlist-app/internal/service"
 
@@ -10,6 +11,9 @@ import (
 	"wishlist-app/internal/handler"
 	"wishlist-app/internal/middleware"
 	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/mail"
+	"wishlist-app/pkg/product"
+	"wishlist-app/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -20,6 +24,7 @@ import (
 type Server struct {
 	httpServer *http.Server
 	logger     logger.Logger
+	stop       chan struct{}
 }
 
 func New(cfg *config.Config, logger logger.Logger) *Server {
@@ -35,27 +40,186 @@ func New(cfg *config.Config, logger logger.Logger) *Server {
 	}
 
 	userRepo := repository.NewUserRepository(db)
-	wishRepo := repository.NewWishRepository(db)
+	wishRepo := repository.NewWishRepository(db, cfg.Search.Language)
+	wishlistRepo := repository.NewWishlistRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+	shareLinkRepo := repository.NewShareLinkRepository(db)
+	pledgeRepo := repository.NewPledgeRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	friendshipRepo := repository.NewFriendshipRepository(db)
+	occasionRepo := repository.NewOccasionRepository(db)
+	exchangeRepo := repository.NewGiftExchangeRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	rateRepo := repository.NewExchangeRateRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	resetRepo := repository.NewPasswordResetRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
 
-	authService := service.NewAuthService(userRepo, cfg)
-	wishService := service.NewWishService(wishRepo, userRepo)
+	mailer := newMailer(cfg, logger)
+	twoFactorService := service.NewTwoFactorService(userRepo, twoFactorRepo, cfg)
+	authService := service.NewAuthService(userRepo, sessionRepo, resetRepo, twoFactorService, mailer, cfg)
+	emailService := service.NewEmailService(userRepo, mailer, cfg)
+	accessPolicy := service.NewAccessPolicy(friendshipRepo)
+	wishService := service.NewWishService(wishRepo, userRepo, wishlistRepo, tagRepo, accessPolicy)
+	wishlistService := service.NewWishlistService(wishlistRepo, wishRepo, userRepo, accessPolicy)
+	reservationService := service.NewReservationService(reservationRepo, wishRepo, accessPolicy)
+	shareLinkService := service.NewShareLinkService(shareLinkRepo, wishlistRepo, wishRepo, accessPolicy)
+	pledgeService := service.NewPledgeService(pledgeRepo, wishRepo, accessPolicy)
+	commentService := service.NewCommentService(commentRepo, wishRepo, accessPolicy)
+	friendshipService := service.NewFriendshipService(friendshipRepo, userRepo, wishRepo)
+	occasionService := service.NewOccasionService(occasionRepo, wishRepo, userRepo, accessPolicy)
+	exchangeService := service.NewGiftExchangeService(exchangeRepo, userRepo, wishRepo, accessPolicy)
+	tagService := service.NewTagService(tagRepo, userRepo, accessPolicy)
+	currencyService := service.NewCurrencyService(rateRepo, userRepo, wishRepo, accessPolicy, cfg.Money.DefaultCurrency)
+	productService := service.NewProductService(product.NewHTTPFetcher(cfg.Product.FetchTimeout, cfg.Product.MaxPageBytes))
+	imageService := service.NewImageService(wishRepo, newStorage(cfg, logger), service.ImageLimits{
+		MaxBytes:      cfg.Storage.MaxUploadBytes,
+		MaxPixels:     cfg.Storage.MaxPixels,
+		ThumbnailSize: cfg.Storage.ThumbnailSize,
+		URLExpiry:     cfg.Storage.URLExpiry,
+	})
 
 	api := router.Group("/api")
 	{
-		authHandler := handler.NewAuthHandler(cfg, logger, authService)
+		authHandler := handler.NewAuthHandler(cfg, logger, authService, emailService)
 		api.POST("/register", authHandler.Register)
 		api.POST("/login", authHandler.Login)
+		api.POST("/login/2fa", authHandler.CompleteLogin)
+		api.POST("/refresh", authHandler.Refresh)
+		api.POST("/password/forgot", authHandler.ForgotPassword)
+		api.POST("/password/reset", authHandler.ResetPassword)
+
+		emailHandler := handler.NewEmailHandler(cfg, logger, emailService)
+		api.POST("/email/verify", emailHandler.Verify)
+
+		wishHandler := handler.NewWishHandler(cfg, logger, wishService, productService, imageService)
+		api.GET("/wishes/:username", middleware.OptionalAuth(authService, logger), wishHandler.GetByUsername)
+
+		wishlistHandler := handler.NewWishlistHandler(cfg, logger, wishlistService)
+		api.GET("/lists/:id", middleware.OptionalAuth(authService, logger), wishlistHandler.GetByID)
+		api.GET("/users/:username/lists", middleware.OptionalAuth(authService, logger), wishlistHandler.GetByUsername)
+
+		occasionHandler := handler.NewOccasionHandler(cfg, logger, occasionService)
+		api.GET("/users/:username/occasions/upcoming", middleware.OptionalAuth(authService, logger), occasionHandler.Upcoming)
 
-		wishHandler := handler.NewWishHandler(cfg, logger, wishService)
-		api.GET("/wishes/:username", wishHandler.GetByUsername)
+		tagHandler := handler.NewTagHandler(cfg, logger, tagService)
+		api.GET("/users/:username/tags", middleware.OptionalAuth(authService, logger), tagHandler.Facets)
+		api.GET("/categories", tagHandler.Categories)
+
+		currencyHandler := handler.NewCurrencyHandler(cfg, logger, currencyService)
+		api.GET("/users/:username/total", middleware.OptionalAuth(authService, logger), currencyHandler.Total)
+		api.GET("/exchange-rates", currencyHandler.GetRates)
+
+		imageHandler := handler.NewImageHandler(cfg, logger, imageService)
+		api.GET("/images/*key", imageHandler.Serve)
+
+		shareLinkHandler := handler.NewShareLinkHandler(cfg, logger, shareLinkService)
+		api.GET("/shared/:token", middleware.OptionalAuth(authService, logger), shareLinkHandler.Resolve)
 
 		auth := api.Group("")
-		auth.Use(middleware.Auth(cfg, logger))
+		auth.Use(middleware.Auth(authService, logger))
 		{
+			auth.POST("/logout", authHandler.Logout)
+			auth.PUT("/password", authHandler.ChangePassword)
+			auth.GET("/sessions", authHandler.Sessions)
+			auth.DELETE("/sessions", authHandler.RevokeOtherSessions)
+			auth.DELETE("/sessions/:id", authHandler.RevokeSession)
+			auth.GET("/email", emailHandler.Status)
+			auth.PUT("/email", emailHandler.Change)
+			auth.POST("/email/verification", emailHandler.ResendVerification)
+
+			twoFactorHandler := handler.NewTwoFactorHandler(cfg, logger, twoFactorService)
+			auth.GET("/2fa", twoFactorHandler.Status)
+			auth.POST("/2fa/enroll", twoFactorHandler.Enroll)
+			auth.POST("/2fa/confirm", twoFactorHandler.Confirm)
+			auth.POST("/2fa/disable", twoFactorHandler.Disable)
+
 			auth.POST("/wishes", wishHandler.Create)
 			auth.PUT("/wishes/:id", wishHandler.Update)
 			auth.DELETE("/wishes/:id", wishHandler.Delete)
 			auth.GET("/wishes", wishHandler.GetByUserID)
+			auth.POST("/wishes/reorder", wishHandler.Reorder)
+			auth.POST("/wishes/preview", wishHandler.PreviewProduct)
+			auth.PUT("/wishes/:id/received", wishHandler.SetReceived)
+			auth.POST("/wishes/:id/copy", wishHandler.Copy)
+			auth.POST("/wishes/:id/receive", wishHandler.Receive)
+			auth.POST("/wishes/:id/archive", wishHandler.Archive)
+			auth.POST("/wishes/:id/restore", wishHandler.Restore)
+			auth.PUT("/wishes/:id/thank-you-note", wishHandler.SetThankYouNote)
+			auth.PUT("/wishes/:id/image", imageHandler.Upload)
+			auth.DELETE("/wishes/:id/image", imageHandler.Remove)
+			auth.GET("/history", wishHandler.History)
+			auth.GET("/search", wishHandler.Search)
+
+			auth.POST("/lists", wishlistHandler.Create)
+			auth.PUT("/lists/:id", wishlistHandler.Update)
+			auth.DELETE("/lists/:id", wishlistHandler.Delete)
+			auth.GET("/lists", wishlistHandler.GetByUserID)
+			auth.PUT("/lists/:id/co-organizer", wishlistHandler.SetCoOrganizer)
+
+			auth.POST("/lists/:id/share-links", shareLinkHandler.Create)
+			auth.GET("/lists/:id/share-links", shareLinkHandler.GetByWishlistID)
+			auth.DELETE("/share-links/:id", shareLinkHandler.Revoke)
+			auth.POST("/share-links/:id/rotate", shareLinkHandler.Rotate)
+
+			reservationHandler := handler.NewReservationHandler(cfg, logger, reservationService)
+			auth.POST("/wishes/:id/reservation", reservationHandler.Reserve)
+			auth.DELETE("/wishes/:id/reservation", reservationHandler.Unreserve)
+			auth.GET("/reservations", reservationHandler.GetByUserID)
+
+			pledgeHandler := handler.NewPledgeHandler(cfg, logger, pledgeService)
+			auth.POST("/wishes/:id/pledges", pledgeHandler.Create)
+			auth.GET("/pledges", pledgeHandler.GetByUserID)
+			auth.PUT("/pledges/:id", pledgeHandler.Update)
+			auth.DELETE("/pledges/:id", pledgeHandler.Delete)
+
+			// Threads are read from /comments because GET /wishes/:username
+			// already takes the path segment after /wishes.
+			commentHandler := handler.NewCommentHandler(cfg, logger, commentService)
+			auth.POST("/wishes/:id/comments", commentHandler.Create)
+			auth.GET("/comments", commentHandler.GetByWishID)
+			auth.PUT("/comments/:id", commentHandler.Update)
+			auth.DELETE("/comments/:id", commentHandler.Delete)
+
+			friendshipHandler := handler.NewFriendshipHandler(cfg, logger, friendshipService)
+			auth.GET("/friends", friendshipHandler.GetFriends)
+			auth.DELETE("/friends/:id", friendshipHandler.Remove)
+			auth.POST("/friends/requests", friendshipHandler.SendRequest)
+			auth.GET("/friends/requests", friendshipHandler.GetRequests)
+			auth.POST("/friends/requests/:id/accept", friendshipHandler.Accept)
+			auth.POST("/friends/requests/:id/decline", friendshipHandler.Decline)
+			auth.GET("/feed", friendshipHandler.Feed)
+
+			auth.POST("/occasions", occasionHandler.Create)
+			auth.GET("/occasions", occasionHandler.GetByUserID)
+			auth.PUT("/occasions/:id", occasionHandler.Update)
+			auth.DELETE("/occasions/:id", occasionHandler.Delete)
+			auth.POST("/occasions/:id/attach", occasionHandler.Attach)
+			auth.POST("/occasions/:id/detach", occasionHandler.Detach)
+
+			auth.POST("/tags", tagHandler.Create)
+			auth.GET("/tags", tagHandler.GetByUserID)
+			auth.PUT("/tags/:id", tagHandler.Rename)
+			auth.DELETE("/tags/:id", tagHandler.Delete)
+
+			auth.PUT("/me/currency", currencyHandler.SetPreferred)
+			auth.PUT("/admin/exchange-rates", currencyHandler.LoadRates)
+
+			exchangeHandler := handler.NewGiftExchangeHandler(cfg, logger, exchangeService)
+			auth.POST("/exchanges", exchangeHandler.Create)
+			auth.GET("/exchanges", exchangeHandler.GetByUserID)
+			auth.GET("/exchanges/:id", exchangeHandler.GetByID)
+			auth.PUT("/exchanges/:id", exchangeHandler.Update)
+			auth.DELETE("/exchanges/:id", exchangeHandler.Delete)
+			auth.POST("/exchanges/:id/participants", exchangeHandler.Invite)
+			auth.DELETE("/exchanges/:id/participants/:userId", exchangeHandler.RemoveParticipant)
+			auth.POST("/exchanges/:id/join", exchangeHandler.Join)
+			auth.POST("/exchanges/:id/leave", exchangeHandler.Leave)
+			auth.POST("/exchanges/:id/exclusions", exchangeHandler.AddExclusion)
+			auth.DELETE("/exchanges/:id/exclusions/:exclusionId", exchangeHandler.RemoveExclusion)
+			auth.POST("/exchanges/:id/draw", exchangeHandler.Draw)
+			auth.POST("/exchanges/:id/reveal", exchangeHandler.Reveal)
+			auth.GET("/exchanges/:id/assignment", exchangeHandler.Assignment)
 		}
 	}
 
@@ -81,6 +245,9 @@ func New(cfg *config.Config, logger logger.Logger) *Server {
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	stop := make(chan struct{})
+	go pruneSessions(authService, cfg.Auth.SessionPruneInterval, logger, stop)
+
 	return &Server{
 		httpServer: &http.Server{
 			Addr:         ":" + cfg.Server.Port,
@@ -90,6 +257,7 @@ func New(cfg *config.Config, logger logger.Logger) *Server {
 			IdleTimeout:  cfg.Server.IdleTimeout,
 		},
 		logger: logger,
+		stop:   stop,
 	}
 }
 
@@ -100,5 +268,74 @@ func (s *Server) Run() error {
 
 func (s *Server) Shutdown() error {
 	s.logger.Info("Shutting down server...")
+	close(s.stop)
 	return s.httpServer.Close()
 }
+
+// pruneSessions deletes expired and revoked sessions every interval until
+// stop is closed.
+func pruneSessions(authService *service.AuthService, interval time.Duration, logger logger.Logger, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pruned, err := authService.PruneSessions()
+			if err != nil {
+				logger.Errorf("Failed to prune sessions: %v", err)
+				continue
+			}
+			logger.Debugf("Pruned %d sessions", pruned)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// newStorage opens the storage selected by the configuration for uploads.
+func newStorage(cfg *config.Config, logger logger.Logger) storage.Storage {
+	switch cfg.Storage.Driver {
+	case "s3":
+		store, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Region:    cfg.Storage.S3Region,
+			Bucket:    cfg.Storage.S3Bucket,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			PathStyle: cfg.Storage.S3PathStyle,
+		}, cfg.Server.WriteTimeout)
+		if err != nil {
+			logger.Fatalf("Failed to initialize S3 storage: %v", err)
+		}
+		return store
+	case "local":
+		store, err := storage.NewLocalStorage(cfg.Storage.LocalDir)
+		if err != nil {
+			logger.Fatalf("Failed to initialize local storage: %v", err)
+		}
+		return store
+	default:
+		logger.Fatalf("Unknown storage driver %q", cfg.Storage.Driver)
+		return nil
+	}
+}
+
+func newMailer(cfg *config.Config, logger logger.Logger) mail.Mailer {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
+		}, cfg.Server.WriteTimeout)
+	case "memory":
+		logger.Warnf("Using the in-memory mailer, emails will not be delivered")
+		return mail.NewMemoryMailer()
+	default:
+		logger.Fatalf("Unknown mail driver %q", cfg.Mail.Driver)
+		return nil
+	}
+}
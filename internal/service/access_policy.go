@@ -0,0 +1,47 @@
+package service
+
+import (
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+)
+
+// AccessPolicy decides how much of an owner's content a viewer may see.
+type AccessPolicy struct {
+	friendshipRepo repository.FriendshipRepositoryInterface
+}
+
+func NewAccessPolicy(friendshipRepo repository.FriendshipRepositoryInterface) *AccessPolicy {
+	return &AccessPolicy{
+		friendshipRepo: friendshipRepo,
+	}
+}
+
+// Access returns the access level of viewerID to content owned by ownerID.
+// A zero viewerID means an anonymous viewer.
+func (p *AccessPolicy) Access(viewerID, ownerID uint) (models.Access, error) {
+	if viewerID == 0 {
+		return models.AccessPublic, nil
+	}
+	if viewerID == ownerID {
+		return models.AccessOwner, nil
+	}
+
+	friends, err := p.friendshipRepo.AreFriends(viewerID, ownerID)
+	if err != nil {
+		return models.AccessPublic, err
+	}
+	if friends {
+		return models.AccessFriend, nil
+	}
+	return models.AccessPublic, nil
+}
+
+// CanSeeWish reports whether the viewer may see the wish. The wish's list must
+// be loaded for the list visibility to be taken into account.
+func (p *AccessPolicy) CanSeeWish(viewerID uint, wish *models.Wish) (bool, error) {
+	access, err := p.Access(viewerID, wish.UserID)
+	if err != nil {
+		return false, err
+	}
+	return wish.VisibleWith(access), nil
+}
@@ -1,89 +1,518 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"wishlist-app/internal/config"
 	"wishlist-app/internal/models"
 	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/mail"
 )
 
 type AuthService struct {
-	userRepo repository.UserRepositoryInterface
-	cfg      *config.Config
+	userRepo    repository.UserRepositoryInterface
+	sessionRepo repository.SessionRepositoryInterface
+	resetRepo   repository.PasswordResetRepositoryInterface
+	twoFactor   *TwoFactorService
+	mailer      mail.Mailer
+	sessions    *sessionCache
+	cfg         *config.Config
 }
 
-func NewAuthService(userRepo repository.UserRepositoryInterface, cfg *config.Config) *AuthService {
+func NewAuthService(userRepo repository.UserRepositoryInterface, sessionRepo repository.SessionRepositoryInterface, resetRepo repository.PasswordResetRepositoryInterface, twoFactor *TwoFactorService, mailer mail.Mailer, cfg *config.Config) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
-		cfg:      cfg,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		resetRepo:   resetRepo,
+		twoFactor:   twoFactor,
+		mailer:      mailer,
+		sessions:    newSessionCache(cfg.Auth.SessionCacheTTL),
+		cfg:         cfg,
 	}
 }
 
+// challengeAudience keeps login challenges and access tokens, which are
+// signed with the same secret, from being used in place of each other.
+const challengeAudience = "2fa-challenge"
+
+// Claims of an access token. Every access token belongs to a session and
+// stops working when the session is revoked.
 type Claims struct {
+	UserID    uint `json:"user_id"`
+	SessionID uint `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// TokenPair is what a client receives on login and on every refresh: a
+// short-lived access token and the refresh token to get the next one with.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
+
+// LoginResult holds the tokens of the new session or, for users with
+// two-factor authentication, the challenge to complete the login with.
+type LoginResult struct {
+	Tokens    *TokenPair
+	Challenge *Challenge
+}
+
+// Challenge is a short-lived token proving that the password was right. It
+// is exchanged for a session together with a second-factor code.
+type Challenge struct {
+	Token     string
+	ExpiresIn time.Duration
+}
+
+type challengeClaims struct {
 	UserID uint `json:"user_id"`
 	jwt.RegisteredClaims
 }
 
-func (s *AuthService) Register(login, password string) error {
+// Register creates a user. The email address is optional and starts out
+// unverified.
+func (s *AuthService) Register(login, password, email string) (*models.User, error) {
 	exists, err := s.userRepo.Exists(login)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if exists {
-		return errors.New("user already exists")
+		return nil, ErrUserExists
+	}
+
+	email = normalizeEmail(email)
+	if email != "" {
+		if _, err := s.userRepo.FindByEmail(email); err == nil {
+			return nil, ErrEmailTaken
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	user := &models.User{
 		Login:        login,
 		PasswordHash: string(hashedPassword),
 	}
+	if email != "" {
+		user.Email = &email
+	}
 
-	return s.userRepo.Create(user)
+	if err := s.userRepo.Create(user); err != nil {
+		// Another registration took the login or address since the checks.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, s.registrationConflict(login)
+		}
+		return nil, err
+	}
+	return user, nil
 }
 
-func (s *AuthService) Login(login, password string) (string, error) {
+// registrationConflict tells which unique column a registration collided on.
+func (s *AuthService) registrationConflict(login string) error {
+	exists, err := s.userRepo.Exists(login)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrUserExists
+	}
+	return ErrEmailTaken
+}
+
+// Login checks the credentials and starts a new session for the device
+// identified by userAgent and ip. Users with two-factor authentication get a
+// challenge instead, to be completed with CompleteLogin.
+func (s *AuthService) Login(login, password, userAgent, ip string) (*LoginResult, error) {
 	user, err := s.userRepo.FindByLogin(login)
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, errors.New("invalid credentials")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, errors.New("invalid credentials")
 	}
 
-	claims := &Claims{
+	if user.TwoFactorEnabled() {
+		challenge, err := s.challenge(user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: challenge}, nil
+	}
+
+	tokens, err := s.startSession(user, userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// CompleteLogin starts the session of a login challenge once the code from
+// the authenticator app, or a recovery code, is given.
+func (s *AuthService) CompleteLogin(challengeToken, code, userAgent, ip string) (*TokenPair, error) {
+	claims := &challengeClaims{}
+	parsed, err := jwt.ParseWithClaims(challengeToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(s.cfg.Auth.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !parsed.Valid || !claims.VerifyAudience(challengeAudience, true) || claims.ID == "" {
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+	if !user.TwoFactorEnabled() {
+		return nil, ErrInvalidChallenge
+	}
+	if err := s.twoFactor.VerifyLogin(user, hashToken(claims.ID), code); err != nil {
+		return nil, err
+	}
+	return s.startSession(user, userAgent, ip)
+}
+
+// challenge issues a login challenge whose ID is recorded on the server, so
+// it cannot be replayed once it has been used.
+func (s *AuthService) challenge(user *models.User) (*Challenge, error) {
+	id, idHash, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.Auth.TwoFactorChallengeLifetime)
+	if err := s.twoFactor.CreateChallenge(user.ID, idHash, expiresAt); err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &challengeClaims{
 		UserID: user.ID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.cfg.Auth.JWTLifetime)),
+			ID:        id,
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
+	}).SignedString([]byte(s.cfg.Auth.JWTSecret))
+	if err != nil {
+		return nil, err
+	}
+	return &Challenge{Token: token, ExpiresIn: s.cfg.Auth.TwoFactorChallengeLifetime}, nil
+}
+
+func (s *AuthService) startSession(user *models.User, userAgent, ip string) (*TokenPair, error) {
+	refreshToken, refreshTokenRow, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	session := &models.Session{
+		UserID:     user.ID,
+		UserAgent:  truncate(userAgent, 255),
+		IP:         ip,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  refreshTokenRow.ExpiresAt,
+	}
+	if err := s.sessionRepo.Create(session, refreshTokenRow); err != nil {
+		return nil, err
+	}
+	return s.tokenPair(session, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new pair of tokens. Each refresh
+// token works once: presenting one that was already exchanged means it was
+// copied, so the whole session is revoked and neither the thief nor the
+// legitimate client can continue with it.
+func (s *AuthService) Refresh(refreshToken string) (*TokenPair, error) {
+	stored, err := s.sessionRepo.GetRefreshToken(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if !stored.Session.Active() {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		return nil, s.revokeReused(&stored.Session)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	next, nextRow, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Rotate(stored, nextRow); err != nil {
+		if errors.Is(err, repository.ErrTokenReused) {
+			return nil, s.revokeReused(&stored.Session)
+		}
+		return nil, err
+	}
+	stored.Session.ExpiresAt = nextRow.ExpiresAt
+	return s.tokenPair(&stored.Session, next)
+}
+
+// Logout revokes the session, invalidating its refresh token and all access
+// tokens issued for it.
+func (s *AuthService) Logout(userID, sessionID uint) error {
+	if err := s.sessionRepo.Revoke(sessionID); err != nil {
+		return err
+	}
+	s.sessions.remove(userID, sessionID)
+	return nil
+}
+
+// Sessions returns the active sessions of the user.
+func (s *AuthService) Sessions(userID uint) ([]models.Session, error) {
+	return s.sessionRepo.GetByUserID(userID)
+}
+
+// RevokeSession ends one of the user's sessions, for example that of a lost
+// phone.
+func (s *AuthService) RevokeSession(userID, sessionID uint) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if session.UserID != userID || !session.Active() {
+		return ErrNotFound
+	}
+
+	if err := s.sessionRepo.Revoke(sessionID); err != nil {
+		return err
+	}
+	s.sessions.remove(userID, sessionID)
+	return nil
+}
+
+// RevokeOtherSessions ends all sessions of the user except the current one.
+func (s *AuthService) RevokeOtherSessions(userID, currentSessionID uint) error {
+	if err := s.sessionRepo.RevokeOthers(userID, currentSessionID); err != nil {
+		return err
+	}
+	s.sessions.removeUser(userID, currentSessionID)
+	return nil
+}
+
+// PruneSessions deletes the sessions that expired or were revoked, and
+// returns how many there were.
+func (s *AuthService) PruneSessions() (int64, error) {
+	return s.sessionRepo.DeleteExpired(time.Now())
+}
+
+// ChangePassword sets a new password after checking the current one. All
+// sessions except the current one are revoked, so a device that knew the old
+// password is logged out.
+func (s *AuthService) ChangePassword(userID, currentSessionID uint, currentPassword, newPassword string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return ErrWrongPassword
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hashedPassword)
+	if err := s.userRepo.Update(user, "password_hash"); err != nil {
+		return err
+	}
+	return s.RevokeOtherSessions(userID, currentSessionID)
+}
+
+// RequestPasswordReset mails a reset link to the user with the given email
+// address if it is verified. Other addresses are ignored without an error, so
+// the response does not reveal who has an account.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(normalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.EmailVerified() {
+		return nil
+	}
+
+	token, hash, err := generateToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.cfg.Auth.PasswordResetLifetime)
+	if err := s.resetRepo.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	link := s.cfg.Server.PublicURL + "/reset-password?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      *user.Email,
+		Subject: "Reset your Wishlist password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your Wishlist account %q.\n\n"+
+			"To choose a new password, open this link before %s:\n\n%s\n\n"+
+			"If this wasn't you, ignore this email. Your password stays the same.\n",
+			user.Login, expiresAt.UTC().Format("2 Jan 2006 15:04 MST"), link),
+	})
+}
+
+// ResetPassword sets a new password with a token from a reset email. The
+// token works once and logs the user out everywhere.
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	stored, err := s.resetRepo.GetByHash(hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return ErrInvalidResetToken
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.cfg.Auth.JWTSecret))
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.resetRepo.Consume(stored, string(hashedPassword)); err != nil {
+		if errors.Is(err, repository.ErrResetTokenUsed) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	s.sessions.removeUser(stored.UserID, 0)
+	return nil
+}
+
+// Authenticate validates an access token and checks that its session is
+// still active. Sessions checked within the cache TTL are trusted without
+// asking the database, which also limits how often their last-seen time is
+// written.
+func (s *AuthService) Authenticate(tokenString string) (*Claims, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if userID, ok := s.sessions.get(claims.SessionID); ok && userID == claims.UserID {
+		return claims, nil
+	}
+
+	checkedAt := time.Now()
+	session, err := s.sessionRepo.GetByID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionRevoked
+		}
+		return nil, err
+	}
+	if !session.Active() || session.UserID != claims.UserID {
+		return nil, ErrSessionRevoked
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) >= s.cfg.Auth.SessionCacheTTL {
+		if err := s.sessionRepo.Touch(session.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	s.sessions.put(session.ID, session.UserID, session.ExpiresAt, checkedAt)
+	return claims, nil
 }
 
+// ValidateToken checks the signature and expiry of an access token. Tokens
+// issued before sessions existed carry no session and are rejected.
 func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte(s.cfg.Auth.JWTSecret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
 	}
 
-	if !token.Valid {
+	if !token.Valid || claims.SessionID == 0 {
 		return nil, errors.New("invalid token")
 	}
 
 	return claims, nil
 }
+
+func (s *AuthService) tokenPair(session *models.Session, refreshToken string) (*TokenPair, error) {
+	claims := &Claims{
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.cfg.Auth.AccessTokenLifetime)),
+		},
+	}
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.cfg.Auth.JWTSecret))
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    s.cfg.Auth.AccessTokenLifetime,
+	}, nil
+}
+
+func (s *AuthService) newRefreshToken() (string, *models.RefreshToken, error) {
+	token, hash, err := generateToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &models.RefreshToken{
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.cfg.Auth.RefreshTokenLifetime),
+	}, nil
+}
+
+func (s *AuthService) revokeReused(session *models.Session) error {
+	if err := s.sessionRepo.Revoke(session.ID); err != nil {
+		return err
+	}
+	s.sessions.remove(session.UserID, session.ID)
+	return ErrRefreshTokenReused
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// truncate shortens s to at most max bytes without splitting a character.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
+}
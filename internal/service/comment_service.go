@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/pagination"
+)
+
+type CommentService struct {
+	commentRepo  repository.CommentRepositoryInterface
+	wishRepo     repository.WishRepositoryInterface
+	accessPolicy *AccessPolicy
+}
+
+func NewCommentService(commentRepo repository.CommentRepositoryInterface, wishRepo repository.WishRepositoryInterface, accessPolicy *AccessPolicy) *CommentService {
+	return &CommentService{
+		commentRepo:  commentRepo,
+		wishRepo:     wishRepo,
+		accessPolicy: accessPolicy,
+	}
+}
+
+// Create adds a comment to the thread of a wish the user can see but does
+// not own.
+func (s *CommentService) Create(userID, wishID uint, body string) (*models.WishComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrInvalidComment
+	}
+	if _, err := s.thread(userID, wishID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.WishComment{
+		WishID: wishID,
+		UserID: userID,
+		Body:   body,
+	}
+	if err := s.commentRepo.Create(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetByWishID returns a page of the wish's thread, oldest first, together
+// with the cursor of the next page, if any.
+func (s *CommentService) GetByWishID(userID, wishID uint, cursor string, limit int) ([]models.WishComment, string, error) {
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, "", ErrInvalidCursor
+	}
+	limit = pagination.Limit(limit)
+
+	if _, err := s.thread(userID, wishID); err != nil {
+		return nil, "", err
+	}
+
+	comments, err := s.commentRepo.GetByWishID(wishID, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(comments) > limit {
+		comments = comments[:limit]
+		next = pagination.Encode(pagination.Cursor{ID: comments[limit-1].ID})
+	}
+	return comments, next, nil
+}
+
+// Update changes the text of one of the user's own comments.
+func (s *CommentService) Update(userID, commentID uint, body string) (*models.WishComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrInvalidComment
+	}
+
+	comment, _, err := s.get(userID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, ErrForbidden
+	}
+
+	now := time.Now()
+	comment.Body = body
+	comment.EditedAt = &now
+	if err := s.commentRepo.Update(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Delete removes a comment. Besides its author, the co-organizer of the list
+// the wish is on may delete it.
+func (s *CommentService) Delete(userID, commentID uint) error {
+	comment, wish, err := s.get(userID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != userID && !isCoOrganizer(userID, wish) {
+		return ErrForbidden
+	}
+	return s.commentRepo.Delete(commentID)
+}
+
+// get returns a comment together with its wish if the user may see the
+// thread it belongs to.
+func (s *CommentService) get(userID, commentID uint) (*models.WishComment, *models.Wish, error) {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	wish, err := s.thread(userID, comment.WishID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return comment, wish, nil
+}
+
+// thread returns the wish if the user may read and write its comments. To
+// the owner, the thread does not exist at all, so they cannot even learn
+// whether anyone is talking about their wish.
+func (s *CommentService) thread(userID, wishID uint) (*models.Wish, error) {
+	wish, err := s.wishRepo.GetByID(wishID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if wish.UserID == userID {
+		return nil, ErrNotFound
+	}
+
+	visible, err := s.accessPolicy.CanSeeWish(userID, wish)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrNotFound
+	}
+	return wish, nil
+}
+
+func isCoOrganizer(userID uint, wish *models.Wish) bool {
+	return wish.Wishlist != nil && wish.Wishlist.CoOrganizerID != nil && *wish.Wishlist.CoOrganizerID == userID
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/money"
+)
+
+type CurrencyService struct {
+	rateRepo        repository.ExchangeRateRepositoryInterface
+	userRepo        repository.UserRepositoryInterface
+	wishRepo        repository.WishRepositoryInterface
+	accessPolicy    *AccessPolicy
+	defaultCurrency string
+}
+
+func NewCurrencyService(rateRepo repository.ExchangeRateRepositoryInterface, userRepo repository.UserRepositoryInterface, wishRepo repository.WishRepositoryInterface, accessPolicy *AccessPolicy, defaultCurrency string) *CurrencyService {
+	return &CurrencyService{
+		rateRepo:        rateRepo,
+		userRepo:        userRepo,
+		wishRepo:        wishRepo,
+		accessPolicy:    accessPolicy,
+		defaultCurrency: defaultCurrency,
+	}
+}
+
+// LoadRates replaces all exchange rates with the ones read from a CSV file.
+// Only admins may load rates.
+func (s *CurrencyService) LoadRates(userID uint, file io.Reader) ([]models.ExchangeRate, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsAdmin {
+		return nil, ErrForbidden
+	}
+
+	parsed, err := money.ParseRates(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRates, err)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("%w: no rates found", ErrInvalidRates)
+	}
+
+	rates := make([]models.ExchangeRate, 0, len(parsed))
+	for currency, rate := range parsed {
+		rates = append(rates, models.ExchangeRate{Currency: currency, Rate: rate})
+	}
+	if err := s.rateRepo.ReplaceAll(rates); err != nil {
+		return nil, err
+	}
+	return s.rateRepo.GetAll()
+}
+
+func (s *CurrencyService) GetRates() ([]models.ExchangeRate, error) {
+	return s.rateRepo.GetAll()
+}
+
+// SetPreferredCurrency sets the currency totals are shown in for the user.
+// An empty currency falls back to the server default.
+func (s *CurrencyService) SetPreferredCurrency(userID uint, currency string) error {
+	currency = strings.ToUpper(currency)
+	if currency != "" && !money.ValidCurrency(currency) {
+		return ErrInvalidCurrency
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	user.Currency = currency
+	return s.userRepo.Update(user, "currency")
+}
+
+// Total adds up the price ranges of the items the user still wishes for, as
+// far as the viewer can see them, in the given currency. Without one, the viewer's
+// preferred currency or the server default is used.
+func (s *CurrencyService) Total(viewerID uint, username, currency string) (*models.WishTotal, error) {
+	currency, err := s.totalCurrency(viewerID, strings.ToUpper(currency))
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.userRepo.FindByLogin(username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	access, err := s.accessPolicy.Access(viewerID, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	wishes, _, err := s.wishRepo.GetByUsername(username, access.Visibilities(), models.WishFilter{}, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.rates()
+	if err != nil {
+		return nil, err
+	}
+
+	total := &models.WishTotal{Currency: currency}
+	var lower, upper int64
+	for _, wish := range wishes {
+		wanted := int64(max(wish.Quantity, 1) - wish.Received)
+		if wanted <= 0 {
+			continue
+		}
+		if wish.PriceUnknown {
+			total.Unknown++
+			continue
+		}
+		total.Wishes++
+		if wish.PriceMaxMinor == 0 {
+			continue
+		}
+
+		var bounds [2]int64
+		for i, price := range []int64{wish.PriceMinor, wish.PriceMaxMinor} {
+			if bounds[i], err = rates.Convert(price*wanted, wish.Currency, currency); err != nil {
+				missing := wish.Currency
+				if _, ok := rates[currency]; !ok {
+					missing = currency
+				}
+				return nil, fmt.Errorf("%w: %s", ErrNoExchangeRate, missing)
+			}
+		}
+		lower += bounds[0]
+		upper += bounds[1]
+	}
+	total.Min = money.Format(lower, currency)
+	total.Max = money.Format(upper, currency)
+	return total, nil
+}
+
+func (s *CurrencyService) totalCurrency(viewerID uint, currency string) (string, error) {
+	if currency != "" {
+		if !money.ValidCurrency(currency) {
+			return "", ErrInvalidCurrency
+		}
+		return currency, nil
+	}
+	if viewerID != 0 {
+		viewer, err := s.userRepo.GetByID(viewerID)
+		if err != nil {
+			return "", err
+		}
+		if viewer.Currency != "" {
+			return viewer.Currency, nil
+		}
+	}
+	return s.defaultCurrency, nil
+}
+
+func (s *CurrencyService) rates() (money.Rates, error) {
+	stored, err := s.rateRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(money.Rates, len(stored))
+	for _, rate := range stored {
+		value, err := money.ParseRate(rate.Rate)
+		if err != nil {
+			return nil, err
+		}
+		rates[rate.Currency] = value
+	}
+	return rates, nil
+}
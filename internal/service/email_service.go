@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/mail"
+)
+
+// emailVerificationAudience keeps verification links and access tokens, which
+// are signed with the same secret, from being used in place of each other.
+const emailVerificationAudience = "email-verification"
+
+type EmailService struct {
+	userRepo repository.UserRepositoryInterface
+	mailer   mail.Mailer
+	cfg      *config.Config
+}
+
+func NewEmailService(userRepo repository.UserRepositoryInterface, mailer mail.Mailer, cfg *config.Config) *EmailService {
+	return &EmailService{
+		userRepo: userRepo,
+		mailer:   mailer,
+		cfg:      cfg,
+	}
+}
+
+// verificationClaims name the address being verified, so a link stops
+// working once the user changes to a different one.
+type verificationClaims struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+func (s *EmailService) Status(userID uint) (*models.User, error) {
+	return s.userRepo.GetByID(userID)
+}
+
+// SendVerification mails a new verification link to the user's address.
+// Links are sent at most once per resend interval.
+func (s *EmailService) SendVerification(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == nil {
+		return ErrNoEmail
+	}
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	if s.throttled(user) {
+		return ErrVerificationThrottled
+	}
+	return s.sendVerification(ctx, user)
+}
+
+// ChangeEmail replaces the user's address after checking their password. The
+// new address is unverified until the link sent to it is opened.
+func (s *EmailService) ChangeEmail(ctx context.Context, userID uint, password, email string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	email = normalizeEmail(email)
+	if user.Email != nil && *user.Email == email {
+		return user, nil
+	}
+	if other, err := s.userRepo.FindByEmail(email); err == nil && other.ID != user.ID {
+		return nil, ErrEmailTaken
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if s.throttled(user) {
+		return nil, ErrVerificationThrottled
+	}
+
+	user.Email = &email
+	user.EmailVerifiedAt = nil
+	if err := s.userRepo.Update(user, "email", "email_verified_at"); err != nil {
+		// Another account took the address since the check above.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+	if err := s.sendVerification(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Verify marks the address named by a verification link as verified.
+// Opening a link again is harmless.
+func (s *EmailService) Verify(token string) error {
+	claims := &verificationClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(s.cfg.Auth.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !parsed.Valid || !claims.VerifyAudience(emailVerificationAudience, true) {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+	if user.Email == nil || *user.Email != claims.Email {
+		return ErrInvalidVerificationToken
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return s.userRepo.Update(user, "email_verified_at")
+}
+
+// VerifiedEmail returns the user's address if it is verified. Features that
+// email users, such as notifications, use it to require a verified address.
+func (s *EmailService) VerifiedEmail(userID uint) (string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if !user.EmailVerified() {
+		return "", ErrEmailNotVerified
+	}
+	return *user.Email, nil
+}
+
+// SendInitialVerification mails the first verification link to a user who
+// registered with an email address.
+func (s *EmailService) SendInitialVerification(ctx context.Context, user *models.User) error {
+	if user.Email == nil || user.EmailVerified() {
+		return nil
+	}
+	return s.sendVerification(ctx, user)
+}
+
+func (s *EmailService) throttled(user *models.User) bool {
+	return user.VerificationSentAt != nil &&
+		time.Since(*user.VerificationSentAt) < s.cfg.Auth.EmailVerificationResendInterval
+}
+
+func (s *EmailService) sendVerification(ctx context.Context, user *models.User) error {
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.Auth.EmailVerificationLifetime)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &verificationClaims{
+		UserID: user.ID,
+		Email:  *user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString([]byte(s.cfg.Auth.JWTSecret))
+	if err != nil {
+		return err
+	}
+
+	user.VerificationSentAt = &now
+	if err := s.userRepo.Update(user, "verification_sent_at"); err != nil {
+		return err
+	}
+
+	link := s.cfg.Server.PublicURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      *user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Please confirm that %s belongs to your Wishlist account %q by opening this link before %s:\n\n%s\n\n"+
+			"If you did not add this address, ignore this email.\n",
+			*user.Email, user.Login, expiresAt.UTC().Format("2 Jan 2006 15:04 MST"), link),
+	})
+}
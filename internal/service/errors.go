@@ -0,0 +1,77 @@
+package service
+
+import "errors"
+
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrForbidden          = errors.New("forbidden")
+	ErrAlreadyReserved    = errors.New("wish is already reserved")
+	ErrOwnWish            = errors.New("cannot reserve or fund your own wish")
+	ErrAlreadyClaimed     = errors.New("you have already claimed this wish")
+	ErrQuantityTooLarge   = errors.New("claim exceeds the remaining quantity")
+	ErrInvalidQuantity    = errors.New("received count cannot exceed the quantity")
+	ErrDefaultWishlist    = errors.New("default wishlist cannot be deleted")
+	ErrInvalidExpiry      = errors.New("expiry must be in the future")
+	ErrShareLinkRevoked   = errors.New("share link has been revoked")
+	ErrGroupGift          = errors.New("wish is funded as a group gift, pledge instead")
+	ErrAlreadyPledged     = errors.New("you have already pledged toward this wish")
+	ErrPledgeTooLarge     = errors.New("pledge exceeds the remaining price")
+	ErrNoPrice            = errors.New("wish has no price to fund")
+	ErrCurrencyLocked     = errors.New("currency cannot change while the wish has pledges")
+	ErrSelfFriendship     = errors.New("cannot send a friend request to yourself")
+	ErrFriendshipExists   = errors.New("friendship or request already exists")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrInvalidTimezone    = errors.New("invalid timezone")
+	ErrInvalidMove        = errors.New("a wish cannot be moved after itself")
+	ErrInvalidTransition  = errors.New("wish cannot change to this status")
+	ErrNotReceived        = errors.New("wish has not been received")
+	ErrWishNotActive      = errors.New("wish is no longer active")
+	ErrInvalidTag         = errors.New("tag name must not be empty")
+	ErrTagExists          = errors.New("tag already exists")
+	ErrInvalidCategory    = errors.New("unknown category")
+	ErrInvalidCurrency    = errors.New("unknown currency code")
+	ErrInvalidAmount      = errors.New("amount must be a positive decimal number in the currency's precision")
+	ErrInvalidPriceRange  = errors.New("minimum price must not exceed the maximum price")
+	ErrInvalidSort        = errors.New("unknown sort order")
+	ErrInvalidProductURL  = errors.New("product URL must be a public http or https address")
+	ErrProductUnavailable = errors.New("product page could not be read")
+	ErrInvalidFilter      = errors.New("invalid filter")
+	ErrInvalidSearch      = errors.New("search query required")
+	ErrImageTooLarge      = errors.New("image is too large")
+	ErrUnsupportedImage   = errors.New("image must be a JPEG, PNG or GIF file")
+	ErrCopyOwnWish        = errors.New("wish is already on your list")
+	ErrInvalidComment     = errors.New("comment must not be empty")
+	ErrSelfCoOrganizer    = errors.New("cannot make yourself co-organizer of your own list")
+	ErrInvalidRates       = errors.New("invalid exchange rate file")
+	ErrNoExchangeRate     = errors.New("no exchange rate for this currency")
+
+	ErrExchangeRevealed    = errors.New("gift exchange results are already revealed")
+	ErrExchangeNotDrawn    = errors.New("gift exchange has not been drawn yet")
+	ErrExchangeNotRevealed = errors.New("gift exchange results are not revealed yet")
+	ErrAlreadyParticipant  = errors.New("user is already a participant")
+	ErrOrganizerLeave      = errors.New("organizer cannot leave their own exchange")
+	ErrInvalidExclusion    = errors.New("exclusion must name two different participants")
+	ErrTooFewParticipants  = errors.New("at least two participants must join before the draw")
+	ErrNoValidAssignment   = errors.New("no assignment satisfies the exclusion rules")
+
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session has been revoked")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrWrongPassword       = errors.New("current password is incorrect")
+	ErrInvalidResetToken   = errors.New("invalid or expired password reset token")
+	ErrEmailTaken          = errors.New("email address is already in use")
+	ErrUserExists          = errors.New("user already exists")
+
+	ErrNoEmail                  = errors.New("no email address set")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+	ErrEmailNotVerified         = errors.New("a verified email address is required")
+	ErrVerificationThrottled    = errors.New("verification email was sent recently, try again later")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification link")
+
+	ErrTwoFactorEnabled         = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled     = errors.New("start two-factor enrollment first")
+	ErrInvalidTwoFactorCode     = errors.New("invalid authentication code")
+	ErrTooManyTwoFactorAttempts = errors.New("too many wrong authentication codes, try again later")
+	ErrInvalidChallenge         = errors.New("invalid or expired login challenge")
+)
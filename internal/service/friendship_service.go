@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/pagination"
+)
+
+type FriendshipService struct {
+	friendshipRepo repository.FriendshipRepositoryInterface
+	userRepo       repository.UserRepositoryInterface
+	wishRepo       repository.WishRepositoryInterface
+}
+
+func NewFriendshipService(friendshipRepo repository.FriendshipRepositoryInterface, userRepo repository.UserRepositoryInterface, wishRepo repository.WishRepositoryInterface) *FriendshipService {
+	return &FriendshipService{
+		friendshipRepo: friendshipRepo,
+		userRepo:       userRepo,
+		wishRepo:       wishRepo,
+	}
+}
+
+// SendRequest asks the user with the given login to become friends. If that
+// user has already asked the sender, their request is accepted instead.
+func (s *FriendshipService) SendRequest(userID uint, login string) (*models.Friendship, error) {
+	addressee, err := s.userRepo.FindByLogin(login)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if addressee.ID == userID {
+		return nil, ErrSelfFriendship
+	}
+
+	existing, err := s.friendshipRepo.GetBetween(userID, addressee.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Status == models.FriendshipPending && existing.AddresseeID == userID {
+			existing.Requester = *addressee
+			return s.accept(existing)
+		}
+		return nil, ErrFriendshipExists
+	}
+
+	friendship := &models.Friendship{
+		RequesterID: userID,
+		AddresseeID: addressee.ID,
+		Status:      models.FriendshipPending,
+	}
+	if err := s.friendshipRepo.Create(friendship); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrFriendshipExists
+		}
+		return nil, err
+	}
+
+	friendship.Addressee = *addressee
+	return friendship, nil
+}
+
+// Accept accepts a pending request addressed to the user.
+func (s *FriendshipService) Accept(userID, friendshipID uint) (*models.Friendship, error) {
+	friendship, err := s.friendshipRepo.GetByID(friendshipID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if friendship.AddresseeID != userID || friendship.Status != models.FriendshipPending {
+		return nil, ErrNotFound
+	}
+
+	return s.accept(friendship)
+}
+
+// Decline rejects a pending request addressed to the user, or withdraws one
+// the user has sent.
+func (s *FriendshipService) Decline(userID, friendshipID uint) error {
+	friendship, err := s.friendshipRepo.GetByID(friendshipID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if friendship.Status != models.FriendshipPending ||
+		(friendship.AddresseeID != userID && friendship.RequesterID != userID) {
+		return ErrNotFound
+	}
+
+	return s.friendshipRepo.Delete(friendship.ID)
+}
+
+// Remove ends an accepted friendship the user is part of.
+func (s *FriendshipService) Remove(userID, friendshipID uint) error {
+	friendship, err := s.friendshipRepo.GetByID(friendshipID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if friendship.Status != models.FriendshipAccepted ||
+		(friendship.AddresseeID != userID && friendship.RequesterID != userID) {
+		return ErrNotFound
+	}
+
+	return s.friendshipRepo.Delete(friendship.ID)
+}
+
+func (s *FriendshipService) GetFriends(userID uint) ([]models.Friendship, error) {
+	return s.friendshipRepo.GetFriends(userID)
+}
+
+func (s *FriendshipService) GetRequests(userID uint) ([]models.Friendship, []models.Friendship, error) {
+	incoming, err := s.friendshipRepo.GetIncoming(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	outgoing, err := s.friendshipRepo.GetOutgoing(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return incoming, outgoing, nil
+}
+
+// Feed returns a page of recently added or updated wishes from the user's
+// friends together with the cursor of the next page, if any.
+func (s *FriendshipService) Feed(userID uint, cursor string, limit int) ([]models.Wish, string, error) {
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, "", ErrInvalidCursor
+	}
+	limit = pagination.Limit(limit)
+
+	wishes, err := s.wishRepo.GetFeed(userID, after, limit+1)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return nil, "", ErrInvalidCursor
+		}
+		return nil, "", err
+	}
+
+	var next string
+	if len(wishes) > limit {
+		wishes = wishes[:limit]
+		last := wishes[limit-1]
+		next = pagination.Encode(pagination.Cursor{
+			Value: last.UpdatedAt.Format(time.RFC3339Nano),
+			ID:    last.ID,
+		})
+	}
+
+	return wishes, next, nil
+}
+
+func (s *FriendshipService) accept(friendship *models.Friendship) (*models.Friendship, error) {
+	now := time.Now()
+	friendship.Status = models.FriendshipAccepted
+	friendship.AcceptedAt = &now
+	if err := s.friendshipRepo.Update(friendship); err != nil {
+		return nil, err
+	}
+	return friendship, nil
+}
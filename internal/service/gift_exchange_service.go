@@ -0,0 +1,383 @@
+package service
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/secretsanta"
+)
+
+type GiftExchangeService struct {
+	exchangeRepo repository.GiftExchangeRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	wishRepo     repository.WishRepositoryInterface
+	accessPolicy *AccessPolicy
+}
+
+func NewGiftExchangeService(exchangeRepo repository.GiftExchangeRepositoryInterface, userRepo repository.UserRepositoryInterface, wishRepo repository.WishRepositoryInterface, accessPolicy *AccessPolicy) *GiftExchangeService {
+	return &GiftExchangeService{
+		exchangeRepo: exchangeRepo,
+		userRepo:     userRepo,
+		wishRepo:     wishRepo,
+		accessPolicy: accessPolicy,
+	}
+}
+
+// Create starts a new exchange with the organizer as its first participant.
+func (s *GiftExchangeService) Create(organizerID uint, exchange *models.GiftExchange) (*models.GiftExchange, error) {
+	exchange.OrganizerID = organizerID
+	exchange.Status = models.ExchangeOpen
+	exchange.Participants = []models.ExchangeParticipant{{
+		UserID: organizerID,
+		Status: models.ParticipantJoined,
+	}}
+	if err := s.exchangeRepo.Create(exchange); err != nil {
+		return nil, err
+	}
+	return s.exchangeRepo.GetByID(exchange.ID)
+}
+
+func (s *GiftExchangeService) Update(organizerID uint, exchange *models.GiftExchange) error {
+	existing, err := s.getOrganized(organizerID, exchange.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = exchange.Name
+	existing.BudgetMinor = exchange.BudgetMinor
+	existing.Currency = exchange.Currency
+	return s.exchangeRepo.Update(existing)
+}
+
+func (s *GiftExchangeService) Delete(organizerID, exchangeID uint) error {
+	if _, err := s.getOrganized(organizerID, exchangeID); err != nil {
+		return err
+	}
+	return s.exchangeRepo.Delete(exchangeID)
+}
+
+// GetByID returns the exchange if the user organizes or was invited to it.
+func (s *GiftExchangeService) GetByID(userID, exchangeID uint) (*models.GiftExchange, error) {
+	exchange, err := s.get(exchangeID)
+	if err != nil {
+		return nil, err
+	}
+	if exchange.OrganizerID != userID && exchange.Participant(userID) == nil {
+		return nil, ErrNotFound
+	}
+	return exchange, nil
+}
+
+func (s *GiftExchangeService) GetByUserID(userID uint) ([]models.GiftExchange, error) {
+	return s.exchangeRepo.GetByUserID(userID)
+}
+
+// Invite adds the user with the given login to the exchange. Invited users
+// take part in the draw only after they join.
+func (s *GiftExchangeService) Invite(organizerID, exchangeID uint, login string) error {
+	exchange, err := s.getOrganized(organizerID, exchangeID)
+	if err != nil {
+		return err
+	}
+	if exchange.Status == models.ExchangeRevealed {
+		return ErrExchangeRevealed
+	}
+
+	user, err := s.userRepo.FindByLogin(login)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if exchange.Participant(user.ID) != nil {
+		return ErrAlreadyParticipant
+	}
+
+	return s.exchangeRepo.AddParticipant(&models.ExchangeParticipant{
+		ExchangeID: exchangeID,
+		UserID:     user.ID,
+		Status:     models.ParticipantInvited,
+	})
+}
+
+// Join accepts an invitation. A draw made before the user joined no longer
+// covers everyone, so it is discarded.
+func (s *GiftExchangeService) Join(userID, exchangeID uint) error {
+	exchange, err := s.GetByID(userID, exchangeID)
+	if err != nil {
+		return err
+	}
+	if exchange.Status == models.ExchangeRevealed {
+		return ErrExchangeRevealed
+	}
+
+	participant := exchange.Participant(userID)
+	if participant == nil {
+		return ErrNotFound
+	}
+	if participant.Status == models.ParticipantJoined {
+		return nil
+	}
+
+	participant.Status = models.ParticipantJoined
+	if err := s.exchangeRepo.UpdateParticipant(participant); err != nil {
+		return err
+	}
+	return s.resetDraw(exchange)
+}
+
+// Leave declines an invitation or leaves the exchange before the results are
+// revealed.
+func (s *GiftExchangeService) Leave(userID, exchangeID uint) error {
+	exchange, err := s.GetByID(userID, exchangeID)
+	if err != nil {
+		return err
+	}
+	if exchange.OrganizerID == userID {
+		return ErrOrganizerLeave
+	}
+	return s.removeParticipant(exchange, userID)
+}
+
+func (s *GiftExchangeService) RemoveParticipant(organizerID, exchangeID, userID uint) error {
+	exchange, err := s.getOrganized(organizerID, exchangeID)
+	if err != nil {
+		return err
+	}
+	if userID == organizerID {
+		return ErrOrganizerLeave
+	}
+	return s.removeParticipant(exchange, userID)
+}
+
+// AddExclusion forbids the giver from drawing the recipient. Mutual
+// exclusions also forbid the reverse, e.g. for spouses. New rules may
+// invalidate the current draw, so it is discarded.
+func (s *GiftExchangeService) AddExclusion(organizerID, exchangeID, giverID, recipientID uint, mutual bool) ([]models.ExchangeExclusion, error) {
+	exchange, err := s.getOrganized(organizerID, exchangeID)
+	if err != nil {
+		return nil, err
+	}
+	if exchange.Status == models.ExchangeRevealed {
+		return nil, ErrExchangeRevealed
+	}
+	if giverID == recipientID || exchange.Participant(giverID) == nil || exchange.Participant(recipientID) == nil {
+		return nil, ErrInvalidExclusion
+	}
+
+	pairs := [][2]uint{{giverID, recipientID}}
+	if mutual {
+		pairs = append(pairs, [2]uint{recipientID, giverID})
+	}
+
+	var created []models.ExchangeExclusion
+	for _, pair := range pairs {
+		if excluded(exchange, pair[0], pair[1]) {
+			continue
+		}
+		exclusion := models.ExchangeExclusion{
+			ExchangeID:  exchangeID,
+			GiverID:     pair[0],
+			RecipientID: pair[1],
+		}
+		if err := s.exchangeRepo.AddExclusion(&exclusion); err != nil {
+			return nil, err
+		}
+		created = append(created, exclusion)
+	}
+
+	if len(created) > 0 {
+		if err := s.resetDraw(exchange); err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+func (s *GiftExchangeService) RemoveExclusion(organizerID, exchangeID, exclusionID uint) error {
+	exchange, err := s.getOrganized(organizerID, exchangeID)
+	if err != nil {
+		return err
+	}
+	if exchange.Status == models.ExchangeRevealed {
+		return ErrExchangeRevealed
+	}
+	if err := s.exchangeRepo.RemoveExclusion(exchangeID, exclusionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Draw assigns every joined participant a recipient that respects the
+// exclusion rules. The organizer may draw again until the results are
+// revealed; each draw replaces the previous one.
+func (s *GiftExchangeService) Draw(organizerID, exchangeID uint) error {
+	exchange, err := s.getOrganized(organizerID, exchangeID)
+	if err != nil {
+		return err
+	}
+	if exchange.Status == models.ExchangeRevealed {
+		return ErrExchangeRevealed
+	}
+
+	var joined []uint
+	for _, participant := range exchange.Participants {
+		if participant.Status == models.ParticipantJoined {
+			joined = append(joined, participant.UserID)
+		}
+	}
+	if len(joined) < 2 {
+		return ErrTooFewParticipants
+	}
+
+	rng, err := newDrawRand()
+	if err != nil {
+		return err
+	}
+	assignment, err := secretsanta.Draw(joined, func(giver, recipient uint) bool {
+		return excluded(exchange, giver, recipient)
+	}, rng)
+	if err != nil {
+		if errors.Is(err, secretsanta.ErrNoAssignment) {
+			return ErrNoValidAssignment
+		}
+		return err
+	}
+
+	for i := range exchange.Participants {
+		participant := &exchange.Participants[i]
+		participant.RecipientID = nil
+		if recipientID, ok := assignment[participant.UserID]; ok {
+			participant.RecipientID = &recipientID
+		}
+	}
+	now := time.Now()
+	exchange.Status = models.ExchangeDrawn
+	exchange.DrawnAt = &now
+	return s.exchangeRepo.SaveDraw(exchange)
+}
+
+// Reveal makes the current draw final and lets participants see their
+// recipients.
+func (s *GiftExchangeService) Reveal(organizerID, exchangeID uint) error {
+	exchange, err := s.getOrganized(organizerID, exchangeID)
+	if err != nil {
+		return err
+	}
+	switch exchange.Status {
+	case models.ExchangeRevealed:
+		return ErrExchangeRevealed
+	case models.ExchangeOpen:
+		return ErrExchangeNotDrawn
+	}
+
+	now := time.Now()
+	exchange.Status = models.ExchangeRevealed
+	exchange.RevealedAt = &now
+	return s.exchangeRepo.Update(exchange)
+}
+
+// Assignment returns the recipient drawn for the user together with the
+// recipient's wishes the user is allowed to see. Nobody, including the
+// organizer, can see anyone else's assignment.
+func (s *GiftExchangeService) Assignment(userID, exchangeID uint) (*models.GiftExchange, *models.User, []models.Wish, error) {
+	exchange, err := s.GetByID(userID, exchangeID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if exchange.Status != models.ExchangeRevealed {
+		return nil, nil, nil, ErrExchangeNotRevealed
+	}
+
+	participant := exchange.Participant(userID)
+	if participant == nil || participant.Recipient == nil {
+		return nil, nil, nil, ErrNotFound
+	}
+	recipient := participant.Recipient
+
+	access, err := s.accessPolicy.Access(userID, recipient.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	wishes, _, err := s.wishRepo.GetByUsername(recipient.Login, access.Visibilities(), models.WishFilter{}, nil, 0)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return exchange, recipient, wishes, nil
+}
+
+func (s *GiftExchangeService) removeParticipant(exchange *models.GiftExchange, userID uint) error {
+	if exchange.Status == models.ExchangeRevealed {
+		return ErrExchangeRevealed
+	}
+	participant := exchange.Participant(userID)
+	if participant == nil {
+		return ErrNotFound
+	}
+
+	if err := s.exchangeRepo.RemoveParticipant(exchange.ID, userID); err != nil {
+		return err
+	}
+	if participant.Status == models.ParticipantJoined {
+		return s.resetDraw(exchange)
+	}
+	return nil
+}
+
+func (s *GiftExchangeService) resetDraw(exchange *models.GiftExchange) error {
+	if exchange.Status != models.ExchangeDrawn {
+		return nil
+	}
+	return s.exchangeRepo.ResetDraw(exchange.ID)
+}
+
+func (s *GiftExchangeService) get(exchangeID uint) (*models.GiftExchange, error) {
+	exchange, err := s.exchangeRepo.GetByID(exchangeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return exchange, nil
+}
+
+func (s *GiftExchangeService) getOrganized(organizerID, exchangeID uint) (*models.GiftExchange, error) {
+	exchange, err := s.GetByID(organizerID, exchangeID)
+	if err != nil {
+		return nil, err
+	}
+	if exchange.OrganizerID != organizerID {
+		return nil, ErrForbidden
+	}
+	return exchange, nil
+}
+
+func excluded(exchange *models.GiftExchange, giverID, recipientID uint) bool {
+	for _, exclusion := range exchange.Exclusions {
+		if exclusion.GiverID == giverID && exclusion.RecipientID == recipientID {
+			return true
+		}
+	}
+	return false
+}
+
+// newDrawRand returns a generator seeded from crypto/rand so that draws
+// cannot be predicted.
+func newDrawRand() (*rand.Rand, error) {
+	var seed [32]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		return nil, err
+	}
+	return rand.New(rand.NewChaCha8(seed)), nil
+}
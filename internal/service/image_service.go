@@ -0,0 +1,230 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/imaging"
+	"wishlist-app/pkg/storage"
+)
+
+// ImageLimits bound what can be uploaded and set the thumbnail size.
+type ImageLimits struct {
+	MaxBytes      int64
+	MaxPixels     int
+	ThumbnailSize int
+	URLExpiry     time.Duration
+}
+
+type ImageService struct {
+	wishRepo repository.WishRepositoryInterface
+	storage  storage.Storage
+	limits   ImageLimits
+}
+
+func NewImageService(wishRepo repository.WishRepositoryInterface, store storage.Storage, limits ImageLimits) *ImageService {
+	return &ImageService{
+		wishRepo: wishRepo,
+		storage:  store,
+		limits:   limits,
+	}
+}
+
+// Upload stores an image and its thumbnail for the user's wish, replacing
+// any image uploaded before. The format is sniffed from the data itself.
+func (s *ImageService) Upload(ctx context.Context, userID, wishID uint, file io.Reader) (*models.Wish, error) {
+	wish, err := s.getOwned(userID, wishID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, s.limits.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.limits.MaxBytes {
+		return nil, ErrImageTooLarge
+	}
+	contentType, err := imaging.Sniff(data)
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	img, err := imaging.Decode(data, s.limits.MaxPixels)
+	switch {
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return nil, ErrImageTooLarge
+	case err != nil:
+		return nil, ErrUnsupportedImage
+	}
+	thumbnail, err := imaging.Thumbnail(img, s.limits.ThumbnailSize)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+	imageKey := fmt.Sprintf("wishes/%d/%s%s", wish.ID, name, imaging.Extensions[contentType])
+	thumbnailKey := fmt.Sprintf("wishes/%d/%s-thumb.jpg", wish.ID, name)
+
+	if err := s.storage.Put(ctx, imageKey, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+		s.deleteKeys(ctx, imageKey)
+		return nil, err
+	}
+
+	oldImage, oldThumbnail := wish.ImageKey, wish.ThumbnailKey
+	wish.ImageKey, wish.ThumbnailKey = imageKey, thumbnailKey
+	if err := s.wishRepo.Update(wish); err != nil {
+		s.deleteKeys(ctx, imageKey, thumbnailKey)
+		return nil, err
+	}
+	s.deleteKeys(ctx, oldImage, oldThumbnail)
+	return wish, nil
+}
+
+// Remove deletes the uploaded image of the user's wish.
+func (s *ImageService) Remove(ctx context.Context, userID, wishID uint) (*models.Wish, error) {
+	wish, err := s.getOwned(userID, wishID)
+	if err != nil {
+		return nil, err
+	}
+	if wish.ImageKey == "" {
+		return wish, nil
+	}
+
+	oldImage, oldThumbnail := wish.ImageKey, wish.ThumbnailKey
+	wish.ImageKey, wish.ThumbnailKey = "", ""
+	if err := s.wishRepo.Update(wish); err != nil {
+		return nil, err
+	}
+	return wish, s.deleteKeys(ctx, oldImage, oldThumbnail)
+}
+
+// DeleteImages removes the stored files of a wish that was deleted.
+func (s *ImageService) DeleteImages(ctx context.Context, wish *models.Wish) error {
+	return s.deleteKeys(ctx, wish.ImageKey, wish.ThumbnailKey)
+}
+
+// CopySourceImage gives a wish saved from another user's list its own copy of
+// the image uploaded to the original, so that either can be changed or
+// deleted without affecting the other.
+func (s *ImageService) CopySourceImage(ctx context.Context, wish *models.Wish) (*models.Wish, error) {
+	if wish.SourceWishID == nil {
+		return wish, nil
+	}
+	source, err := s.wishRepo.GetByID(*wish.SourceWishID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return wish, nil
+		}
+		return nil, err
+	}
+	if source.ImageKey == "" {
+		return wish, nil
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+	imageKey := fmt.Sprintf("wishes/%d/%s%s", wish.ID, name, path.Ext(source.ImageKey))
+	thumbnailKey := fmt.Sprintf("wishes/%d/%s-thumb.jpg", wish.ID, name)
+
+	if err := s.copyObject(ctx, source.ImageKey, imageKey); err != nil {
+		return nil, err
+	}
+	if err := s.copyObject(ctx, source.ThumbnailKey, thumbnailKey); err != nil {
+		s.deleteKeys(ctx, imageKey)
+		return nil, err
+	}
+
+	wish.ImageKey, wish.ThumbnailKey = imageKey, thumbnailKey
+	if err := s.wishRepo.Update(wish); err != nil {
+		s.deleteKeys(ctx, imageKey, thumbnailKey)
+		return nil, err
+	}
+	return wish, nil
+}
+
+func (s *ImageService) copyObject(ctx context.Context, from, to string) error {
+	object, err := s.storage.Open(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer object.Body.Close()
+	return s.storage.Put(ctx, to, object.Body, object.Size, object.ContentType)
+}
+
+// Open returns either a temporary URL to download the image from, when the
+// storage can presign one, or the image itself.
+func (s *ImageService) Open(ctx context.Context, key string) (string, *storage.Object, error) {
+	if !storage.ValidKey(key) {
+		return "", nil, ErrNotFound
+	}
+	if presigner, ok := s.storage.(storage.Presigner); ok {
+		url, err := presigner.PresignGet(key, s.limits.URLExpiry)
+		return url, nil, err
+	}
+
+	object, err := s.storage.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", nil, ErrNotFound
+		}
+		return "", nil, err
+	}
+	return "", object, nil
+}
+
+// deleteKeys deletes every non-empty key, returning the first error.
+func (s *ImageService) deleteKeys(ctx context.Context, keys ...string) error {
+	var first error
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.storage.Delete(ctx, key); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (s *ImageService) getOwned(userID, wishID uint) (*models.Wish, error) {
+	wish, err := s.wishRepo.GetByID(wishID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if wish.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return wish, nil
+}
+
+// randomName makes image keys unguessable, so that images of hidden wishes
+// cannot be found by enumerating keys.
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+)
+
+const (
+	DefaultUpcomingDays = 365
+	MaxUpcomingDays     = 366
+)
+
+type OccasionService struct {
+	occasionRepo repository.OccasionRepositoryInterface
+	wishRepo     repository.WishRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	accessPolicy *AccessPolicy
+}
+
+func NewOccasionService(occasionRepo repository.OccasionRepositoryInterface, wishRepo repository.WishRepositoryInterface, userRepo repository.UserRepositoryInterface, accessPolicy *AccessPolicy) *OccasionService {
+	return &OccasionService{
+		occasionRepo: occasionRepo,
+		wishRepo:     wishRepo,
+		userRepo:     userRepo,
+		accessPolicy: accessPolicy,
+	}
+}
+
+func (s *OccasionService) Create(userID uint, occasion *models.Occasion) (*models.Occasion, error) {
+	if err := validateTimezone(occasion); err != nil {
+		return nil, err
+	}
+
+	occasion.UserID = userID
+	if err := s.occasionRepo.Create(occasion); err != nil {
+		return nil, err
+	}
+	return occasion, nil
+}
+
+func (s *OccasionService) Update(userID uint, occasion *models.Occasion) error {
+	existing, err := s.getOwned(userID, occasion.ID)
+	if err != nil {
+		return err
+	}
+	if err := validateTimezone(occasion); err != nil {
+		return err
+	}
+
+	existing.Name = occasion.Name
+	existing.Date = occasion.Date
+	existing.Recurring = occasion.Recurring
+	existing.Timezone = occasion.Timezone
+	if occasion.Visibility != "" {
+		existing.Visibility = occasion.Visibility
+	}
+	return s.occasionRepo.Update(existing)
+}
+
+func (s *OccasionService) Delete(userID, occasionID uint) error {
+	if _, err := s.getOwned(userID, occasionID); err != nil {
+		return err
+	}
+	return s.occasionRepo.Delete(occasionID)
+}
+
+func (s *OccasionService) GetByUserID(userID uint) ([]models.Occasion, error) {
+	return s.occasionRepo.GetByUserID(userID, models.AccessOwner.Visibilities())
+}
+
+// Attach links the user's wishes and lists to one of their occasions.
+func (s *OccasionService) Attach(userID, occasionID uint, wishIDs, wishlistIDs []uint) error {
+	if _, err := s.getOwned(userID, occasionID); err != nil {
+		return err
+	}
+	return s.occasionRepo.Attach(occasionID, userID, wishIDs, wishlistIDs)
+}
+
+func (s *OccasionService) Detach(userID, occasionID uint, wishIDs, wishlistIDs []uint) error {
+	if _, err := s.getOwned(userID, occasionID); err != nil {
+		return err
+	}
+	return s.occasionRepo.Detach(occasionID, userID, wishIDs, wishlistIDs)
+}
+
+// Upcoming returns the user's occasions visible to the viewer that happen
+// within the given number of days, soonest first, each with the related wishes
+// the viewer can see. Other viewers only get wishes that are still available;
+// the owner gets all of them so reservations are not revealed.
+func (s *OccasionService) Upcoming(viewerID uint, username string, days int, now time.Time) ([]models.Occasion, error) {
+	if days <= 0 {
+		days = DefaultUpcomingDays
+	}
+	if days > MaxUpcomingDays {
+		days = MaxUpcomingDays
+	}
+
+	owner, err := s.userRepo.FindByLogin(username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	access, err := s.accessPolicy.Access(viewerID, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	occasions, err := s.occasionRepo.GetByUserID(owner.ID, access.Visibilities())
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming := make([]models.Occasion, 0, len(occasions))
+	for _, occasion := range occasions {
+		if until, ok := occasion.DaysUntil(now); ok && until <= days {
+			upcoming = append(upcoming, occasion)
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		a, _ := upcoming[i].DaysUntil(now)
+		b, _ := upcoming[j].DaysUntil(now)
+		return a < b
+	})
+	if len(upcoming) == 0 {
+		return upcoming, nil
+	}
+
+	ids := make([]uint, len(upcoming))
+	index := make(map[uint]int, len(upcoming))
+	for i, occasion := range upcoming {
+		ids[i] = occasion.ID
+		index[occasion.ID] = i
+	}
+
+	wishes, err := s.wishRepo.GetByOccasionIDs(ids, access.Visibilities())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wish := range wishes {
+		if access != models.AccessOwner && wish.Reserved() {
+			continue
+		}
+		attached := map[uint]bool{}
+		if wish.OccasionID != nil {
+			attached[*wish.OccasionID] = true
+		}
+		if wish.Wishlist != nil && wish.Wishlist.OccasionID != nil {
+			attached[*wish.Wishlist.OccasionID] = true
+		}
+		for occasionID := range attached {
+			if i, ok := index[occasionID]; ok {
+				upcoming[i].Wishes = append(upcoming[i].Wishes, wish)
+			}
+		}
+	}
+
+	return upcoming, nil
+}
+
+func (s *OccasionService) getOwned(userID, occasionID uint) (*models.Occasion, error) {
+	occasion, err := s.occasionRepo.GetByID(occasionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if occasion.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return occasion, nil
+}
+
+func validateTimezone(occasion *models.Occasion) error {
+	if occasion.Timezone == "" {
+		occasion.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(occasion.Timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/money"
+)
+
+type PledgeService struct {
+	pledgeRepo   repository.PledgeRepositoryInterface
+	wishRepo     repository.WishRepositoryInterface
+	accessPolicy *AccessPolicy
+}
+
+func NewPledgeService(pledgeRepo repository.PledgeRepositoryInterface, wishRepo repository.WishRepositoryInterface, accessPolicy *AccessPolicy) *PledgeService {
+	return &PledgeService{
+		pledgeRepo:   pledgeRepo,
+		wishRepo:     wishRepo,
+		accessPolicy: accessPolicy,
+	}
+}
+
+// Pledge commits amount, a decimal string in the wish's currency, toward a
+// wish the user can see but does not own.
+func (s *PledgeService) Pledge(userID, wishID uint, amount string) (*models.Pledge, error) {
+	wish, err := s.wishRepo.GetByID(wishID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	visible, err := s.accessPolicy.CanSeeWish(userID, wish)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrNotFound
+	}
+	if wish.UserID == userID {
+		return nil, ErrOwnWish
+	}
+	if !wish.Active() {
+		return nil, ErrWishNotActive
+	}
+	if wish.PriceUnknown || wish.PriceMaxMinor <= 0 {
+		return nil, ErrNoPrice
+	}
+	amountMinor, err := parsePledgeAmount(amount, wish.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	pledge := &models.Pledge{
+		WishID:      wishID,
+		UserID:      userID,
+		AmountMinor: amountMinor,
+	}
+	if err := s.pledgeRepo.Create(pledge); err != nil {
+		return nil, translatePledgeError(err)
+	}
+
+	wish.Pledges = append(wish.Pledges, *pledge)
+	pledge.Wish = *wish
+	return pledge, nil
+}
+
+func (s *PledgeService) Update(userID, pledgeID uint, amount string) error {
+	pledge, err := s.getOwned(userID, pledgeID)
+	if err != nil {
+		return err
+	}
+	wish, err := s.wishRepo.GetByID(pledge.WishID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if pledge.AmountMinor, err = parsePledgeAmount(amount, wish.Currency); err != nil {
+		return err
+	}
+	if err := s.pledgeRepo.Update(pledge); err != nil {
+		return translatePledgeError(err)
+	}
+	return nil
+}
+
+func (s *PledgeService) Delete(userID, pledgeID uint) error {
+	if _, err := s.getOwned(userID, pledgeID); err != nil {
+		return err
+	}
+	return s.pledgeRepo.Delete(pledgeID)
+}
+
+// GetByUserID returns the user's pledges toward wishes they can still see.
+func (s *PledgeService) GetByUserID(userID uint) ([]models.Pledge, error) {
+	pledges, err := s.pledgeRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := pledges[:0]
+	for _, pledge := range pledges {
+		ok, err := s.accessPolicy.CanSeeWish(userID, &pledge.Wish)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			visible = append(visible, pledge)
+		}
+	}
+	return visible, nil
+}
+
+func (s *PledgeService) getOwned(userID, pledgeID uint) (*models.Pledge, error) {
+	pledge, err := s.pledgeRepo.GetByID(pledgeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if pledge.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return pledge, nil
+}
+
+func parsePledgeAmount(amount, currency string) (int64, error) {
+	amountMinor, err := money.Parse(amount, currency)
+	if err != nil || amountMinor <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	return amountMinor, nil
+}
+
+func translatePledgeError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrAlreadyReserved):
+		return ErrAlreadyReserved
+	case errors.Is(err, repository.ErrAlreadyPledged):
+		return ErrAlreadyPledged
+	case errors.Is(err, repository.ErrPledgeExceedsPrice):
+		return ErrPledgeTooLarge
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/pkg/money"
+	"wishlist-app/pkg/product"
+)
+
+type ProductService struct {
+	fetcher product.Fetcher
+}
+
+func NewProductService(fetcher product.Fetcher) *ProductService {
+	return &ProductService{fetcher: fetcher}
+}
+
+// Preview fetches a product page and extracts its title, image and price.
+func (s *ProductService) Preview(ctx context.Context, rawURL string) (*product.Metadata, error) {
+	page, err := s.fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		if errors.Is(err, product.ErrInvalidURL) || errors.Is(err, product.ErrForbiddenAddress) {
+			return nil, ErrInvalidProductURL
+		}
+		return nil, ErrProductUnavailable
+	}
+	return product.Extract(page), nil
+}
+
+// Prefill fills the title and image the wish was created without, and its
+// price if unknown, from the wish's product page. Failing to read the page is
+// only an error when the wish has no title either.
+func (s *ProductService) Prefill(ctx context.Context, wish *models.Wish) error {
+	if wish.ProductURL == "" || (wish.Title != "" && wish.ImageURL != "" && !wish.PriceUnknown) {
+		return nil
+	}
+
+	meta, err := s.Preview(ctx, wish.ProductURL)
+	if err != nil {
+		if wish.Title != "" {
+			return nil
+		}
+		return err
+	}
+
+	if wish.Title == "" {
+		if meta.Title == "" {
+			return ErrProductUnavailable
+		}
+		wish.Title = meta.Title
+	}
+	if wish.ImageURL == "" {
+		wish.ImageURL = meta.ImageURL
+	}
+	if wish.PriceUnknown && money.ValidCurrency(meta.Currency) {
+		setScrapedPrice(wish, meta)
+	}
+	return nil
+}
+
+// setScrapedPrice sets the price of the wish from page metadata, leaving it
+// unknown if the amounts do not fit the currency.
+func setScrapedPrice(wish *models.Wish, meta *product.Metadata) {
+	lower, upper := meta.Price, meta.Price
+	if lower == "" {
+		lower, upper = meta.PriceMin, meta.PriceMax
+	}
+
+	priceMin, err := money.Parse(lower, meta.Currency)
+	if err != nil {
+		return
+	}
+	priceMax, err := money.Parse(upper, meta.Currency)
+	if err != nil || priceMax < priceMin {
+		return
+	}
+	wish.PriceMinor, wish.PriceMaxMinor = priceMin, priceMax
+	wish.Currency = meta.Currency
+	wish.PriceUnknown = false
+}
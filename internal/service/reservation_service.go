@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+)
+
+type ReservationService struct {
+	reservationRepo repository.ReservationRepositoryInterface
+	wishRepo        repository.WishRepositoryInterface
+	accessPolicy    *AccessPolicy
+}
+
+func NewReservationService(reservationRepo repository.ReservationRepositoryInterface, wishRepo repository.WishRepositoryInterface, accessPolicy *AccessPolicy) *ReservationService {
+	return &ReservationService{
+		reservationRepo: reservationRepo,
+		wishRepo:        wishRepo,
+		accessPolicy:    accessPolicy,
+	}
+}
+
+// Reserve claims the given quantity of another user's wish.
+func (s *ReservationService) Reserve(userID, wishID uint, quantity int) (*models.Reservation, error) {
+	if quantity < 1 {
+		quantity = 1
+	}
+
+	wish, err := s.wishRepo.GetByID(wishID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	visible, err := s.accessPolicy.CanSeeWish(userID, wish)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrNotFound
+	}
+
+	if wish.UserID == userID {
+		return nil, ErrOwnWish
+	}
+	if !wish.Active() {
+		return nil, ErrWishNotActive
+	}
+
+	reservation := &models.Reservation{
+		WishID:   wishID,
+		UserID:   userID,
+		Quantity: quantity,
+	}
+	if err := s.reservationRepo.Create(reservation); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAlreadyReserved):
+			return nil, ErrAlreadyReserved
+		case errors.Is(err, repository.ErrAlreadyClaimed):
+			return nil, ErrAlreadyClaimed
+		case errors.Is(err, repository.ErrQuantityExceeded):
+			return nil, ErrQuantityTooLarge
+		case errors.Is(err, repository.ErrHasPledges):
+			return nil, ErrGroupGift
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	wish.Reservations = append(wish.Reservations, *reservation)
+	reservation.Wish = *wish
+	return reservation, nil
+}
+
+func (s *ReservationService) Unreserve(userID, wishID uint) error {
+	deleted, err := s.reservationRepo.Delete(wishID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetByUserID returns the user's reservations of wishes they can still see.
+func (s *ReservationService) GetByUserID(userID uint) ([]models.Reservation, error) {
+	reservations, err := s.reservationRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := reservations[:0]
+	for _, reservation := range reservations {
+		ok, err := s.accessPolicy.CanSeeWish(userID, &reservation.Wish)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			visible = append(visible, reservation)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// sessionCache remembers for a short time which sessions are active, so that
+// authenticating a request does not cost a database round-trip. Revocations
+// made through this instance take effect immediately; revocations made by
+// other instances of the server are noticed once the entry expires. A zero
+// TTL disables the cache.
+//
+// Revocations are written to the database before they reach the cache, but a
+// request may have read the session just before that. The cache therefore
+// remembers when each user's sessions were last revoked and refuses entries
+// read before then, so such a request cannot bring a revoked session back.
+type sessionCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[uint]sessionCacheEntry
+	revoked   map[uint]time.Time
+	lastSweep time.Time
+}
+
+type sessionCacheEntry struct {
+	userID    uint
+	checkedAt time.Time
+	expiresAt time.Time
+}
+
+func newSessionCache(ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		ttl:     ttl,
+		entries: make(map[uint]sessionCacheEntry),
+		revoked: make(map[uint]time.Time),
+	}
+}
+
+// get returns the user of an active session if it was checked recently and
+// has not expired since.
+func (c *sessionCache) get(sessionID uint) (uint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[sessionID]
+	if !ok || time.Since(entry.checkedAt) >= c.ttl || !time.Now().Before(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.userID, true
+}
+
+// put remembers a session that was read from the database at checkedAt. It
+// is ignored if a session of the user was revoked since.
+func (c *sessionCache) put(sessionID, userID uint, expiresAt, checkedAt time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for id, entry := range c.entries {
+			if now.Sub(entry.checkedAt) >= c.ttl {
+				delete(c.entries, id)
+			}
+		}
+		// Reads older than the TTL are refused anyway, so older
+		// revocations no longer need to be remembered.
+		for id, revokedAt := range c.revoked {
+			if now.Sub(revokedAt) >= c.ttl {
+				delete(c.revoked, id)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if now.Sub(checkedAt) >= c.ttl {
+		return
+	}
+	if revokedAt, ok := c.revoked[userID]; ok && !checkedAt.After(revokedAt) {
+		return
+	}
+	c.entries[sessionID] = sessionCacheEntry{userID: userID, checkedAt: checkedAt, expiresAt: expiresAt}
+}
+
+// remove forgets a revoked session of the user.
+func (c *sessionCache) remove(userID, sessionID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, sessionID)
+	c.revoked[userID] = time.Now()
+}
+
+// removeUser forgets all sessions of the user except keepID.
+func (c *sessionCache) removeUser(userID, keepID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, entry := range c.entries {
+		if entry.userID == userID && id != keepID {
+			delete(c.entries, id)
+		}
+	}
+	c.revoked[userID] = time.Now()
+}
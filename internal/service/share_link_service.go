@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+)
+
+type ShareLinkService struct {
+	shareLinkRepo repository.ShareLinkRepositoryInterface
+	wishlistRepo  repository.WishlistRepositoryInterface
+	wishRepo      repository.WishRepositoryInterface
+	accessPolicy  *AccessPolicy
+}
+
+func NewShareLinkService(shareLinkRepo repository.ShareLinkRepositoryInterface, wishlistRepo repository.WishlistRepositoryInterface, wishRepo repository.WishRepositoryInterface, accessPolicy *AccessPolicy) *ShareLinkService {
+	return &ShareLinkService{
+		shareLinkRepo: shareLinkRepo,
+		wishlistRepo:  wishlistRepo,
+		wishRepo:      wishRepo,
+		accessPolicy:  accessPolicy,
+	}
+}
+
+// Create issues a new share link for the user's list and returns it together
+// with the plain token.
+func (s *ShareLinkService) Create(userID, wishlistID uint, expiresAt *time.Time) (*models.ShareLink, string, error) {
+	if err := s.checkWishlistOwner(userID, wishlistID); err != nil {
+		return nil, "", err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidExpiry
+	}
+
+	token, tokenHash, err := generateToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	link := &models.ShareLink{
+		UserID:     userID,
+		WishlistID: wishlistID,
+		TokenHash:  tokenHash,
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.shareLinkRepo.Create(link); err != nil {
+		return nil, "", err
+	}
+
+	return link, token, nil
+}
+
+func (s *ShareLinkService) GetByWishlistID(userID, wishlistID uint) ([]models.ShareLink, error) {
+	if err := s.checkWishlistOwner(userID, wishlistID); err != nil {
+		return nil, err
+	}
+	return s.shareLinkRepo.GetByWishlistID(wishlistID)
+}
+
+// Revoke disables the link permanently.
+func (s *ShareLinkService) Revoke(userID, linkID uint) error {
+	link, err := s.getOwned(userID, linkID)
+	if err != nil {
+		return err
+	}
+
+	if link.RevokedAt == nil {
+		now := time.Now()
+		link.RevokedAt = &now
+	}
+	return s.shareLinkRepo.Update(link)
+}
+
+// Rotate replaces the link's token, invalidating the old one. A nil expiresAt
+// keeps the current expiry. Revoked links stay revoked.
+func (s *ShareLinkService) Rotate(userID, linkID uint, expiresAt *time.Time) (*models.ShareLink, string, error) {
+	link, err := s.getOwned(userID, linkID)
+	if err != nil {
+		return nil, "", err
+	}
+	if link.RevokedAt != nil {
+		return nil, "", ErrShareLinkRevoked
+	}
+	if expiresAt != nil {
+		if !expiresAt.After(time.Now()) {
+			return nil, "", ErrInvalidExpiry
+		}
+		link.ExpiresAt = expiresAt
+	}
+
+	token, tokenHash, err := generateToken()
+	if err != nil {
+		return nil, "", err
+	}
+	link.TokenHash = tokenHash
+
+	if err := s.shareLinkRepo.Update(link); err != nil {
+		return nil, "", err
+	}
+
+	return link, token, nil
+}
+
+// Resolve returns the shared list with the wishes the token grants access to.
+// Unknown, expired and revoked tokens are all reported as not found.
+func (s *ShareLinkService) Resolve(viewerID uint, token string) (*models.Wishlist, error) {
+	link, err := s.shareLinkRepo.GetByTokenHash(hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !link.Active(time.Now()) {
+		return nil, ErrNotFound
+	}
+
+	wishlist, err := s.wishlistRepo.GetByID(link.WishlistID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	access, err := s.accessPolicy.Access(viewerID, wishlist.UserID)
+	if err != nil {
+		return nil, err
+	}
+	access = access.With(models.AccessLink)
+	if !access.CanSee(wishlist.Visibility) {
+		return nil, ErrNotFound
+	}
+
+	wishes, err := s.wishRepo.GetByWishlistID(wishlist.ID, access.Visibilities())
+	if err != nil {
+		return nil, err
+	}
+	wishlist.Wishes = wishes
+
+	return wishlist, nil
+}
+
+func (s *ShareLinkService) checkWishlistOwner(userID, wishlistID uint) error {
+	wishlist, err := s.wishlistRepo.GetByID(wishlistID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if wishlist.UserID != userID {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *ShareLinkService) getOwned(userID, linkID uint) (*models.ShareLink, error) {
+	link, err := s.shareLinkRepo.GetByID(linkID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if link.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return link, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+)
+
+type TagService struct {
+	tagRepo      repository.TagRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	accessPolicy *AccessPolicy
+}
+
+func NewTagService(tagRepo repository.TagRepositoryInterface, userRepo repository.UserRepositoryInterface, accessPolicy *AccessPolicy) *TagService {
+	return &TagService{
+		tagRepo:      tagRepo,
+		userRepo:     userRepo,
+		accessPolicy: accessPolicy,
+	}
+}
+
+func (s *TagService) Create(userID uint, name string) (*models.Tag, error) {
+	tag := &models.Tag{UserID: userID, Name: models.NormalizeTagName(name)}
+	if tag.Name == "" {
+		return nil, ErrInvalidTag
+	}
+	if err := s.tagRepo.Create(tag); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrTagExists
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *TagService) Rename(userID, tagID uint, name string) (*models.Tag, error) {
+	tag, err := s.getOwned(userID, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	tag.Name = models.NormalizeTagName(name)
+	if tag.Name == "" {
+		return nil, ErrInvalidTag
+	}
+	if err := s.tagRepo.Update(tag); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrTagExists
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+// Delete removes the tag from all of the user's wishes.
+func (s *TagService) Delete(userID, tagID uint) error {
+	if _, err := s.getOwned(userID, tagID); err != nil {
+		return err
+	}
+	return s.tagRepo.Delete(tagID)
+}
+
+// GetCounts returns the user's tags with how many active wishes carry each.
+func (s *TagService) GetCounts(userID uint) ([]models.TagCount, error) {
+	return s.tagRepo.GetCounts(userID)
+}
+
+// GetFacets returns the tags and categories of the user's wishes that the
+// viewer can see, with counts.
+func (s *TagService) GetFacets(viewerID uint, username string) (*models.WishFacets, error) {
+	owner, err := s.userRepo.FindByLogin(username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	access, err := s.accessPolicy.Access(viewerID, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.tagRepo.GetFacets(owner.ID, access.Visibilities())
+}
+
+func (s *TagService) getOwned(userID, tagID uint) (*models.Tag, error) {
+	tag, err := s.tagRepo.GetByID(tagID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if tag.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return tag, nil
+}
+
+// tagNames normalizes the names of the given tags and drops empty and
+// duplicate ones.
+func tagNames(tags []models.Tag) []string {
+	seen := make(map[string]bool, len(tags))
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		name := models.NormalizeTagName(tag.Name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+
+	// maxTwoFactorAttempts is how many codes may be tried before the second
+	// factor is locked for the configured lockout.
+	maxTwoFactorAttempts = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TwoFactorService struct {
+	userRepo      repository.UserRepositoryInterface
+	twoFactorRepo repository.TwoFactorRepositoryInterface
+	cfg           *config.Config
+}
+
+func NewTwoFactorService(userRepo repository.UserRepositoryInterface, twoFactorRepo repository.TwoFactorRepositoryInterface, cfg *config.Config) *TwoFactorService {
+	return &TwoFactorService{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		cfg:           cfg,
+	}
+}
+
+// TOTPEnrollment is shown to the user once, to add the account to their
+// authenticator app.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+func (s *TwoFactorService) Status(userID uint) (*models.TwoFactorStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatus{
+		Enabled:   user.TwoFactorEnabled(),
+		EnabledAt: user.TOTPEnabledAt,
+	}
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = s.twoFactorRepo.CountRecoveryCodes(userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// Enroll generates a new TOTP secret for the user. It takes effect once
+// confirmed with a code; enrolling again before that replaces the secret.
+func (s *TwoFactorService) Enroll(userID uint) (*TOTPEnrollment, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := s.userRepo.Update(user, "totp_secret", "totp_last_step"); err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.cfg.Auth.TOTPIssuer, user.Login, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the user proves their app
+// produces valid codes. It returns the recovery codes, which are not stored
+// in plain text and cannot be shown again.
+func (s *TwoFactorService) Confirm(userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, rows, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	user.TOTPLastStep = step
+	user.TOTPFailedAttempts = 0
+	user.TOTPAttemptsSince = nil
+	user.TOTPLockedUntil = nil
+	if err := s.twoFactorRepo.Enable(user, rows); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off. Both the password and a code
+// from the app or a recovery code are required.
+func (s *TwoFactorService) Disable(userID uint, password, code string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrWrongPassword
+	}
+	if err := s.checkCode(user, code); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.Disable(userID)
+}
+
+// CreateChallenge records a login challenge of the user, identified by the
+// hash of its ID, until it expires.
+func (s *TwoFactorService) CreateChallenge(userID uint, tokenHash string, expiresAt time.Time) error {
+	return s.twoFactorRepo.CreateChallenge(&models.LoginChallenge{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// VerifyLogin completes the user's login challenge with the given hash once
+// the code is right. Each challenge completes a single login.
+func (s *TwoFactorService) VerifyLogin(user *models.User, challengeHash, code string) error {
+	challenge, err := s.twoFactorRepo.GetChallenge(challengeHash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidChallenge
+		}
+		return err
+	}
+	if challenge.UserID != user.ID || challenge.UsedAt != nil || !time.Now().Before(challenge.ExpiresAt) {
+		return ErrInvalidChallenge
+	}
+
+	if err := s.checkCode(user, code); err != nil {
+		return err
+	}
+	if err := s.twoFactorRepo.UseChallenge(challenge.ID); err != nil {
+		if errors.Is(err, repository.ErrChallengeUsed) {
+			return ErrInvalidChallenge
+		}
+		return err
+	}
+	return nil
+}
+
+// checkCode verifies a code within the attempt limit. The attempt is counted
+// before the code is checked, so parallel guesses cannot get around the
+// limit, and a right code clears the count. Once the limit is reached the
+// second factor stays locked for the lockout, whether or not the password is
+// entered again.
+func (s *TwoFactorService) checkCode(user *models.User, code string) error {
+	if err := s.twoFactorRepo.ReserveAttempt(user.ID, maxTwoFactorAttempts, s.cfg.Auth.TwoFactorLockout); err != nil {
+		if errors.Is(err, repository.ErrTooManyAttempts) {
+			return ErrTooManyTwoFactorAttempts
+		}
+		return err
+	}
+	if err := s.verifyCode(user, code); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.ResetFailedAttempts(user.ID)
+}
+
+// verifyCode accepts a current code from the authenticator app or an unused
+// recovery code. Either works only once.
+func (s *TwoFactorService) verifyCode(user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		if err := s.twoFactorRepo.UseStep(user.ID, step); err != nil {
+			if errors.Is(err, repository.ErrCodeReused) {
+				return ErrInvalidTwoFactorCode
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := s.twoFactorRepo.UseRecoveryCode(user.ID, recoveryCodeHash(user.ID, normalizeRecoveryCode(code))); err != nil {
+		if errors.Is(err, repository.ErrCodeReused) {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns codes like "k3m9q-xa2rt", ten base32
+// characters or 50 random bits each, and the rows that store their hashes.
+func generateRecoveryCodes(userID uint) ([]string, []models.RecoveryCode, error) {
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		rows[i] = models.RecoveryCode{UserID: userID, CodeHash: recoveryCodeHash(userID, code)}
+	}
+	return codes, rows, nil
+}
+
+// normalizeRecoveryCode accepts recovery codes typed with or without the
+// dash, in any case.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// recoveryCodeHash hashes a normalized recovery code with Argon2id, salted
+// with the user's ID. Recovery codes are short enough that a fast hash could
+// be reversed by brute force if the hashes leaked.
+func recoveryCodeHash(userID uint, code string) string {
+	salt := []byte(fmt.Sprintf("wishlist-recovery-code:%d", userID))
+	return hex.EncodeToString(argon2.IDKey([]byte(code), salt, 2, 19*1024, 1, 32))
+}
@@ -2,39 +2,137 @@ package service
 
 import (
 	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 
 	"wishlist-app/internal/models"
 	"wishlist-app/internal/repository"
+	"wishlist-app/pkg/money"
+	"wishlist-app/pkg/pagination"
+	"wishlist-app/pkg/product"
+	"wishlist-app/pkg/rank"
 )
 
+// WishMove places a wish right after another one in the owner's manual order,
+// or first when AfterID is nil.
+type WishMove struct {
+	WishID  uint
+	AfterID *uint
+}
+
 type WishService struct {
-	wishRepo repository.WishRepositoryInterface
-	userRepo repository.UserRepositoryInterface
+	wishRepo     repository.WishRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	wishlistRepo repository.WishlistRepositoryInterface
+	tagRepo      repository.TagRepositoryInterface
+	accessPolicy *AccessPolicy
 }
 
-func NewWishService(wishRepo repository.WishRepositoryInterface, userRepo repository.UserRepositoryInterface) *WishService {
+func NewWishService(wishRepo repository.WishRepositoryInterface, userRepo repository.UserRepositoryInterface, wishlistRepo repository.WishlistRepositoryInterface, tagRepo repository.TagRepositoryInterface, accessPolicy *AccessPolicy) *WishService {
 	return &WishService{
-		wishRepo: wishRepo,
-		userRepo: userRepo,
+		wishRepo:     wishRepo,
+		userRepo:     userRepo,
+		wishlistRepo: wishlistRepo,
+		tagRepo:      tagRepo,
+		accessPolicy: accessPolicy,
 	}
 }
 
 func (s *WishService) Create(userID uint, wish *models.Wish) (*models.Wish, error) {
 	wish.UserID = userID
+	wishlistID, err := s.resolveWishlist(userID, wish.WishlistID)
+	if err != nil {
+		return nil, err
+	}
+	wish.WishlistID = &wishlistID
+	if wish.Priority == "" {
+		wish.Priority = models.PriorityWant
+	}
+	if wish.Quantity < 1 {
+		wish.Quantity = 1
+	}
+	if wish.Category != "" && !wish.Category.Valid() {
+		return nil, ErrInvalidCategory
+	}
+	if err := validatePrice(wish); err != nil {
+		return nil, err
+	}
+	if err := validateProductURL(wish.ProductURL); err != nil {
+		return nil, err
+	}
+	if wish.Tags != nil {
+		if wish.Tags, err = s.tagRepo.FindOrCreate(userID, tagNames(wish.Tags)); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.wishRepo.Create(wish); err != nil {
 		return nil, err
 	}
 	return wish, nil
 }
 
+// Copy saves a wish the user can see on someone else's list as a new wish of
+// their own, on the given list or their default one. The copy starts with
+// the title, description, links, price and category of the original and
+// remembers it for attribution. Uploaded images are copied separately.
+func (s *WishService) Copy(userID, sourceID uint, wishlistID *uint) (*models.Wish, error) {
+	source, err := s.wishRepo.GetByID(sourceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	visible, err := s.accessPolicy.CanSeeWish(userID, source)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrNotFound
+	}
+	if source.UserID == userID {
+		return nil, ErrCopyOwnWish
+	}
+	if !source.Active() {
+		return nil, ErrWishNotActive
+	}
+
+	wish, err := s.Create(userID, &models.Wish{
+		WishlistID:    wishlistID,
+		Title:         source.Title,
+		Comment:       source.Comment,
+		ImageURL:      source.ImageURL,
+		ProductURL:    source.ProductURL,
+		PriceMinor:    source.PriceMinor,
+		PriceMaxMinor: source.PriceMaxMinor,
+		PriceUnknown:  source.PriceUnknown,
+		Currency:      source.Currency,
+		Category:      source.Category,
+		SourceWishID:  &source.ID,
+		SourceUserID:  &source.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	wish.SourceUser = &source.User
+	return wish, nil
+}
+
 func (s *WishService) GetByID(userID, wishID uint) (*models.Wish, error) {
 	wish, err := s.wishRepo.GetByID(wishID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 
 	if wish.UserID != userID {
-		return nil, errors.New("unauthorized")
+		return nil, ErrForbidden
 	}
 
 	return wish, nil
@@ -47,11 +145,73 @@ func (s *WishService) Update(userID uint, wish *models.Wish) error {
 	}
 
 	if existingWish.UserID != userID {
-		return errors.New("unauthorized")
+		return ErrForbidden
 	}
 
-	wish.UserID = userID
-	return s.wishRepo.Update(wish)
+	if wish.WishlistID != nil {
+		wishlistID, err := s.resolveWishlist(userID, wish.WishlistID)
+		if err != nil {
+			return err
+		}
+		existingWish.WishlistID = &wishlistID
+	}
+
+	if wish.Category != "" && !wish.Category.Valid() {
+		return ErrInvalidCategory
+	}
+	if err := validatePrice(wish); err != nil {
+		return err
+	}
+	if err := validateProductURL(wish.ProductURL); err != nil {
+		return err
+	}
+
+	existingWish.Title = wish.Title
+	existingWish.Comment = wish.Comment
+	existingWish.ImageURL = wish.ImageURL
+	existingWish.ProductURL = wish.ProductURL
+	existingWish.PriceMinor = wish.PriceMinor
+	existingWish.PriceMaxMinor = wish.PriceMaxMinor
+	existingWish.PriceUnknown = wish.PriceUnknown
+	if wish.Currency != "" && wish.Currency != existingWish.Currency {
+		// Pledges are stored in minor units of the wish's currency, so
+		// changing it would silently change what they are worth.
+		pledged, err := s.wishRepo.HasPledges(existingWish.ID)
+		if err != nil {
+			return err
+		}
+		if pledged {
+			return ErrCurrencyLocked
+		}
+		existingWish.Currency = wish.Currency
+	}
+	if wish.Visibility != "" {
+		existingWish.Visibility = wish.Visibility
+	}
+	if wish.Priority != "" {
+		existingWish.Priority = wish.Priority
+	}
+	if wish.Quantity > 0 {
+		if wish.Quantity < existingWish.Received {
+			return ErrInvalidQuantity
+		}
+		existingWish.Quantity = wish.Quantity
+	}
+	if wish.Category != "" {
+		existingWish.Category = wish.Category
+	}
+	if err := s.wishRepo.Update(existingWish); err != nil {
+		return err
+	}
+
+	if wish.Tags == nil {
+		return nil
+	}
+	tags, err := s.tagRepo.FindOrCreate(userID, tagNames(wish.Tags))
+	if err != nil {
+		return err
+	}
+	return s.wishRepo.ReplaceTags(existingWish, tags)
 }
 
 func (s *WishService) Delete(userID, wishID uint) error {
@@ -61,16 +221,333 @@ func (s *WishService) Delete(userID, wishID uint) error {
 	}
 
 	if wish.UserID != userID {
-		return errors.New("unauthorized")
+		return ErrForbidden
 	}
 
 	return s.wishRepo.Delete(wishID)
 }
 
-func (s *WishService) GetByUserID(userID uint) ([]models.Wish, error) {
-	return s.wishRepo.GetByUserID(userID)
+// GetByUserID returns a page of the user's wishes matching the filter, active
+// ones unless another status is requested, with the cursor of the next page,
+// if any.
+func (s *WishService) GetByUserID(userID uint, filter models.WishFilter, cursor string, limit int) ([]models.Wish, string, error) {
+	if filter.Status == "" {
+		filter.Status = models.WishActive
+	}
+	if err := normalizeFilter(&filter); err != nil {
+		return nil, "", err
+	}
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, "", ErrInvalidCursor
+	}
+
+	wishes, next, err := s.wishRepo.GetByUserID(userID, filter, after, pagination.Limit(limit))
+	return pageResult(wishes, next, err)
+}
+
+// GetHistory returns what the user has received, most recent first.
+func (s *WishService) GetHistory(userID uint) ([]models.Wish, error) {
+	return s.wishRepo.GetReceived(userID)
 }
 
-func (s *WishService) GetByUsername(username string) ([]models.Wish, error) {
-	return s.wishRepo.GetByUsername(username)
+// Receive marks the wish as received, optionally with a thank-you note for
+// whoever gave it. Received wishes disappear from public views.
+func (s *WishService) Receive(userID, wishID uint, note string) (*models.Wish, error) {
+	wish, err := s.transition(userID, wishID, models.WishReceived)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	wish.ReceivedAt = &now
+	wish.ThankYouNote = note
+	if err := s.wishRepo.Update(wish); err != nil {
+		return nil, err
+	}
+	return wish, nil
+}
+
+// Archive hides the wish without deleting it. A received wish keeps its
+// place in the history.
+func (s *WishService) Archive(userID, wishID uint) (*models.Wish, error) {
+	wish, err := s.transition(userID, wishID, models.WishArchived)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.wishRepo.Update(wish); err != nil {
+		return nil, err
+	}
+	return wish, nil
+}
+
+// Restore makes the wish active again. Restoring a received wish removes it
+// from the history.
+func (s *WishService) Restore(userID, wishID uint) (*models.Wish, error) {
+	wish, err := s.transition(userID, wishID, models.WishActive)
+	if err != nil {
+		return nil, err
+	}
+
+	wish.ReceivedAt = nil
+	wish.ThankYouNote = ""
+	if err := s.wishRepo.Update(wish); err != nil {
+		return nil, err
+	}
+	return wish, nil
+}
+
+// SetThankYouNote changes the note shown to the gifters of a received wish.
+func (s *WishService) SetThankYouNote(userID, wishID uint, note string) (*models.Wish, error) {
+	wish, err := s.getOwned(userID, wishID)
+	if err != nil {
+		return nil, err
+	}
+	if wish.ReceivedAt == nil {
+		return nil, ErrNotReceived
+	}
+
+	wish.ThankYouNote = note
+	if err := s.wishRepo.Update(wish); err != nil {
+		return nil, err
+	}
+	return wish, nil
+}
+
+func (s *WishService) transition(userID, wishID uint, next models.WishStatus) (*models.Wish, error) {
+	wish, err := s.getOwned(userID, wishID)
+	if err != nil {
+		return nil, err
+	}
+	if !wish.Status.CanTransition(next) {
+		return nil, ErrInvalidTransition
+	}
+	wish.Status = next
+	return wish, nil
+}
+
+// GetByUsername returns a page of the user's wishes matching the filter that
+// the viewer is allowed to see, with the cursor of the next page, if any.
+func (s *WishService) GetByUsername(viewerID uint, username string, filter models.WishFilter, cursor string, limit int) ([]models.Wish, string, error) {
+	if err := normalizeFilter(&filter); err != nil {
+		return nil, "", err
+	}
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, "", ErrInvalidCursor
+	}
+
+	owner, err := s.userRepo.FindByLogin(username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", err
+	}
+
+	access, err := s.accessPolicy.Access(viewerID, owner.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	wishes, next, err := s.wishRepo.GetByUsername(username, access.Visibilities(), filter, after, pagination.Limit(limit))
+	return pageResult(wishes, next, err)
+}
+
+// pageResult encodes the cursor of the next page returned by the repository.
+// A cursor the repository rejects, e.g. one from a list sorted differently,
+// is the client's error.
+func pageResult(wishes []models.Wish, next *pagination.Cursor, err error) ([]models.Wish, string, error) {
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return nil, "", ErrInvalidCursor
+		}
+		return nil, "", err
+	}
+	if next == nil {
+		return wishes, "", nil
+	}
+	return wishes, pagination.Encode(*next), nil
+}
+
+// Search runs a full-text search over the wishes the viewer can see and
+// returns a page of results, most relevant first, together with the cursor of
+// the next page, if any.
+func (s *WishService) Search(viewerID uint, search models.WishSearch, cursor string, limit int) ([]models.WishSearchResult, string, error) {
+	search.Query = strings.TrimSpace(search.Query)
+	if search.Query == "" {
+		return nil, "", ErrInvalidSearch
+	}
+	// Results are always ordered by relevance.
+	if search.Filter.Sort != models.SortManual {
+		return nil, "", ErrInvalidSort
+	}
+	if err := normalizeFilter(&search.Filter); err != nil {
+		return nil, "", err
+	}
+
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, "", ErrInvalidCursor
+	}
+	limit = pagination.Limit(limit)
+
+	results, err := s.wishRepo.Search(viewerID, search, after, limit+1)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return nil, "", ErrInvalidCursor
+		}
+		return nil, "", err
+	}
+
+	var next string
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		next = pagination.Encode(pagination.Cursor{
+			Value: strconv.FormatFloat(last.Rank, 'g', -1, 64),
+			ID:    last.Wish.ID,
+		})
+	}
+	return results, next, nil
+}
+
+// validatePrice checks the price range of a wish and clears the bounds of a
+// wish whose price is unknown.
+func validatePrice(wish *models.Wish) error {
+	if wish.PriceUnknown {
+		wish.PriceMinor, wish.PriceMaxMinor = 0, 0
+		return nil
+	}
+	if wish.PriceMinor < 0 || wish.PriceMinor > wish.PriceMaxMinor {
+		return ErrInvalidPriceRange
+	}
+	if wish.PriceMaxMinor > 0 && !money.ValidCurrency(wish.Currency) {
+		return ErrInvalidCurrency
+	}
+	return nil
+}
+
+func validateProductURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	if _, err := product.ParseURL(rawURL); err != nil {
+		return ErrInvalidProductURL
+	}
+	return nil
+}
+
+func normalizeFilter(filter *models.WishFilter) error {
+	if filter.Category != "" && !filter.Category.Valid() {
+		return ErrInvalidCategory
+	}
+	if !filter.Sort.Valid() {
+		return ErrInvalidSort
+	}
+	if filter.HasPrice() && !money.ValidCurrency(filter.Currency) {
+		return ErrInvalidCurrency
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return ErrInvalidPriceRange
+	}
+	filter.Tag = models.NormalizeTagName(filter.Tag)
+	return nil
+}
+
+// SetReceived records how many items of the wish the owner already has. They
+// no longer count as available to gifters.
+func (s *WishService) SetReceived(userID, wishID uint, received int) error {
+	wish, err := s.getOwned(userID, wishID)
+	if err != nil {
+		return err
+	}
+	if received < 0 || received > max(wish.Quantity, 1) {
+		return ErrInvalidQuantity
+	}
+
+	wish.Received = received
+	return s.wishRepo.Update(wish)
+}
+
+// Reorder applies the moves in order. Each move only rewrites the rank of the
+// moved wish.
+func (s *WishService) Reorder(userID uint, moves []WishMove) error {
+	for _, move := range moves {
+		if err := s.move(userID, move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *WishService) move(userID uint, move WishMove) error {
+	wish, err := s.getOwned(userID, move.WishID)
+	if err != nil {
+		return err
+	}
+
+	lower := ""
+	if move.AfterID != nil {
+		if *move.AfterID == wish.ID {
+			return ErrInvalidMove
+		}
+		after, err := s.getOwned(userID, *move.AfterID)
+		if err != nil {
+			return err
+		}
+		lower = after.Rank
+	}
+
+	upper, err := s.wishRepo.NextRank(userID, lower)
+	if err != nil {
+		return err
+	}
+	if upper == wish.Rank {
+		return nil
+	}
+
+	newRank, err := rank.Between(lower, upper)
+	if err != nil {
+		return err
+	}
+	return s.wishRepo.UpdateRank(wish.ID, newRank)
+}
+
+func (s *WishService) getOwned(userID, wishID uint) (*models.Wish, error) {
+	wish, err := s.wishRepo.GetByID(wishID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if wish.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return wish, nil
+}
+
+// resolveWishlist checks that the requested list belongs to the user, falling
+// back to the user's default list when none is given.
+func (s *WishService) resolveWishlist(userID uint, wishlistID *uint) (uint, error) {
+	if wishlistID == nil {
+		wishlist, err := s.wishlistRepo.GetOrCreateDefault(userID)
+		if err != nil {
+			return 0, err
+		}
+		return wishlist.ID, nil
+	}
+
+	wishlist, err := s.wishlistRepo.GetByID(*wishlistID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	if wishlist.UserID != userID {
+		return 0, ErrNotFound
+	}
+	return wishlist.ID, nil
 }
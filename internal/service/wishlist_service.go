@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+)
+
+type WishlistService struct {
+	wishlistRepo repository.WishlistRepositoryInterface
+	wishRepo     repository.WishRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	accessPolicy *AccessPolicy
+}
+
+func NewWishlistService(wishlistRepo repository.WishlistRepositoryInterface, wishRepo repository.WishRepositoryInterface, userRepo repository.UserRepositoryInterface, accessPolicy *AccessPolicy) *WishlistService {
+	return &WishlistService{
+		wishlistRepo: wishlistRepo,
+		wishRepo:     wishRepo,
+		userRepo:     userRepo,
+		accessPolicy: accessPolicy,
+	}
+}
+
+func (s *WishlistService) Create(userID uint, wishlist *models.Wishlist) (*models.Wishlist, error) {
+	wishlist.UserID = userID
+	wishlist.IsDefault = false
+	if err := s.wishlistRepo.Create(wishlist); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// GetOwned returns the list if it belongs to the user.
+func (s *WishlistService) GetOwned(userID, wishlistID uint) (*models.Wishlist, error) {
+	wishlist, err := s.wishlistRepo.GetByID(wishlistID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if wishlist.UserID != userID {
+		return nil, ErrNotFound
+	}
+
+	return wishlist, nil
+}
+
+func (s *WishlistService) Update(userID uint, wishlist *models.Wishlist) error {
+	existing, err := s.GetOwned(userID, wishlist.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.Title = wishlist.Title
+	existing.Description = wishlist.Description
+	existing.CoverImageURL = wishlist.CoverImageURL
+	existing.Position = wishlist.Position
+	if wishlist.Visibility != "" {
+		existing.Visibility = wishlist.Visibility
+	}
+	return s.wishlistRepo.Update(existing)
+}
+
+// SetCoOrganizer designates the user with the given login as co-organizer of
+// the list. An empty login removes the co-organizer.
+func (s *WishlistService) SetCoOrganizer(userID, wishlistID uint, login string) (*models.Wishlist, error) {
+	wishlist, err := s.GetOwned(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	var coOrganizer *models.User
+	if login != "" {
+		if coOrganizer, err = s.userRepo.FindByLogin(login); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		if coOrganizer.ID == userID {
+			return nil, ErrSelfCoOrganizer
+		}
+	}
+
+	wishlist.CoOrganizer = nil
+	wishlist.CoOrganizerID = nil
+	if coOrganizer != nil {
+		wishlist.CoOrganizerID = &coOrganizer.ID
+	}
+	if err := s.wishlistRepo.Update(wishlist); err != nil {
+		return nil, err
+	}
+	wishlist.CoOrganizer = coOrganizer
+	return wishlist, nil
+}
+
+// Delete removes the list; its wishes are moved to the user's default list.
+func (s *WishlistService) Delete(userID, wishlistID uint) error {
+	wishlist, err := s.GetOwned(userID, wishlistID)
+	if err != nil {
+		return err
+	}
+
+	if wishlist.IsDefault {
+		return ErrDefaultWishlist
+	}
+
+	defaultList, err := s.wishlistRepo.GetOrCreateDefault(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.wishlistRepo.Delete(wishlistID, defaultList.ID)
+}
+
+func (s *WishlistService) GetByUserID(userID uint) ([]models.Wishlist, error) {
+	return s.wishlistRepo.GetByUserID(userID)
+}
+
+// GetByUsername returns the user's lists that the viewer is allowed to see.
+func (s *WishlistService) GetByUsername(viewerID uint, username string) ([]models.Wishlist, error) {
+	owner, err := s.userRepo.FindByLogin(username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	access, err := s.accessPolicy.Access(viewerID, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.wishlistRepo.GetByUsername(username, access.Visibilities())
+}
+
+// GetWithWishes returns a list together with the wishes on it that the viewer
+// is allowed to see. Lists hidden from the viewer are reported as not found.
+func (s *WishlistService) GetWithWishes(viewerID, wishlistID uint) (*models.Wishlist, error) {
+	wishlist, err := s.wishlistRepo.GetByID(wishlistID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	access, err := s.accessPolicy.Access(viewerID, wishlist.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.CanSee(wishlist.Visibility) {
+		return nil, ErrNotFound
+	}
+
+	wishes, err := s.wishRepo.GetByWishlistID(wishlistID, access.Visibilities())
+	if err != nil {
+		return nil, err
+	}
+	wishlist.Wishes = wishes
+
+	return wishlist, nil
+}
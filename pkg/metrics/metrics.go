@@ -26,6 +26,46 @@ var (
 		Name: "wish_operations_total",
 		Help: "Total number of wish operations",
 	}, []string{"type", "status"})
+
+	WishlistOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wishlist_operations_total",
+		Help: "Total number of wishlist operations",
+	}, []string{"type", "status"})
+
+	FriendshipOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "friendship_operations_total",
+		Help: "Total number of friendship operations",
+	}, []string{"type", "status"})
+
+	OccasionOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "occasion_operations_total",
+		Help: "Total number of occasion operations",
+	}, []string{"type", "status"})
+
+	TagOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tag_operations_total",
+		Help: "Total number of tag operations",
+	}, []string{"type", "status"})
+
+	GiftExchangeOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gift_exchange_operations_total",
+		Help: "Total number of gift exchange operations",
+	}, []string{"type", "status"})
+
+	CurrencyOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "currency_operations_total",
+		Help: "Total number of currency operations",
+	}, []string{"type", "status"})
+
+	ImageOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "image_operations_total",
+		Help: "Total number of image operations",
+	}, []string{"type", "status"})
+
+	CommentOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "comment_operations_total",
+		Help: "Total number of wish comment operations",
+	}, []string{"type", "status"})
 )
 
 func RecordDatabaseQuery(queryType, table string, duration float64) {
@@ -41,6 +81,30 @@ func RecordWishOperation(operationType, status string) {
 	WishOperations.WithLabelValues(operationType, status).Inc()
 }
 
+func RecordWishlistOperation(operationType, status string) {
+	WishlistOperations.WithLabelValues(operationType, status).Inc()
+}
+
+func RecordFriendshipOperation(operationType, status string) {
+	FriendshipOperations.WithLabelValues(operationType, status).Inc()
+}
+
+func RecordOccasionOperation(operationType, status string) {
+	OccasionOperations.WithLabelValues(operationType, status).Inc()
+}
+
+func RecordTagOperation(operationType, status string) {
+	TagOperations.WithLabelValues(operationType, status).Inc()
+}
+
+func RecordGiftExchangeOperation(operationType, status string) {
+	GiftExchangeOperations.WithLabelValues(operationType, status).Inc()
+}
+
+func RecordCurrencyOperation(operationType, status string) {
+	CurrencyOperations.WithLabelValues(operationType, status).Inc()
+}
+
 func Init() {
 	promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "app_info",
@@ -50,3 +114,11 @@ func Init() {
 		},
 	}).Set(1)
 }
+
+func RecordImageOperation(operationType, status string) {
+	ImageOperations.WithLabelValues(operationType, status).Inc()
+}
+
+func RecordCommentOperation(operationType, status string) {
+	CommentOperations.WithLabelValues(operationType, status).Inc()
+}
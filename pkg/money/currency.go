@@ -0,0 +1,38 @@
+package money
+
+import "sort"
+
+// minorDigits maps ISO 4217 currency codes to the number of digits after the
+// decimal point of their minor unit.
+var minorDigits = map[string]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2,
+	"CHF": 2, "CLP": 0, "CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EGP": 2,
+	"EUR": 2, "GBP": 2, "GEL": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2,
+	"INR": 2, "ISK": 0, "JOD": 3, "JPY": 0, "KES": 2, "KRW": 0, "KWD": 3,
+	"KZT": 2, "MAD": 2, "MXN": 2, "MYR": 2, "NGN": 2, "NOK": 2, "NZD": 2,
+	"OMR": 3, "PEN": 2, "PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2, "RON": 2,
+	"RSD": 2, "RUB": 2, "SAR": 2, "SEK": 2, "SGD": 2, "THB": 2, "TND": 3,
+	"TRY": 2, "TWD": 2, "UAH": 2, "USD": 2, "UYU": 2, "VND": 0, "ZAR": 2,
+}
+
+// ValidCurrency reports whether code is a supported ISO 4217 currency code.
+// Codes are upper case.
+func ValidCurrency(code string) bool {
+	_, ok := minorDigits[code]
+	return ok
+}
+
+// Digits returns the number of minor unit digits of the currency.
+func Digits(code string) int {
+	return minorDigits[code]
+}
+
+// Currencies returns the supported currency codes in alphabetical order.
+func Currencies() []string {
+	codes := make([]string, 0, len(minorDigits))
+	for code := range minorDigits {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,97 @@
+// Package money handles amounts of money exactly. Amounts are stored as an
+// integer number of minor units (cents for USD, yen for JPY) next to an ISO
+// 4217 currency code and are exchanged with clients as decimal strings, so
+// they never pass through a float.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// maxIntegerDigits keeps parsed amounts well within int64 for every currency.
+const maxIntegerDigits = 13
+
+var (
+	ErrInvalidAmount   = errors.New("money: amount must be a non-negative decimal number")
+	ErrTooPrecise      = errors.New("money: amount has more decimal places than the currency allows")
+	ErrInvalidCurrency = errors.New("money: unknown currency code")
+)
+
+// Parse converts a decimal string such as "12.5" into minor units of the
+// currency. Trailing zeros beyond the currency's precision are accepted.
+func Parse(s, currency string) (int64, error) {
+	if !ValidCurrency(currency) {
+		return 0, ErrInvalidCurrency
+	}
+
+	whole, frac, _ := strings.Cut(strings.TrimSpace(s), ".")
+	if whole == "" && frac == "" || len(whole) > maxIntegerDigits || !isDigits(whole) || !isDigits(frac) {
+		return 0, ErrInvalidAmount
+	}
+
+	digits := Digits(currency)
+	if len(frac) > digits {
+		if strings.Trim(frac[digits:], "0") != "" {
+			return 0, ErrTooPrecise
+		}
+		frac = frac[:digits]
+	}
+
+	var amount int64
+	for _, c := range whole + frac + strings.Repeat("0", digits-len(frac)) {
+		amount = amount*10 + int64(c-'0')
+	}
+	return amount, nil
+}
+
+// Format renders minor units of the currency as a decimal string with exactly
+// the currency's number of decimal places.
+func Format(amount int64, currency string) string {
+	digits := Digits(currency)
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	s := strconv.FormatInt(amount, 10)
+	if digits == 0 {
+		return sign + s
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+// Decimal is an amount as sent by a client, either as a JSON number or a JSON
+// string. It keeps the original text so that it can be parsed exactly once
+// the currency is known.
+type Decimal string
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*d = Decimal(s)
+		return nil
+	}
+	*d = Decimal(data)
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
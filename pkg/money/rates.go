@@ -0,0 +1,106 @@
+package money
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+var ErrNoRate = errors.New("money: no exchange rate for currency")
+
+// Rates holds exchange rates relative to a common base currency: each rate is
+// the amount of that currency worth one unit of the base. The base itself
+// needs no entry other than a rate of 1.
+type Rates map[string]*big.Rat
+
+// ParseRates reads exchange rates from CSV lines of the form "currency,rate",
+// e.g. "USD,1.0842". Empty lines, lines starting with '#' and a leading
+// "currency,rate" header are skipped. Rates are decimal strings and kept
+// exactly as written.
+func ParseRates(r io.Reader) (map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	rates := make(map[string]string)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		code := strings.ToUpper(strings.TrimSpace(record[0]))
+		rate := strings.TrimSpace(record[1])
+		if line == 1 && code == "CURRENCY" {
+			continue
+		}
+		if !ValidCurrency(code) {
+			return nil, fmt.Errorf("line %d: %w: %q", line, ErrInvalidCurrency, record[0])
+		}
+		if _, err := ParseRate(rate); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates[code] = rate
+	}
+	return rates, nil
+}
+
+// ParseRate parses a positive decimal exchange rate.
+func ParseRate(s string) (*big.Rat, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("invalid exchange rate %q", s)
+	}
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q", s)
+	}
+	return rate, nil
+}
+
+// Convert converts minor units of one currency into minor units of another,
+// rounding half away from zero.
+func (r Rates) Convert(amount int64, from, to string) (int64, error) {
+	if from == to {
+		return amount, nil
+	}
+	fromRate, ok := r[from]
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrNoRate, from)
+	}
+	toRate, ok := r[to]
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrNoRate, to)
+	}
+
+	value := new(big.Rat).SetInt64(amount)
+	value.Mul(value, toRate)
+	value.Quo(value, fromRate)
+	value.Mul(value, new(big.Rat).SetInt(pow10(Digits(to))))
+	value.Quo(value, new(big.Rat).SetInt(pow10(Digits(from))))
+	return round(value), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// round returns the integer nearest to r, rounding halves away from zero.
+func round(r *big.Rat) int64 {
+	num := new(big.Int).Abs(r.Num())
+	quo, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return quo.Int64()
+}
@@ -0,0 +1,60 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points just past the last row of a page: the value of the sort column
+// and the row ID used as a tie-breaker. Null marks a NULL sort value, and Sort
+// names the order the cursor belongs to when a list can be sorted in several.
+type Cursor struct {
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+	Null  bool   `json:"n,omitempty"`
+	Sort  string `json:"s,omitempty"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func Encode(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor produced by Encode. An empty string yields nil.
+func Decode(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Limit clamps a requested page size to [1, MaxLimit], using DefaultLimit for
+// non-positive values.
+func Limit(requested int) int {
+	if requested <= 0 {
+		return DefaultLimit
+	}
+	if requested > MaxLimit {
+		return MaxLimit
+	}
+	return requested
+}
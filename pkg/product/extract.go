@@ -0,0 +1,303 @@
+package product
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Metadata is what could be learned about a product from its page. Prices are
+// decimal strings with a '.' separator; a page listing several offers gives a
+// PriceMin and PriceMax instead of a Price. Any field may be empty.
+type Metadata struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	Price       string `json:"price,omitempty"`
+	PriceMin    string `json:"price_min,omitempty"`
+	PriceMax    string `json:"price_max,omitempty"`
+	Currency    string `json:"currency,omitempty"`
+}
+
+// Extract reads schema.org Product JSON-LD, OpenGraph and Twitter card tags
+// from the page, in that order of preference, falling back to the document
+// title.
+func Extract(page *Page) *Metadata {
+	doc, err := html.Parse(bytes.NewReader(page.Body))
+	if err != nil {
+		return &Metadata{URL: page.URL.String()}
+	}
+
+	var (
+		meta     = make(map[string]string)
+		title    string
+		products []*Metadata
+	)
+	walk(doc, func(n *html.Node) {
+		switch n.DataAtom {
+		case atom.Title:
+			if title == "" && n.FirstChild != nil {
+				title = n.FirstChild.Data
+			}
+		case atom.Meta:
+			key := strings.ToLower(attr(n, "property"))
+			if key == "" {
+				key = strings.ToLower(attr(n, "name"))
+			}
+			if _, seen := meta[key]; key != "" && !seen {
+				meta[key] = attr(n, "content")
+			}
+		case atom.Script:
+			if strings.EqualFold(attr(n, "type"), "application/ld+json") && n.FirstChild != nil {
+				products = append(products, parseJSONLD([]byte(n.FirstChild.Data))...)
+			}
+		}
+	})
+
+	result := &Metadata{URL: page.URL.String()}
+	for _, product := range products {
+		merge(result, product)
+	}
+	merge(result, &Metadata{
+		Title:       first(meta["og:title"], meta["twitter:title"]),
+		Description: first(meta["og:description"], meta["twitter:description"], meta["description"]),
+		ImageURL:    first(meta["og:image:secure_url"], meta["og:image"], meta["twitter:image"], meta["twitter:image:src"]),
+		Price:       normalizePrice(first(meta["product:price:amount"], meta["og:price:amount"])),
+		Currency:    first(meta["product:price:currency"], meta["og:price:currency"]),
+	})
+	merge(result, &Metadata{Title: title})
+
+	result.Title = clean(result.Title)
+	result.Description = clean(result.Description)
+	result.Currency = strings.ToUpper(strings.TrimSpace(result.Currency))
+	result.ImageURL = resolve(page.URL, result.ImageURL)
+	return result
+}
+
+// merge fills the empty fields of dst from src. Prices are only taken
+// together with the currency they are in.
+func merge(dst, src *Metadata) {
+	dst.Title = first(dst.Title, src.Title)
+	dst.Description = first(dst.Description, src.Description)
+	dst.ImageURL = first(dst.ImageURL, src.ImageURL)
+	if dst.Price == "" && dst.PriceMin == "" && (src.Price != "" || src.PriceMin != "") {
+		dst.Price, dst.PriceMin, dst.PriceMax = src.Price, src.PriceMin, src.PriceMax
+		dst.Currency = src.Currency
+	}
+}
+
+// jsonLD covers the parts of schema.org documents that describe products.
+// Most properties may be a single value or a list.
+type jsonLD struct {
+	Type   json.RawMessage `json:"@type"`
+	Graph  []jsonLD        `json:"@graph"`
+	Name   string          `json:"name"`
+	Desc   string          `json:"description"`
+	Image  json.RawMessage `json:"image"`
+	Offers json.RawMessage `json:"offers"`
+}
+
+type jsonLDOffer struct {
+	Type          string          `json:"@type"`
+	Price         json.RawMessage `json:"price"`
+	LowPrice      json.RawMessage `json:"lowPrice"`
+	HighPrice     json.RawMessage `json:"highPrice"`
+	PriceCurrency string          `json:"priceCurrency"`
+	Specification *jsonLDOffer    `json:"priceSpecification"`
+}
+
+func parseJSONLD(data []byte) []*Metadata {
+	var nodes []jsonLD
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		var node jsonLD
+		if err := json.Unmarshal(data, &node); err != nil {
+			return nil
+		}
+		nodes = []jsonLD{node}
+	}
+
+	var products []*Metadata
+	for _, node := range nodes {
+		if len(node.Graph) > 0 {
+			graph, _ := json.Marshal(node.Graph)
+			products = append(products, parseJSONLD(graph)...)
+			continue
+		}
+		if !hasType(node.Type, "Product") {
+			continue
+		}
+
+		product := &Metadata{
+			Title:       node.Name,
+			Description: node.Desc,
+			ImageURL:    imageURL(node.Image),
+		}
+		setOffer(product, node.Offers)
+		products = append(products, product)
+	}
+	return products
+}
+
+func setOffer(product *Metadata, raw json.RawMessage) {
+	var offers []jsonLDOffer
+	if err := json.Unmarshal(raw, &offers); err != nil {
+		var offer jsonLDOffer
+		if err := json.Unmarshal(raw, &offer); err != nil {
+			return
+		}
+		offers = []jsonLDOffer{offer}
+	}
+
+	for _, offer := range offers {
+		if offer.Specification != nil && len(offer.Price) == 0 {
+			offer.Price = offer.Specification.Price
+			offer.PriceCurrency = first(offer.PriceCurrency, offer.Specification.PriceCurrency)
+		}
+		low, high, price := jsonPrice(offer.LowPrice), jsonPrice(offer.HighPrice), jsonPrice(offer.Price)
+		switch {
+		case low != "" && high != "" && low != high:
+			product.PriceMin, product.PriceMax = low, high
+		case price != "":
+			product.Price = price
+		case low != "":
+			product.Price = low
+		default:
+			continue
+		}
+		product.Currency = offer.PriceCurrency
+		return
+	}
+}
+
+func hasType(raw json.RawMessage, want string) bool {
+	var types []string
+	if err := json.Unmarshal(raw, &types); err != nil {
+		var single string
+		if err := json.Unmarshal(raw, &single); err != nil {
+			return false
+		}
+		types = []string{single}
+	}
+	for _, t := range types {
+		if strings.EqualFold(strings.TrimPrefix(strings.TrimPrefix(t, "http://schema.org/"), "https://schema.org/"), want) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageURL picks the first image of a schema.org image property, which may be
+// a URL, an ImageObject or a list of either.
+func imageURL(raw json.RawMessage) string {
+	var link string
+	if json.Unmarshal(raw, &link) == nil {
+		return link
+	}
+	var object struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(raw, &object) == nil && object.URL != "" {
+		return object.URL
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) == nil && len(list) > 0 {
+		return imageURL(list[0])
+	}
+	return ""
+}
+
+// jsonPrice reads a price given as a JSON number or string.
+func jsonPrice(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return normalizePrice(s)
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil && !strings.ContainsAny(n.String(), "eE-") {
+		return n.String()
+	}
+	return ""
+}
+
+// normalizePrice turns prices such as "1,299.00", "1.299,00" or "19,99" into
+// a plain decimal string, or returns an empty string if it is not a price.
+func normalizePrice(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == ',':
+			return r
+		}
+		return -1
+	}, s)
+
+	// The separator that comes last and is followed by one or two digits is
+	// the decimal point; all other separators group thousands.
+	decimal := strings.LastIndexAny(s, ".,")
+	if decimal >= 0 && (len(s)-decimal-1 > 2 || len(s)-decimal-1 == 0) {
+		decimal = -1
+	}
+	var out strings.Builder
+	for i, r := range s {
+		switch {
+		case i == decimal:
+			out.WriteByte('.')
+		case r != '.' && r != ',':
+			out.WriteRune(r)
+		}
+	}
+
+	if _, err := strconv.ParseFloat(out.String(), 64); err != nil || strings.Trim(out.String(), ".") == "" {
+		return ""
+	}
+	return out.String()
+}
+
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+func walk(n *html.Node, visit func(*html.Node)) {
+	if n.Type == html.ElementNode {
+		visit(n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, visit)
+	}
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clean collapses whitespace in text taken from a page.
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
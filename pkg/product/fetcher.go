@@ -0,0 +1,172 @@
+// Package product reads product pages of online stores and extracts the
+// title, image and price a wish can be prefilled with.
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const maxRedirects = 5
+
+var (
+	ErrInvalidURL         = errors.New("product: URL must be an absolute http or https URL")
+	ErrForbiddenAddress   = errors.New("product: URL points to a private or reserved address")
+	ErrUnsupportedContent = errors.New("product: page is not HTML")
+	ErrFetchFailed        = errors.New("product: page could not be fetched")
+)
+
+// Page is a fetched HTML document. URL is the address after redirects and is
+// used to resolve relative links.
+type Page struct {
+	URL  *url.URL
+	Body []byte
+}
+
+// Fetcher downloads product pages.
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*Page, error)
+}
+
+// HTTPFetcher fetches pages from the internet. It only connects to public
+// addresses, checked after DNS resolution so that a hostname cannot be used to
+// reach internal services, and reads at most maxBytes of each page.
+type HTTPFetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+func NewHTTPFetcher(timeout time.Duration, maxBytes int64) *HTTPFetcher {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !PublicAddress(addrPort.Addr()) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		// A proxy from the environment would make the connection on our
+		// behalf and bypass the address check.
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &HTTPFetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("%w: too many redirects", ErrFetchFailed)
+				}
+				if _, err := ParseURL(req.URL.String()); err != nil {
+					return err
+				}
+				return nil
+			},
+		},
+		maxBytes: maxBytes,
+	}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	target, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "wishlist-app/1.0 (+product preview)")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		for _, sentinel := range []error{ErrForbiddenAddress, ErrInvalidURL} {
+			if errors.Is(err, sentinel) {
+				return nil, sentinel
+			}
+		}
+		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrFetchFailed, resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrUnsupportedContent
+	}
+
+	// Metadata lives near the top of the document, so a page cut off at the
+	// limit is still useful.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+	return &Page{URL: resp.Request.URL, Body: body}, nil
+}
+
+// ParseURL accepts absolute http and https URLs with a host and no
+// credentials.
+func ParseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return nil, ErrInvalidURL
+	}
+	return u, nil
+}
+
+// reservedPrefixes are ranges that are not reachable on the public internet
+// and are not covered by the netip.Addr predicates.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// PublicAddress reports whether addr is a globally routable unicast address,
+// i.e. not loopback, private, link-local, multicast or otherwise reserved.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() ||
+		addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+// Package rank generates string sort keys for manual ordering. A new key can
+// always be placed between two existing ones, so moving an item only rewrites
+// that item's key. Keys compare with plain byte order (COLLATE "C" in SQL).
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+var ErrInvalidRange = errors.New("rank: lower bound must sort before upper bound")
+
+// Between returns a key that sorts strictly after lower and before upper. An
+// empty lower means the start of the order and an empty upper means its end.
+func Between(lower, upper string) (string, error) {
+	if !valid(lower) || !valid(upper) || (upper != "" && lower >= upper) {
+		return "", ErrInvalidRange
+	}
+	return midpoint(lower, upper), nil
+}
+
+// After returns a key that sorts after the given one.
+func After(key string) (string, error) {
+	return Between(key, "")
+}
+
+// Spread returns n evenly spaced keys of equal length in ascending order.
+// It is used to assign keys to existing items in bulk.
+func Spread(n int) []string {
+	width, space := 1, base
+	for space <= n {
+		width++
+		space *= base
+	}
+
+	keys := make([]string, n)
+	for i := range keys {
+		value := (i + 1) * space / (n + 1)
+		key := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			key[j] = digits[value%base]
+			value /= base
+		}
+		keys[i] = strings.TrimRight(string(key), digits[:1])
+	}
+	return keys
+}
+
+// midpoint treats keys as base-36 fractions, with lower padded by zeros and an
+// empty upper meaning one.
+func midpoint(lower, upper string) string {
+	n := 0
+	for n < len(upper) && digitAt(lower, n) == upper[n] {
+		n++
+	}
+	if n > 0 {
+		rest := ""
+		if n < len(lower) {
+			rest = lower[n:]
+		}
+		return upper[:n] + midpoint(rest, upper[n:])
+	}
+
+	low := 0
+	if lower != "" {
+		low = strings.IndexByte(digits, lower[0])
+	}
+	high := base
+	if upper != "" {
+		high = strings.IndexByte(digits, upper[0])
+	}
+
+	if high-low > 1 {
+		return string(digits[(low+high)/2])
+	}
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+	rest := ""
+	if lower != "" {
+		rest = lower[1:]
+	}
+	return string(digits[low]) + midpoint(rest, "")
+}
+
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return digits[0]
+}
+
+// valid reports whether key only uses rank digits and does not end with the
+// zero digit, which would leave no room for a key before it.
+func valid(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+	return key == "" || key[len(key)-1] != digits[0]
+}
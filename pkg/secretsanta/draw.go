@@ -0,0 +1,68 @@
+package secretsanta
+
+import (
+	"errors"
+	"math/rand/v2"
+)
+
+var ErrNoAssignment = errors.New("no valid assignment exists")
+
+// Draw assigns every participant a recipient so that nobody draws themselves,
+// every participant receives exactly one gift and no forbidden pair is used.
+// The result maps givers to recipients. It returns ErrNoAssignment when the
+// rules leave no valid assignment.
+func Draw(participants []uint, forbidden func(giver, recipient uint) bool, rng *rand.Rand) (map[uint]uint, error) {
+	if len(participants) < 2 {
+		return nil, ErrNoAssignment
+	}
+
+	candidates := make(map[uint][]uint, len(participants))
+	for _, giver := range participants {
+		for _, recipient := range participants {
+			if giver != recipient && !forbidden(giver, recipient) {
+				candidates[giver] = append(candidates[giver], recipient)
+			}
+		}
+		if len(candidates[giver]) == 0 {
+			return nil, ErrNoAssignment
+		}
+		rng.Shuffle(len(candidates[giver]), func(i, j int) {
+			candidates[giver][i], candidates[giver][j] = candidates[giver][j], candidates[giver][i]
+		})
+	}
+
+	givers := append([]uint(nil), participants...)
+	rng.Shuffle(len(givers), func(i, j int) { givers[i], givers[j] = givers[j], givers[i] })
+
+	// Find a perfect matching with augmenting paths: each giver takes a free
+	// recipient or bumps an earlier giver onto another candidate. Shuffled
+	// candidate lists keep the draw random while the search stays polynomial.
+	giverOf := make(map[uint]uint, len(participants))
+	var augment func(giver uint, visited map[uint]bool) bool
+	augment = func(giver uint, visited map[uint]bool) bool {
+		for _, recipient := range candidates[giver] {
+			if visited[recipient] {
+				continue
+			}
+			visited[recipient] = true
+			current, taken := giverOf[recipient]
+			if !taken || augment(current, visited) {
+				giverOf[recipient] = giver
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, giver := range givers {
+		if !augment(giver, make(map[uint]bool, len(participants))) {
+			return nil, ErrNoAssignment
+		}
+	}
+
+	assignment := make(map[uint]uint, len(participants))
+	for recipient, giver := range giverOf {
+		assignment[giver] = recipient
+	}
+	return assignment, nil
+}
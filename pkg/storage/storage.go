@@ -0,0 +1,48 @@
+// Package storage keeps uploaded files on the local filesystem or in an
+// S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("storage: object not found")
+	ErrInvalidKey = errors.New("storage: invalid key")
+)
+
+// Object is a stored file opened for reading.
+type Object struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// Storage stores files under keys such as "wishes/12/3f9a.jpg". Keys consist
+// of lower-case letters, digits, '-', '_', '.' and '/'-separated segments.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Presigner is implemented by storages that can hand out temporary URLs, so
+// that clients download files from the storage directly instead of through
+// the API.
+type Presigner interface {
+	PresignGet(key string, expiry time.Duration) (string, error)
+}
+
+var keyPattern = regexp.MustCompile(`^[a-z0-9_-][a-z0-9._-]*(/[a-z0-9_-][a-z0-9._-]*)*$`)
+
+// ValidKey reports whether key is well-formed. Segments cannot start with a
+// dot, so keys never escape the storage root.
+func ValidKey(key string) bool {
+	return len(key) <= 255 && keyPattern.MatchString(key) && !strings.Contains(key, "..")
+}
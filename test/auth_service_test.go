@@ -0,0 +1,485 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"wishlist-app/internal/config"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/mail"
+)
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(session *models.Session, token *models.RefreshToken) error {
+	args := m.Called(session, token)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) GetByID(id uint) (*models.Session, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) GetByUserID(userID uint) ([]models.Session, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Touch(id uint, seenAt time.Time) error {
+	args := m.Called(id, seenAt)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeOthers(userID, keepID uint) error {
+	args := m.Called(userID, keepID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Revoke(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) GetRefreshToken(hash string) (*models.RefreshToken, error) {
+	args := m.Called(hash)
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *MockSessionRepository) Rotate(used *models.RefreshToken, next *models.RefreshToken) error {
+	args := m.Called(used, next)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) DeleteExpired(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) GetByHash(hash string) (*models.PasswordResetToken, error) {
+	args := m.Called(hash)
+	return args.Get(0).(*models.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) Consume(token *models.PasswordResetToken, passwordHash string) error {
+	args := m.Called(token, passwordHash)
+	return args.Error(0)
+}
+
+func testAuthConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Auth.JWTSecret = "test-secret"
+	cfg.Auth.AccessTokenLifetime = 15 * time.Minute
+	cfg.Auth.RefreshTokenLifetime = 24 * time.Hour
+	cfg.Auth.SessionCacheTTL = time.Minute
+	return cfg
+}
+
+// activeSession returns a session that is neither revoked nor expired.
+func activeSession(id, userID uint) *models.Session {
+	return &models.Session{ID: id, UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}
+}
+
+// loginSession logs in as user 1 and returns the tokens together with the
+// refresh token row that was stored for them.
+func loginSession(t *testing.T, authService *service.AuthService, userRepo *MockUserRepository, sessionRepo *MockSessionRepository) (*service.TokenPair, *models.RefreshToken) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	userRepo.On("FindByLogin", "alice").Return(&models.User{Model: gorm.Model{ID: 1}, Login: "alice", PasswordHash: string(hashedPassword)}, nil)
+
+	sessionRepo.On("Touch", mock.Anything, mock.Anything).Return(nil)
+
+	var stored *models.RefreshToken
+	sessionRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Session).ID = 5
+		stored = args.Get(1).(*models.RefreshToken)
+		stored.SessionID = 5
+	}).Return(nil).Once()
+
+	result, err := authService.Login("alice", "secret", "Mozilla/5.0 (iPhone)", "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Nil(t, result.Challenge)
+	return result.Tokens, stored
+}
+
+func TestAuthService_LoginAndLogout(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	tokens, stored := loginSession(t, authService, userRepo, sessionRepo)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.NotEqual(t, tokens.RefreshToken, stored.TokenHash)
+	assert.Equal(t, 15*time.Minute, tokens.ExpiresIn)
+
+	sessionRepo.On("GetByID", uint(5)).Return(activeSession(5, 1), nil).Once()
+	claims, err := authService.Authenticate(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), claims.UserID)
+	assert.Equal(t, uint(5), claims.SessionID)
+
+	sessionRepo.On("Revoke", uint(5)).Return(nil)
+	assert.NoError(t, authService.Logout(1, 5))
+
+	revokedAt := time.Now()
+	sessionRepo.On("GetByID", uint(5)).Return(&models.Session{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+	_, err = authService.Authenticate(tokens.AccessToken)
+	assert.ErrorIs(t, err, service.ErrSessionRevoked)
+
+	_, err = authService.Login("alice", "wrong", "", "")
+	assert.Error(t, err)
+}
+
+func TestAuthService_RejectsTokensWithoutSession(t *testing.T) {
+	cfg := testAuthConfig()
+	authService := service.NewAuthService(new(MockUserRepository), new(MockSessionRepository), new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), cfg)
+
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &service.Claims{
+		UserID:           1,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString([]byte(cfg.Auth.JWTSecret))
+	_, err := authService.Authenticate(legacy)
+	assert.Error(t, err)
+
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, &service.Claims{UserID: 1, SessionID: 5}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	_, err = authService.Authenticate(unsigned)
+	assert.Error(t, err)
+}
+
+func TestAuthService_RefreshRotates(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	tokens, stored := loginSession(t, authService, userRepo, sessionRepo)
+	stored.ID = 10
+	stored.Session = *activeSession(5, 1)
+	sessionRepo.On("GetRefreshToken", stored.TokenHash).Return(stored, nil)
+
+	var next *models.RefreshToken
+	sessionRepo.On("Rotate", stored, mock.Anything).Run(func(args mock.Arguments) {
+		next = args.Get(1).(*models.RefreshToken)
+	}).Return(nil).Once()
+
+	refreshed, err := authService.Refresh(tokens.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+	assert.NotEqual(t, stored.TokenHash, next.TokenHash)
+	assert.True(t, next.ExpiresAt.After(time.Now().Add(23*time.Hour)))
+
+	sessionRepo.On("GetByID", uint(5)).Return(activeSession(5, 1), nil)
+	claims, err := authService.Authenticate(refreshed.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), claims.SessionID)
+
+	sessionRepo.On("GetRefreshToken", mock.Anything).Return((*models.RefreshToken)(nil), gorm.ErrRecordNotFound)
+	_, err = authService.Refresh("unknown")
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestAuthService_RefreshReuseRevokesSession(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	tokens, stored := loginSession(t, authService, userRepo, sessionRepo)
+	usedAt := time.Now()
+	stored.UsedAt = &usedAt
+	stored.Session = *activeSession(5, 1)
+	sessionRepo.On("GetRefreshToken", stored.TokenHash).Return(stored, nil)
+	sessionRepo.On("Revoke", uint(5)).Return(nil)
+
+	_, err := authService.Refresh(tokens.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+	sessionRepo.AssertCalled(t, "Revoke", uint(5))
+	sessionRepo.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_RefreshRaceRevokesSession(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	tokens, stored := loginSession(t, authService, userRepo, sessionRepo)
+	stored.Session = *activeSession(5, 1)
+	sessionRepo.On("GetRefreshToken", stored.TokenHash).Return(stored, nil)
+	sessionRepo.On("Rotate", stored, mock.Anything).Return(repository.ErrTokenReused)
+	sessionRepo.On("Revoke", uint(5)).Return(nil)
+
+	_, err := authService.Refresh(tokens.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+	sessionRepo.AssertCalled(t, "Revoke", uint(5))
+}
+
+func TestAuthService_RefreshExpiredOrRevoked(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	tokens, stored := loginSession(t, authService, userRepo, sessionRepo)
+	stored.Session = *activeSession(5, 1)
+	stored.ExpiresAt = time.Now().Add(-time.Minute)
+	sessionRepo.On("GetRefreshToken", stored.TokenHash).Return(stored, nil)
+
+	_, err := authService.Refresh(tokens.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+	revokedAt := time.Now()
+	stored.ExpiresAt = time.Now().Add(time.Hour)
+	stored.Session.RevokedAt = &revokedAt
+	_, err = authService.Refresh(tokens.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	sessionRepo.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_SessionCache(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	tokens, _ := loginSession(t, authService, userRepo, sessionRepo)
+	sessionRepo.On("GetByID", uint(5)).Return(activeSession(5, 1), nil).Once()
+
+	for i := 0; i < 3; i++ {
+		_, err := authService.Authenticate(tokens.AccessToken)
+		assert.NoError(t, err)
+	}
+	sessionRepo.AssertNumberOfCalls(t, "GetByID", 1)
+	sessionRepo.AssertNumberOfCalls(t, "Touch", 1)
+
+	// Logging out everywhere else from another device takes effect at once.
+	revokedAt := time.Now()
+	sessionRepo.On("RevokeOthers", uint(1), uint(8)).Return(nil)
+	sessionRepo.On("GetByID", uint(5)).Return(&models.Session{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+	assert.NoError(t, authService.RevokeOtherSessions(1, 8))
+
+	_, err := authService.Authenticate(tokens.AccessToken)
+	assert.ErrorIs(t, err, service.ErrSessionRevoked)
+}
+
+func TestAuthService_RevokeDuringAuthenticate(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	tokens, _ := loginSession(t, authService, userRepo, sessionRepo)
+	sessionRepo.On("Revoke", uint(5)).Return(nil)
+
+	// The session is logged out after Authenticate read it as active but
+	// before it was cached.
+	sessionRepo.On("GetByID", uint(5)).Return(activeSession(5, 1), nil).Run(func(mock.Arguments) {
+		assert.NoError(t, authService.Logout(1, 5))
+	}).Once()
+	_, err := authService.Authenticate(tokens.AccessToken)
+	assert.NoError(t, err)
+
+	revokedAt := time.Now()
+	sessionRepo.On("GetByID", uint(5)).Return(&models.Session{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+	_, err = authService.Authenticate(tokens.AccessToken)
+	assert.ErrorIs(t, err, service.ErrSessionRevoked)
+	sessionRepo.AssertNumberOfCalls(t, "GetByID", 2)
+}
+
+func TestAuthService_RevokeSession(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	var created *models.Session
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	userRepo.On("FindByLogin", "alice").Return(&models.User{Model: gorm.Model{ID: 1}, Login: "alice", PasswordHash: string(hashedPassword)}, nil)
+	sessionRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.Session)
+	}).Return(nil)
+
+	_, err := authService.Login("alice", "secret", strings.Repeat("ü", 200), "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", created.IP)
+	assert.LessOrEqual(t, len(created.UserAgent), 255)
+	assert.True(t, utf8.ValidString(created.UserAgent))
+	assert.False(t, created.LastSeenAt.IsZero())
+	assert.True(t, created.ExpiresAt.After(time.Now().Add(23*time.Hour)))
+
+	sessionRepo.On("GetByID", uint(6)).Return(activeSession(6, 2), nil)
+	sessionRepo.On("GetByID", uint(7)).Return(activeSession(7, 1), nil)
+	sessionRepo.On("Revoke", uint(7)).Return(nil)
+
+	// Sessions of other users look the same as missing ones.
+	assert.ErrorIs(t, authService.RevokeSession(1, 6), service.ErrNotFound)
+	assert.NoError(t, authService.RevokeSession(1, 7))
+	sessionRepo.AssertNotCalled(t, "Revoke", uint(6))
+
+	now := time.Now()
+	session := models.Session{ID: 7, UserID: 1, UserAgent: "Firefox", LastSeenAt: now}
+	assert.True(t, session.ToPublic(7).Current)
+	assert.False(t, session.ToPublic(5).Current)
+}
+
+func TestAuthService_SessionExpiry(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	tokens, stored := loginSession(t, authService, userRepo, sessionRepo)
+	expired := models.Session{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}
+	sessionRepo.On("GetByID", uint(5)).Return(&expired, nil)
+
+	_, err := authService.Authenticate(tokens.AccessToken)
+	assert.ErrorIs(t, err, service.ErrSessionRevoked)
+
+	stored.Session = expired
+	sessionRepo.On("GetRefreshToken", stored.TokenHash).Return(stored, nil)
+	_, err = authService.Refresh(tokens.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	sessionRepo.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything)
+
+	sessionRepo.On("DeleteExpired", mock.AnythingOfType("time.Time")).Return(int64(3), nil)
+	pruned, err := authService.PruneSessions()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), pruned)
+}
+
+var resetLinkPattern = regexp.MustCompile(`/reset-password\?token=([A-Za-z0-9_-]+)`)
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("old-secret"), bcrypt.MinCost)
+	user := &models.User{Model: gorm.Model{ID: 1}, Login: "alice", PasswordHash: string(hashedPassword)}
+	userRepo.On("GetByID", uint(1)).Return(user, nil)
+	userRepo.On("Update", user, []string{"password_hash"}).Return(nil)
+	sessionRepo.On("RevokeOthers", uint(1), uint(5)).Return(nil)
+
+	err := authService.ChangePassword(1, 5, "wrong", "new-secret")
+	assert.ErrorIs(t, err, service.ErrWrongPassword)
+	userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	assert.NoError(t, authService.ChangePassword(1, 5, "old-secret", "new-secret"))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("new-secret")))
+	sessionRepo.AssertCalled(t, "RevokeOthers", uint(1), uint(5))
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	resetRepo := new(MockPasswordResetRepository)
+	mailer := mail.NewMemoryMailer()
+	cfg := testAuthConfig()
+	cfg.Server.PublicURL = "https://wishlist.example"
+	cfg.Auth.PasswordResetLifetime = time.Hour
+	authService := service.NewAuthService(userRepo, new(MockSessionRepository), resetRepo, nil, mailer, cfg)
+
+	email, unverified := "alice@example.com", "carol@example.com"
+	verifiedAt := time.Now()
+	userRepo.On("FindByEmail", "alice@example.com").Return(&models.User{Model: gorm.Model{ID: 1}, Login: "alice", Email: &email, EmailVerifiedAt: &verifiedAt}, nil)
+	userRepo.On("FindByEmail", "carol@example.com").Return(&models.User{Model: gorm.Model{ID: 3}, Login: "carol", Email: &unverified}, nil)
+	userRepo.On("FindByEmail", mock.Anything).Return((*models.User)(nil), gorm.ErrRecordNotFound)
+
+	// Unknown and unverified addresses look the same to the caller but get
+	// no email.
+	assert.NoError(t, authService.RequestPasswordReset(context.Background(), "bob@example.com"))
+	assert.NoError(t, authService.RequestPasswordReset(context.Background(), "carol@example.com"))
+	assert.Empty(t, mailer.Messages())
+
+	var stored *models.PasswordResetToken
+	resetRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.PasswordResetToken)
+	}).Return(nil)
+	assert.NoError(t, authService.RequestPasswordReset(context.Background(), " Alice@Example.com "))
+
+	msg, ok := mailer.Last()
+	assert.True(t, ok)
+	assert.Equal(t, "alice@example.com", msg.To)
+	assert.Contains(t, msg.Body, "https://wishlist.example/reset-password?token=")
+	match := resetLinkPattern.FindStringSubmatch(msg.Body)
+	assert.Len(t, match, 2)
+	token := match[1]
+	assert.NotEqual(t, token, stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+
+	resetRepo.On("GetByHash", stored.TokenHash).Return(stored, nil)
+	resetRepo.On("GetByHash", mock.Anything).Return((*models.PasswordResetToken)(nil), gorm.ErrRecordNotFound)
+	resetRepo.On("Consume", stored, mock.Anything).Run(func(args mock.Arguments) {
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(args.String(1)), []byte("new-secret")))
+	}).Return(nil).Once()
+
+	assert.ErrorIs(t, authService.ResetPassword("forged", "new-secret"), service.ErrInvalidResetToken)
+	assert.NoError(t, authService.ResetPassword(token, "new-secret"))
+
+	// A second reset with the same token loses the race in the database.
+	resetRepo.On("Consume", stored, mock.Anything).Return(repository.ErrResetTokenUsed)
+	assert.ErrorIs(t, authService.ResetPassword(token, "other-secret"), service.ErrInvalidResetToken)
+
+	usedAt := time.Now()
+	stored.UsedAt = &usedAt
+	assert.ErrorIs(t, authService.ResetPassword(token, "other-secret"), service.ErrInvalidResetToken)
+
+	stored.UsedAt = nil
+	stored.ExpiresAt = time.Now().Add(-time.Minute)
+	assert.ErrorIs(t, authService.ResetPassword(token, "other-secret"), service.ErrInvalidResetToken)
+	resetRepo.AssertNumberOfCalls(t, "Consume", 2)
+}
+
+func TestAuthService_RegisterEmail(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	authService := service.NewAuthService(userRepo, new(MockSessionRepository), new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	taken := "alice@example.com"
+	userRepo.On("Exists", mock.Anything).Return(false, nil)
+	userRepo.On("FindByEmail", "alice@example.com").Return(&models.User{Model: gorm.Model{ID: 1}, Email: &taken}, nil)
+	userRepo.On("FindByEmail", mock.Anything).Return((*models.User)(nil), gorm.ErrRecordNotFound)
+	userRepo.On("Create", mock.Anything).Return(nil)
+
+	_, err := authService.Register("alice2", "secret", "ALICE@example.com")
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+
+	created, err := authService.Register("bob", "secret", " Bob@Example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "bob@example.com", *created.Email)
+	assert.False(t, created.EmailVerified())
+
+	created, err = authService.Register("carol", "secret", "")
+	assert.NoError(t, err)
+	assert.Nil(t, created.Email)
+}
+
+func TestAuthService_RegisterRace(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	authService := service.NewAuthService(userRepo, new(MockSessionRepository), new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+
+	// Both checks pass, but another registration wins the insert.
+	userRepo.On("Exists", "dave").Return(false, nil).Once()
+	userRepo.On("Exists", "dave").Return(true, nil)
+	userRepo.On("Exists", "erin").Return(false, nil)
+	userRepo.On("FindByEmail", mock.Anything).Return((*models.User)(nil), gorm.ErrRecordNotFound)
+	userRepo.On("Create", mock.Anything).Return(gorm.ErrDuplicatedKey)
+
+	_, err := authService.Register("dave", "secret", "dave@example.com")
+	assert.ErrorIs(t, err, service.ErrUserExists)
+
+	_, err = authService.Register("erin", "secret", "shared@example.com")
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+}
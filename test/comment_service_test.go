@@ -0,0 +1,150 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/pagination"
+)
+
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) Create(comment *models.WishComment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) GetByID(id uint) (*models.WishComment, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.WishComment), args.Error(1)
+}
+
+func (m *MockCommentRepository) Update(comment *models.WishComment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) GetByWishID(wishID uint, cursor *pagination.Cursor, limit int) ([]models.WishComment, error) {
+	args := m.Called(wishID, cursor, limit)
+	return args.Get(0).([]models.WishComment), args.Error(1)
+}
+
+func TestCommentService_HiddenFromOwner(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	commentRepo := new(MockCommentRepository)
+	commentService := service.NewCommentService(commentRepo, wishRepo, newAccessPolicy())
+
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Visibility: models.VisibilityPublic}, nil)
+	wishRepo.On("GetByID", uint(2)).Return(&models.Wish{Model: gorm.Model{ID: 2}, UserID: 1, Visibility: models.VisibilityFriends}, nil)
+
+	_, _, err := commentService.GetByWishID(1, 1, "", 0)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	_, err = commentService.Create(1, 1, "Who is getting the shoes?")
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	// Gifters who cannot see the wish cannot see its thread either.
+	_, _, err = commentService.GetByWishID(2, 2, "", 0)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	commentRepo.AssertNotCalled(t, "GetByWishID", mock.Anything, mock.Anything, mock.Anything)
+
+	commentRepo.On("Create", mock.Anything).Return(nil)
+	comment, err := commentService.Create(2, 1, "  Does anyone know her size? ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Does anyone know her size?", comment.Body)
+
+	_, err = commentService.Create(2, 1, "   ")
+	assert.ErrorIs(t, err, service.ErrInvalidComment)
+}
+
+func TestCommentService_Paging(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	commentRepo := new(MockCommentRepository)
+	commentService := service.NewCommentService(commentRepo, wishRepo, newAccessPolicy())
+
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Visibility: models.VisibilityPublic}, nil)
+	commentRepo.On("GetByWishID", uint(1), (*pagination.Cursor)(nil), 3).Return([]models.WishComment{
+		{Model: gorm.Model{ID: 4}}, {Model: gorm.Model{ID: 6}}, {Model: gorm.Model{ID: 9}},
+	}, nil)
+	commentRepo.On("GetByWishID", uint(1), &pagination.Cursor{ID: 6}, 3).Return([]models.WishComment{
+		{Model: gorm.Model{ID: 9}},
+	}, nil)
+
+	comments, next, err := commentService.GetByWishID(2, 1, "", 2)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+	assert.Equal(t, pagination.Encode(pagination.Cursor{ID: 6}), next)
+
+	comments, next, err = commentService.GetByWishID(2, 1, next, 2)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.Empty(t, next)
+}
+
+func TestCommentService_EditAndModerate(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	commentRepo := new(MockCommentRepository)
+	commentService := service.NewCommentService(commentRepo, wishRepo, newAccessPolicy())
+
+	coOrganizer := uint(3)
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{
+		Model:      gorm.Model{ID: 1},
+		UserID:     1,
+		Visibility: models.VisibilityPublic,
+		Wishlist:   &models.Wishlist{Visibility: models.VisibilityPublic, CoOrganizerID: &coOrganizer},
+	}, nil)
+	commentRepo.On("GetByID", uint(5)).Return(&models.WishComment{Model: gorm.Model{ID: 5}, WishID: 1, UserID: 2, Body: "M or L?"}, nil)
+	commentRepo.On("Update", mock.Anything).Return(nil)
+	commentRepo.On("Delete", uint(5)).Return(nil)
+
+	_, err := commentService.Update(3, 5, "XL")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	err = commentService.Delete(4, 5)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	// The owner must not learn that the comment exists.
+	err = commentService.Delete(1, 5)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	commentRepo.AssertNotCalled(t, "Delete", mock.Anything)
+
+	comment, err := commentService.Update(2, 5, "L, she said so last week")
+	assert.NoError(t, err)
+	assert.NotNil(t, comment.EditedAt)
+
+	assert.NoError(t, commentService.Delete(3, 5))
+	commentRepo.AssertCalled(t, "Delete", uint(5))
+}
+
+func TestWishlistService_SetCoOrganizer(t *testing.T) {
+	wishlistRepo := new(MockWishlistRepository)
+	userRepo := new(MockUserRepository)
+	wishlistService := service.NewWishlistService(wishlistRepo, new(MockWishRepository), userRepo, newAccessPolicy())
+
+	wishlistRepo.On("GetByID", uint(1)).Return(&models.Wishlist{Model: gorm.Model{ID: 1}, UserID: 1}, nil)
+	wishlistRepo.On("Update", mock.Anything).Return(nil)
+	userRepo.On("FindByLogin", "owner").Return(&models.User{Model: gorm.Model{ID: 1}, Login: "owner"}, nil)
+	userRepo.On("FindByLogin", "alice").Return(&models.User{Model: gorm.Model{ID: 3}, Login: "alice"}, nil)
+
+	_, err := wishlistService.SetCoOrganizer(2, 1, "alice")
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	_, err = wishlistService.SetCoOrganizer(1, 1, "owner")
+	assert.ErrorIs(t, err, service.ErrSelfCoOrganizer)
+
+	wishlist, err := wishlistService.SetCoOrganizer(1, 1, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), *wishlist.CoOrganizerID)
+	assert.Equal(t, "alice", wishlist.ToPublic().CoOrganizer.Login)
+
+	wishlist, err = wishlistService.SetCoOrganizer(1, 1, "")
+	assert.NoError(t, err)
+	assert.Nil(t, wishlist.CoOrganizerID)
+}
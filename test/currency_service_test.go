@@ -0,0 +1,85 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/pagination"
+)
+
+type MockExchangeRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRateRepository) GetAll() ([]models.ExchangeRate, error) {
+	args := m.Called()
+	return args.Get(0).([]models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRateRepository) ReplaceAll(rates []models.ExchangeRate) error {
+	args := m.Called(rates)
+	return args.Error(0)
+}
+
+func TestCurrencyService_LoadRatesRequiresAdmin(t *testing.T) {
+	rateRepo := new(MockExchangeRateRepository)
+	userRepo := new(MockUserRepository)
+	currencyService := service.NewCurrencyService(rateRepo, userRepo, new(MockWishRepository), newAccessPolicy(), "EUR")
+
+	userRepo.On("GetByID", uint(1)).Return(&models.User{Model: gorm.Model{ID: 1}}, nil)
+	userRepo.On("GetByID", uint(2)).Return(&models.User{Model: gorm.Model{ID: 2}, IsAdmin: true}, nil)
+
+	_, err := currencyService.LoadRates(1, strings.NewReader("EUR,1\n"))
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	_, err = currencyService.LoadRates(2, strings.NewReader("EUR,abc\n"))
+	assert.ErrorIs(t, err, service.ErrInvalidRates)
+	rateRepo.AssertNotCalled(t, "ReplaceAll", mock.Anything)
+
+	rates := []models.ExchangeRate{{Currency: "EUR", Rate: "1"}}
+	rateRepo.On("ReplaceAll", rates).Return(nil)
+	rateRepo.On("GetAll").Return(rates, nil)
+	loaded, err := currencyService.LoadRates(2, strings.NewReader("EUR,1\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, rates, loaded)
+}
+
+func TestCurrencyService_TotalInPreferredCurrency(t *testing.T) {
+	rateRepo := new(MockExchangeRateRepository)
+	userRepo := new(MockUserRepository)
+	wishRepo := new(MockWishRepository)
+	currencyService := service.NewCurrencyService(rateRepo, userRepo, wishRepo, newAccessPolicy(), "EUR")
+
+	userRepo.On("GetByID", uint(1)).Return(&models.User{Model: gorm.Model{ID: 1}, Currency: "USD"}, nil)
+	userRepo.On("FindByLogin", "owner").Return(&models.User{Model: gorm.Model{ID: 1}, Login: "owner"}, nil)
+	wishRepo.On("GetByUsername", "owner", models.AccessOwner.Visibilities(), models.WishFilter{}, (*pagination.Cursor)(nil), 0).Return([]models.Wish{
+		{PriceMinor: 1000, PriceMaxMinor: 1000, Currency: "EUR", Quantity: 3, Received: 1},
+		{PriceMinor: 550, PriceMaxMinor: 1100, Currency: "USD", Quantity: 1},
+		{PriceUnknown: true, Quantity: 1},
+		{Quantity: 1},
+	}, (*pagination.Cursor)(nil), nil)
+	rateRepo.On("GetAll").Return([]models.ExchangeRate{
+		{Currency: "EUR", Rate: "1"},
+		{Currency: "USD", Rate: "1.1"},
+	}, nil)
+
+	total, err := currencyService.Total(1, "owner", "")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.WishTotal{Min: "27.50", Max: "33.00", Currency: "USD", Wishes: 3, Unknown: 1}, total)
+
+	total, err = currencyService.Total(1, "owner", "eur")
+	assert.NoError(t, err)
+	assert.Equal(t, "25.00", total.Min)
+	assert.Equal(t, "30.00", total.Max)
+
+	_, err = currencyService.Total(1, "owner", "GBP")
+	assert.ErrorIs(t, err, service.ErrNoExchangeRate)
+
+	_, err = currencyService.Total(1, "owner", "XYZ")
+	assert.ErrorIs(t, err, service.ErrInvalidCurrency)
+}
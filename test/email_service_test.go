@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/mail"
+)
+
+var verifyLinkPattern = regexp.MustCompile(`/verify-email\?token=([A-Za-z0-9_.-]+)`)
+
+func newEmailService(userRepo *MockUserRepository, mailer mail.Mailer) *service.EmailService {
+	cfg := testAuthConfig()
+	cfg.Server.PublicURL = "https://wishlist.example"
+	cfg.Auth.EmailVerificationLifetime = 24 * time.Hour
+	cfg.Auth.EmailVerificationResendInterval = time.Minute
+	return service.NewEmailService(userRepo, mailer, cfg)
+}
+
+func verificationToken(t *testing.T, mailer *mail.MemoryMailer) string {
+	msg, ok := mailer.Last()
+	assert.True(t, ok)
+	match := verifyLinkPattern.FindStringSubmatch(msg.Body)
+	if !assert.Len(t, match, 2) {
+		t.FailNow()
+	}
+	return match[1]
+}
+
+func TestEmailService_VerifyAndResend(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	mailer := mail.NewMemoryMailer()
+	emailService := newEmailService(userRepo, mailer)
+
+	email := "alice@example.com"
+	user := &models.User{Model: gorm.Model{ID: 1}, Login: "alice", Email: &email}
+	userRepo.On("GetByID", uint(1)).Return(user, nil)
+	userRepo.On("Update", user, []string{"verification_sent_at"}).Return(nil)
+	userRepo.On("Update", user, []string{"email_verified_at"}).Return(nil)
+
+	assert.NoError(t, emailService.SendInitialVerification(context.Background(), user))
+	assert.Equal(t, "alice@example.com", mailer.Messages()[0].To)
+	assert.NotNil(t, user.VerificationSentAt)
+
+	// Resending right away is throttled.
+	err := emailService.SendVerification(context.Background(), 1)
+	assert.ErrorIs(t, err, service.ErrVerificationThrottled)
+	assert.Len(t, mailer.Messages(), 1)
+
+	sentAt := time.Now().Add(-2 * time.Minute)
+	user.VerificationSentAt = &sentAt
+	assert.NoError(t, emailService.SendVerification(context.Background(), 1))
+	assert.Len(t, mailer.Messages(), 2)
+
+	_, err = emailService.VerifiedEmail(1)
+	assert.ErrorIs(t, err, service.ErrEmailNotVerified)
+
+	assert.ErrorIs(t, emailService.Verify("forged"), service.ErrInvalidVerificationToken)
+	assert.NoError(t, emailService.Verify(verificationToken(t, mailer)))
+	assert.True(t, user.EmailVerified())
+
+	verified, err := emailService.VerifiedEmail(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", verified)
+
+	err = emailService.SendVerification(context.Background(), 1)
+	assert.ErrorIs(t, err, service.ErrEmailAlreadyVerified)
+}
+
+func TestEmailService_ChangeEmail(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	mailer := mail.NewMemoryMailer()
+	emailService := newEmailService(userRepo, mailer)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	email, taken := "alice@example.com", "bob@example.com"
+	verifiedAt := time.Now()
+	user := &models.User{Model: gorm.Model{ID: 1}, Login: "alice", PasswordHash: string(hashedPassword), Email: &email, EmailVerifiedAt: &verifiedAt}
+	userRepo.On("GetByID", uint(1)).Return(user, nil)
+	userRepo.On("Update", user, mock.Anything).Return(nil)
+	userRepo.On("FindByEmail", "bob@example.com").Return(&models.User{Model: gorm.Model{ID: 2}, Email: &taken}, nil)
+	userRepo.On("FindByEmail", mock.Anything).Return((*models.User)(nil), gorm.ErrRecordNotFound)
+
+	_, err := emailService.ChangeEmail(context.Background(), 1, "wrong", "alice@new.example")
+	assert.ErrorIs(t, err, service.ErrWrongPassword)
+	_, err = emailService.ChangeEmail(context.Background(), 1, "secret", "Bob@example.com")
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+	assert.True(t, user.EmailVerified())
+
+	changed, err := emailService.ChangeEmail(context.Background(), 1, "secret", "alice@new.example")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@new.example", *changed.Email)
+	assert.False(t, changed.EmailVerified())
+	assert.Equal(t, "alice@new.example", mailer.Messages()[0].To)
+	firstLink := verificationToken(t, mailer)
+
+	// Changing again within the resend interval would mail another address.
+	_, err = emailService.ChangeEmail(context.Background(), 1, "secret", "alice@other.example")
+	assert.ErrorIs(t, err, service.ErrVerificationThrottled)
+
+	sentAt := time.Now().Add(-2 * time.Minute)
+	user.VerificationSentAt = &sentAt
+	_, err = emailService.ChangeEmail(context.Background(), 1, "secret", "alice@other.example")
+	assert.NoError(t, err)
+
+	// The link for the previous address no longer verifies anything.
+	assert.ErrorIs(t, emailService.Verify(firstLink), service.ErrInvalidVerificationToken)
+	assert.NoError(t, emailService.Verify(verificationToken(t, mailer)))
+	assert.Equal(t, "alice@other.example", *user.Email)
+	assert.True(t, user.EmailVerified())
+}
+
+func TestEmailService_ChangeEmailRace(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	mailer := mail.NewMemoryMailer()
+	emailService := newEmailService(userRepo, mailer)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	user := &models.User{Model: gorm.Model{ID: 1}, Login: "alice", PasswordHash: string(hashedPassword)}
+	userRepo.On("GetByID", uint(1)).Return(user, nil)
+	userRepo.On("FindByEmail", "bob@example.com").Return((*models.User)(nil), gorm.ErrRecordNotFound)
+	// Another account takes the address between the check and the write.
+	userRepo.On("Update", user, []string{"email", "email_verified_at"}).Return(gorm.ErrDuplicatedKey)
+
+	_, err := emailService.ChangeEmail(context.Background(), 1, "secret", "bob@example.com")
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+	assert.Empty(t, mailer.Messages())
+}
+
+func TestEmailService_RejectsAccessTokens(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), testAuthConfig())
+	emailService := newEmailService(userRepo, mail.NewMemoryMailer())
+
+	tokens, _ := loginSession(t, authService, userRepo, sessionRepo)
+	assert.ErrorIs(t, emailService.Verify(tokens.AccessToken), service.ErrInvalidVerificationToken)
+}
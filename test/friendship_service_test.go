@@ -0,0 +1,153 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/pagination"
+)
+
+type MockFriendshipRepository struct {
+	mock.Mock
+}
+
+func (m *MockFriendshipRepository) Create(friendship *models.Friendship) error {
+	args := m.Called(friendship)
+	return args.Error(0)
+}
+
+func (m *MockFriendshipRepository) GetByID(id uint) (*models.Friendship, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Friendship), args.Error(1)
+}
+
+func (m *MockFriendshipRepository) GetBetween(userID, otherID uint) (*models.Friendship, error) {
+	args := m.Called(userID, otherID)
+	friendship, _ := args.Get(0).(*models.Friendship)
+	return friendship, args.Error(1)
+}
+
+func (m *MockFriendshipRepository) Update(friendship *models.Friendship) error {
+	args := m.Called(friendship)
+	return args.Error(0)
+}
+
+func (m *MockFriendshipRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockFriendshipRepository) AreFriends(userID, otherID uint) (bool, error) {
+	args := m.Called(userID, otherID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFriendshipRepository) GetFriends(userID uint) ([]models.Friendship, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Friendship), args.Error(1)
+}
+
+func (m *MockFriendshipRepository) GetIncoming(userID uint) ([]models.Friendship, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Friendship), args.Error(1)
+}
+
+func (m *MockFriendshipRepository) GetOutgoing(userID uint) ([]models.Friendship, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Friendship), args.Error(1)
+}
+
+// newAccessPolicy returns a policy under which nobody is friends with anybody.
+func newAccessPolicy() *service.AccessPolicy {
+	friendshipRepo := new(MockFriendshipRepository)
+	friendshipRepo.On("AreFriends", mock.Anything, mock.Anything).Return(false, nil)
+	return service.NewAccessPolicy(friendshipRepo)
+}
+
+func TestAccessPolicy_Friends(t *testing.T) {
+	friendshipRepo := new(MockFriendshipRepository)
+	friendshipRepo.On("AreFriends", uint(2), uint(1)).Return(true, nil)
+	friendshipRepo.On("AreFriends", uint(3), uint(1)).Return(false, nil)
+	policy := service.NewAccessPolicy(friendshipRepo)
+
+	wish := &models.Wish{UserID: 1, Visibility: models.VisibilityFriends}
+
+	visible, err := policy.CanSeeWish(2, wish)
+	assert.NoError(t, err)
+	assert.True(t, visible)
+
+	visible, err = policy.CanSeeWish(3, wish)
+	assert.NoError(t, err)
+	assert.False(t, visible)
+
+	visible, err = policy.CanSeeWish(0, wish)
+	assert.NoError(t, err)
+	assert.False(t, visible)
+}
+
+func TestFriendshipService_SendRequestAcceptsReverse(t *testing.T) {
+	friendshipRepo := new(MockFriendshipRepository)
+	userRepo := new(MockUserRepository)
+	friendshipService := service.NewFriendshipService(friendshipRepo, userRepo, new(MockWishRepository))
+
+	userRepo.On("FindByLogin", "alice").Return(&models.User{Model: gorm.Model{ID: 2}, Login: "alice"}, nil)
+	pending := &models.Friendship{ID: 9, RequesterID: 2, AddresseeID: 1, Status: models.FriendshipPending}
+	friendshipRepo.On("GetBetween", uint(1), uint(2)).Return(pending, nil)
+	friendshipRepo.On("Update", pending).Return(nil)
+
+	friendship, err := friendshipService.SendRequest(1, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, models.FriendshipAccepted, friendship.Status)
+	assert.Equal(t, "alice", friendship.ToPublicFor(1).User.Login)
+	friendshipRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestFriendshipService_RemoveByFriendshipID(t *testing.T) {
+	friendshipRepo := new(MockFriendshipRepository)
+	friendshipService := service.NewFriendshipService(friendshipRepo, new(MockUserRepository), new(MockWishRepository))
+
+	friendshipRepo.On("GetByID", uint(9)).Return(&models.Friendship{ID: 9, RequesterID: 2, AddresseeID: 1, Status: models.FriendshipAccepted}, nil)
+	friendshipRepo.On("GetByID", uint(10)).Return(&models.Friendship{ID: 10, RequesterID: 1, AddresseeID: 3, Status: models.FriendshipPending}, nil)
+	friendshipRepo.On("Delete", uint(9)).Return(nil)
+
+	assert.ErrorIs(t, friendshipService.Remove(3, 9), service.ErrNotFound)
+	assert.ErrorIs(t, friendshipService.Remove(1, 10), service.ErrNotFound)
+	friendshipRepo.AssertNotCalled(t, "Delete", mock.Anything)
+
+	assert.NoError(t, friendshipService.Remove(1, 9))
+	friendshipRepo.AssertCalled(t, "Delete", uint(9))
+}
+
+func TestFriendshipService_SendRequestToSelf(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	friendshipService := service.NewFriendshipService(new(MockFriendshipRepository), userRepo, new(MockWishRepository))
+
+	userRepo.On("FindByLogin", "me").Return(&models.User{Model: gorm.Model{ID: 1}, Login: "me"}, nil)
+
+	_, err := friendshipService.SendRequest(1, "me")
+	assert.ErrorIs(t, err, service.ErrSelfFriendship)
+}
+
+func TestFriendshipService_FeedPagination(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	friendshipService := service.NewFriendshipService(new(MockFriendshipRepository), new(MockUserRepository), wishRepo)
+
+	wishes := []models.Wish{{Model: gorm.Model{ID: 3}}, {Model: gorm.Model{ID: 2}}, {Model: gorm.Model{ID: 1}}}
+	wishRepo.On("GetFeed", uint(1), (*pagination.Cursor)(nil), 3).Return(wishes, nil)
+
+	page, next, err := friendshipService.Feed(1, "", 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+	assert.NotEmpty(t, next)
+
+	cursor, err := pagination.Decode(next)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), cursor.ID)
+
+	_, _, err = friendshipService.Feed(1, "not-a-cursor", 2)
+	assert.ErrorIs(t, err, service.ErrInvalidCursor)
+}
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/pagination"
+	"wishlist-app/pkg/secretsanta"
+)
+
+type MockGiftExchangeRepository struct {
+	mock.Mock
+}
+
+func (m *MockGiftExchangeRepository) Create(exchange *models.GiftExchange) error {
+	args := m.Called(exchange)
+	return args.Error(0)
+}
+
+func (m *MockGiftExchangeRepository) GetByID(id uint) (*models.GiftExchange, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.GiftExchange), args.Error(1)
+}
+
+func (m *MockGiftExchangeRepository) Update(exchange *models.GiftExchange) error {
+	args := m.Called(exchange)
+	return args.Error(0)
+}
+
+func (m *MockGiftExchangeRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockGiftExchangeRepository) GetByUserID(userID uint) ([]models.GiftExchange, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.GiftExchange), args.Error(1)
+}
+
+func (m *MockGiftExchangeRepository) AddParticipant(participant *models.ExchangeParticipant) error {
+	args := m.Called(participant)
+	return args.Error(0)
+}
+
+func (m *MockGiftExchangeRepository) UpdateParticipant(participant *models.ExchangeParticipant) error {
+	args := m.Called(participant)
+	return args.Error(0)
+}
+
+func (m *MockGiftExchangeRepository) RemoveParticipant(exchangeID, userID uint) error {
+	args := m.Called(exchangeID, userID)
+	return args.Error(0)
+}
+
+func (m *MockGiftExchangeRepository) AddExclusion(exclusion *models.ExchangeExclusion) error {
+	args := m.Called(exclusion)
+	return args.Error(0)
+}
+
+func (m *MockGiftExchangeRepository) RemoveExclusion(exchangeID, exclusionID uint) error {
+	args := m.Called(exchangeID, exclusionID)
+	return args.Error(0)
+}
+
+func (m *MockGiftExchangeRepository) SaveDraw(exchange *models.GiftExchange) error {
+	args := m.Called(exchange)
+	return args.Error(0)
+}
+
+func (m *MockGiftExchangeRepository) ResetDraw(exchangeID uint) error {
+	args := m.Called(exchangeID)
+	return args.Error(0)
+}
+
+func newExchange(status models.ExchangeStatus, userIDs ...uint) *models.GiftExchange {
+	exchange := &models.GiftExchange{Model: gorm.Model{ID: 1}, OrganizerID: userIDs[0], Status: status}
+	for _, userID := range userIDs {
+		exchange.Participants = append(exchange.Participants, models.ExchangeParticipant{
+			ID:         userID,
+			ExchangeID: 1,
+			UserID:     userID,
+			Status:     models.ParticipantJoined,
+		})
+	}
+	return exchange
+}
+
+func TestSecretSanta_DrawRespectsExclusions(t *testing.T) {
+	participants := []uint{1, 2, 3, 4}
+	forbidden := func(giver, recipient uint) bool {
+		return (giver == 1 && recipient == 2) || (giver == 2 && recipient == 1)
+	}
+
+	for seed := uint64(0); seed < 50; seed++ {
+		assignment, err := secretsanta.Draw(participants, forbidden, rand.New(rand.NewPCG(seed, seed)))
+		assert.NoError(t, err)
+		assert.Len(t, assignment, len(participants))
+
+		received := map[uint]bool{}
+		for giver, recipient := range assignment {
+			assert.NotEqual(t, giver, recipient)
+			assert.False(t, forbidden(giver, recipient))
+			received[recipient] = true
+		}
+		assert.Len(t, received, len(participants))
+	}
+}
+
+func TestSecretSanta_DrawFailsWithoutValidAssignment(t *testing.T) {
+	// Nobody may give to 3, so 3 can never receive a gift.
+	participants := []uint{1, 2, 3}
+	forbidden := func(giver, recipient uint) bool {
+		return recipient == 3
+	}
+
+	_, err := secretsanta.Draw(participants, forbidden, rand.New(rand.NewPCG(1, 1)))
+	assert.ErrorIs(t, err, secretsanta.ErrNoAssignment)
+
+	_, err = secretsanta.Draw([]uint{1}, func(uint, uint) bool { return false }, rand.New(rand.NewPCG(1, 1)))
+	assert.ErrorIs(t, err, secretsanta.ErrNoAssignment)
+}
+
+func TestSecretSanta_DrawLargeInfeasibleFailsQuickly(t *testing.T) {
+	// Participants 1..21 may only give to 22..40: 21 givers share 19
+	// recipients, so no assignment exists although everybody has options.
+	participants := make([]uint, 40)
+	for i := range participants {
+		participants[i] = uint(i + 1)
+	}
+	forbidden := func(giver, recipient uint) bool {
+		return giver <= 21 && recipient <= 21
+	}
+
+	start := time.Now()
+	_, err := secretsanta.Draw(participants, forbidden, rand.New(rand.NewPCG(1, 1)))
+	assert.ErrorIs(t, err, secretsanta.ErrNoAssignment)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestGiftExchangeService_DrawNoValidAssignment(t *testing.T) {
+	exchangeRepo := new(MockGiftExchangeRepository)
+	exchangeService := service.NewGiftExchangeService(exchangeRepo, new(MockUserRepository), new(MockWishRepository), newAccessPolicy())
+
+	exchange := newExchange(models.ExchangeOpen, 1, 2)
+	exchange.Exclusions = []models.ExchangeExclusion{{GiverID: 1, RecipientID: 2}}
+	exchangeRepo.On("GetByID", uint(1)).Return(exchange, nil)
+
+	err := exchangeService.Draw(1, 1)
+	assert.ErrorIs(t, err, service.ErrNoValidAssignment)
+	exchangeRepo.AssertNotCalled(t, "SaveDraw", mock.Anything)
+}
+
+func TestGiftExchangeService_DrawAndRedraw(t *testing.T) {
+	exchangeRepo := new(MockGiftExchangeRepository)
+	exchangeService := service.NewGiftExchangeService(exchangeRepo, new(MockUserRepository), new(MockWishRepository), newAccessPolicy())
+
+	exchange := newExchange(models.ExchangeDrawn, 1, 2, 3)
+	exchange.Participants = append(exchange.Participants, models.ExchangeParticipant{
+		ID: 4, ExchangeID: 1, UserID: 4, Status: models.ParticipantInvited,
+	})
+	exchangeRepo.On("GetByID", uint(1)).Return(exchange, nil)
+	exchangeRepo.On("SaveDraw", exchange).Return(nil)
+
+	err := exchangeService.Draw(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ExchangeDrawn, exchange.Status)
+	for _, participant := range exchange.Participants {
+		if participant.Status == models.ParticipantInvited {
+			assert.Nil(t, participant.RecipientID)
+			continue
+		}
+		assert.NotNil(t, participant.RecipientID)
+		assert.NotEqual(t, participant.UserID, *participant.RecipientID)
+		assert.NotEqual(t, uint(4), *participant.RecipientID)
+	}
+
+	err = exchangeService.Draw(2, 1)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	exchange.Status = models.ExchangeRevealed
+	err = exchangeService.Draw(1, 1)
+	assert.ErrorIs(t, err, service.ErrExchangeRevealed)
+}
+
+func TestGiftExchangeService_JoinDiscardsDraw(t *testing.T) {
+	exchangeRepo := new(MockGiftExchangeRepository)
+	exchangeService := service.NewGiftExchangeService(exchangeRepo, new(MockUserRepository), new(MockWishRepository), newAccessPolicy())
+
+	exchange := newExchange(models.ExchangeDrawn, 1, 2)
+	exchange.Participants = append(exchange.Participants, models.ExchangeParticipant{
+		ID: 3, ExchangeID: 1, UserID: 3, Status: models.ParticipantInvited,
+	})
+	exchangeRepo.On("GetByID", uint(1)).Return(exchange, nil)
+	exchangeRepo.On("UpdateParticipant", mock.Anything).Return(nil)
+	exchangeRepo.On("ResetDraw", uint(1)).Return(nil)
+
+	err := exchangeService.Join(3, 1)
+	assert.NoError(t, err)
+	exchangeRepo.AssertCalled(t, "ResetDraw", uint(1))
+
+	_, err = exchangeService.GetByID(5, 1)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestGiftExchangeService_AssignmentOnlyAfterReveal(t *testing.T) {
+	exchangeRepo := new(MockGiftExchangeRepository)
+	wishRepo := new(MockWishRepository)
+	exchangeService := service.NewGiftExchangeService(exchangeRepo, new(MockUserRepository), wishRepo, newAccessPolicy())
+
+	recipient := &models.User{Model: gorm.Model{ID: 2}, Login: "bob"}
+	exchange := newExchange(models.ExchangeDrawn, 1, 2)
+	exchange.Participants[0].RecipientID = &recipient.ID
+	exchange.Participants[0].Recipient = recipient
+	exchangeRepo.On("GetByID", uint(1)).Return(exchange, nil)
+
+	_, _, _, err := exchangeService.Assignment(1, 1)
+	assert.ErrorIs(t, err, service.ErrExchangeNotRevealed)
+
+	exchange.Status = models.ExchangeRevealed
+	wishRepo.On("GetByUsername", "bob", models.AccessPublic.Visibilities(), models.WishFilter{}, (*pagination.Cursor)(nil), 0).Return([]models.Wish{
+		{Model: gorm.Model{ID: 7}, UserID: 2, Title: "Scarf"},
+	}, (*pagination.Cursor)(nil), nil)
+
+	_, got, wishes, err := exchangeService.Assignment(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", got.Login)
+	assert.Len(t, wishes, 1)
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"wishlist-app/internal/config"
+	"wishlist-app/pkg/money"
+)
+
+func TestMoney_ParseAndFormat(t *testing.T) {
+	amount, err := money.Parse("19.99", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1999), amount)
+
+	amount, err = money.Parse("0.1", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), amount)
+
+	amount, err = money.Parse("1500", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1500), amount)
+
+	amount, err = money.Parse("2.500", "KWD")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.500", money.Format(amount, "KWD"))
+
+	_, err = money.Parse("1.999", "EUR")
+	assert.ErrorIs(t, err, money.ErrTooPrecise)
+	_, err = money.Parse("-5", "EUR")
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+	_, err = money.Parse("1e3", "EUR")
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+	_, err = money.Parse("5", "XYZ")
+	assert.ErrorIs(t, err, money.ErrInvalidCurrency)
+
+	assert.Equal(t, "0.05", money.Format(5, "EUR"))
+	assert.Equal(t, "1500", money.Format(1500, "JPY"))
+}
+
+func TestMoney_ConvertWithRates(t *testing.T) {
+	parsed, err := money.ParseRates(strings.NewReader("currency,rate\nEUR,1\n# comment\nUSD,1.1\nJPY,160\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"EUR": "1", "USD": "1.1", "JPY": "160"}, parsed)
+
+	rates := money.Rates{}
+	for code, rate := range parsed {
+		rates[code], err = money.ParseRate(rate)
+		assert.NoError(t, err)
+	}
+
+	converted, err := rates.Convert(1000, "EUR", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1100), converted)
+
+	// 10.00 USD is 9.0909... EUR, rounded to the nearest cent.
+	converted, err = rates.Convert(1000, "USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(909), converted)
+
+	converted, err = rates.Convert(250, "EUR", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(400), converted)
+
+	_, err = rates.Convert(100, "EUR", "GBP")
+	assert.ErrorIs(t, err, money.ErrNoRate)
+
+	_, err = money.ParseRates(strings.NewReader("USD,-1\n"))
+	assert.Error(t, err)
+	_, err = money.ParseRates(strings.NewReader("ABC,1\n"))
+	assert.ErrorIs(t, err, money.ErrInvalidCurrency)
+}
+
+func TestConfig_DefaultCurrency(t *testing.T) {
+	t.Setenv("DEFAULT_CURRENCY", "jpy")
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "JPY", cfg.Money.DefaultCurrency)
+
+	// An unknown code would migrate existing prices with the wrong precision.
+	t.Setenv("DEFAULT_CURRENCY", "EUX")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "DEFAULT_CURRENCY")
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+)
+
+type MockOccasionRepository struct {
+	mock.Mock
+}
+
+func (m *MockOccasionRepository) Create(occasion *models.Occasion) error {
+	args := m.Called(occasion)
+	return args.Error(0)
+}
+
+func (m *MockOccasionRepository) GetByID(id uint) (*models.Occasion, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Occasion), args.Error(1)
+}
+
+func (m *MockOccasionRepository) Update(occasion *models.Occasion) error {
+	args := m.Called(occasion)
+	return args.Error(0)
+}
+
+func (m *MockOccasionRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockOccasionRepository) GetByUserID(userID uint, visibilities []models.Visibility) ([]models.Occasion, error) {
+	args := m.Called(userID, visibilities)
+	return args.Get(0).([]models.Occasion), args.Error(1)
+}
+
+func (m *MockOccasionRepository) Attach(occasionID, userID uint, wishIDs, wishlistIDs []uint) error {
+	args := m.Called(occasionID, userID, wishIDs, wishlistIDs)
+	return args.Error(0)
+}
+
+func (m *MockOccasionRepository) Detach(occasionID, userID uint, wishIDs, wishlistIDs []uint) error {
+	args := m.Called(occasionID, userID, wishIDs, wishlistIDs)
+	return args.Error(0)
+}
+
+func date(s string) time.Time {
+	t, _ := time.Parse(models.DateLayout, s)
+	return t
+}
+
+func TestOccasion_NextOccurrence(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	birthday := &models.Occasion{Date: date("1990-03-01"), Recurring: true, Timezone: "UTC"}
+	next, ok := birthday.NextOccurrence(now)
+	assert.True(t, ok)
+	assert.Equal(t, "2026-03-01", next.Format(models.DateLayout))
+
+	wedding := &models.Occasion{Date: date("2025-03-01"), Timezone: "UTC"}
+	_, ok = wedding.NextOccurrence(now)
+	assert.False(t, ok)
+
+	leap := &models.Occasion{Date: date("2000-02-29"), Recurring: true, Timezone: "UTC"}
+	next, _ = leap.NextOccurrence(now)
+	assert.Equal(t, "2026-02-28", next.Format(models.DateLayout))
+
+	today := &models.Occasion{Date: date("1990-03-10"), Recurring: true, Timezone: "UTC"}
+	days, ok := today.DaysUntil(now)
+	assert.True(t, ok)
+	assert.Equal(t, 0, days)
+}
+
+func TestOccasion_DaysUntilUsesTimezone(t *testing.T) {
+	// 22:00 UTC on March 9 is already March 10 in Tokyo.
+	now := time.Date(2025, time.March, 9, 22, 0, 0, 0, time.UTC)
+	occasion := &models.Occasion{Date: date("2025-03-11"), Timezone: "Asia/Tokyo"}
+
+	days, ok := occasion.DaysUntil(now)
+	assert.True(t, ok)
+	assert.Equal(t, 1, days)
+}
+
+func TestOccasionService_UpcomingHidesReservedWishes(t *testing.T) {
+	occasionRepo := new(MockOccasionRepository)
+	wishRepo := new(MockWishRepository)
+	userRepo := new(MockUserRepository)
+	occasionService := service.NewOccasionService(occasionRepo, wishRepo, userRepo, newAccessPolicy())
+
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	occasionID := uint(4)
+	userRepo.On("FindByLogin", "owner").Return(&models.User{Model: gorm.Model{ID: 1}, Login: "owner"}, nil)
+	occasionRepo.On("GetByUserID", uint(1), mock.Anything).Return([]models.Occasion{
+		{Model: gorm.Model{ID: occasionID}, UserID: 1, Date: date("1990-03-20"), Recurring: true, Timezone: "UTC"},
+		{Model: gorm.Model{ID: 5}, UserID: 1, Date: date("2020-01-01"), Timezone: "UTC"},
+	}, nil)
+	wishRepo.On("GetByOccasionIDs", []uint{occasionID}, mock.Anything).Return([]models.Wish{
+		{Model: gorm.Model{ID: 1}, UserID: 1, OccasionID: &occasionID},
+		{Model: gorm.Model{ID: 2}, UserID: 1, OccasionID: &occasionID, Reservations: []models.Reservation{{UserID: 3}}},
+	}, nil)
+
+	occasions, err := occasionService.Upcoming(2, "owner", 0, now)
+	assert.NoError(t, err)
+	assert.Len(t, occasions, 1)
+	assert.Len(t, occasions[0].Wishes, 1)
+	assert.Equal(t, uint(1), occasions[0].Wishes[0].ID)
+
+	occasions, err = occasionService.Upcoming(1, "owner", 0, now)
+	assert.NoError(t, err)
+	assert.Len(t, occasions[0].Wishes, 2)
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/internal/service"
+)
+
+type MockPledgeRepository struct {
+	mock.Mock
+}
+
+func (m *MockPledgeRepository) Create(pledge *models.Pledge) error {
+	args := m.Called(pledge)
+	return args.Error(0)
+}
+
+func (m *MockPledgeRepository) GetByID(id uint) (*models.Pledge, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Pledge), args.Error(1)
+}
+
+func (m *MockPledgeRepository) Update(pledge *models.Pledge) error {
+	args := m.Called(pledge)
+	return args.Error(0)
+}
+
+func (m *MockPledgeRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPledgeRepository) GetByUserID(userID uint) ([]models.Pledge, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Pledge), args.Error(1)
+}
+
+func TestPledgeService_PledgeWithoutPrice(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	pledgeRepo := new(MockPledgeRepository)
+	pledgeService := service.NewPledgeService(pledgeRepo, wishRepo, newAccessPolicy())
+
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Visibility: models.VisibilityPublic}, nil)
+
+	_, err := pledgeService.Pledge(2, 1, "10")
+	assert.ErrorIs(t, err, service.ErrNoPrice)
+	pledgeRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestPledgeService_PledgeExceedsPrice(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	pledgeRepo := new(MockPledgeRepository)
+	pledgeService := service.NewPledgeService(pledgeRepo, wishRepo, newAccessPolicy())
+
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, PriceMinor: 30000, PriceMaxMinor: 30000, Currency: "EUR", Visibility: models.VisibilityPublic}, nil)
+	pledgeRepo.On("Create", mock.Anything).Return(repository.ErrPledgeExceedsPrice)
+
+	_, err := pledgeService.Pledge(2, 1, "400")
+	assert.ErrorIs(t, err, service.ErrPledgeTooLarge)
+	pledgeRepo.AssertCalled(t, "Create", mock.MatchedBy(func(p *models.Pledge) bool {
+		return p.AmountMinor == 40000
+	}))
+
+	_, err = pledgeService.Pledge(2, 1, "12.345")
+	assert.ErrorIs(t, err, service.ErrInvalidAmount)
+}
+
+func TestWish_FundingProgress(t *testing.T) {
+	wish := &models.Wish{
+		Model:         gorm.Model{ID: 1},
+		UserID:        1,
+		PriceMinor:    30000,
+		PriceMaxMinor: 30000,
+		Currency:      "EUR",
+		Pledges: []models.Pledge{
+			{WishID: 1, UserID: 2, AmountMinor: 10000},
+			{WishID: 1, UserID: 3, AmountMinor: 15000},
+		},
+	}
+
+	assert.Nil(t, wish.ToPublicFor(1).Reservation)
+
+	status := wish.ToPublicFor(2).Reservation
+	assert.False(t, status.Reserved)
+	assert.Equal(t, "250.00", status.Pledged)
+	assert.Equal(t, 2, status.Contributors)
+	assert.Equal(t, "100.00", status.MyPledge)
+
+	wish.Pledges = append(wish.Pledges, models.Pledge{WishID: 1, UserID: 4, AmountMinor: 5000})
+	assert.True(t, wish.ToPublicFor(2).Reservation.Reserved)
+}
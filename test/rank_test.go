@@ -0,0 +1,34 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"wishlist-app/pkg/rank"
+)
+
+func TestRank_BetweenKeepsOrder(t *testing.T) {
+	keys := rank.Spread(3)
+	assert.True(t, keys[0] < keys[1] && keys[1] < keys[2])
+
+	// Repeatedly inserting at the same spot always finds room.
+	lower, upper := keys[0], keys[1]
+	for i := 0; i < 100; i++ {
+		key, err := rank.Between(lower, upper)
+		assert.NoError(t, err)
+		assert.Greater(t, key, lower)
+		assert.Less(t, key, upper)
+		upper = key
+	}
+
+	first, err := rank.Between("", keys[0])
+	assert.NoError(t, err)
+	assert.Less(t, first, keys[0])
+
+	last, err := rank.After(keys[2])
+	assert.NoError(t, err)
+	assert.Greater(t, last, keys[2])
+
+	_, err = rank.Between(keys[1], keys[0])
+	assert.ErrorIs(t, err, rank.ErrInvalidRange)
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/internal/service"
+)
+
+type MockReservationRepository struct {
+	mock.Mock
+}
+
+func (m *MockReservationRepository) Create(reservation *models.Reservation) error {
+	args := m.Called(reservation)
+	return args.Error(0)
+}
+
+func (m *MockReservationRepository) Delete(wishID, userID uint) (bool, error) {
+	args := m.Called(wishID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReservationRepository) GetByUserID(userID uint) ([]models.Reservation, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Reservation), args.Error(1)
+}
+
+func TestReservationService_ReserveOwnWish(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	reservationRepo := new(MockReservationRepository)
+	reservationService := service.NewReservationService(reservationRepo, wishRepo, newAccessPolicy())
+
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Visibility: models.VisibilityPublic}, nil)
+
+	_, err := reservationService.Reserve(1, 1, 1)
+	assert.ErrorIs(t, err, service.ErrOwnWish)
+	reservationRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestReservationService_ReserveConflict(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	reservationRepo := new(MockReservationRepository)
+	reservationService := service.NewReservationService(reservationRepo, wishRepo, newAccessPolicy())
+
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Visibility: models.VisibilityPublic}, nil)
+	reservationRepo.On("Create", mock.Anything).Return(repository.ErrAlreadyReserved)
+
+	_, err := reservationService.Reserve(2, 1, 1)
+	assert.ErrorIs(t, err, service.ErrAlreadyReserved)
+}
+
+func TestReservationService_ReservePrivateWish(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	reservationRepo := new(MockReservationRepository)
+	reservationService := service.NewReservationService(reservationRepo, wishRepo, newAccessPolicy())
+
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{
+		Model:      gorm.Model{ID: 1},
+		UserID:     1,
+		Visibility: models.VisibilityPublic,
+		Wishlist:   &models.Wishlist{Visibility: models.VisibilityPrivate},
+	}, nil)
+
+	_, err := reservationService.Reserve(2, 1, 1)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	reservationRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestWish_ReservationHiddenFromOwner(t *testing.T) {
+	wish := &models.Wish{
+		Model:        gorm.Model{ID: 1},
+		UserID:       1,
+		Reservations: []models.Reservation{{WishID: 1, UserID: 2}},
+	}
+
+	assert.Nil(t, wish.ToPublic().Reservation)
+	assert.Nil(t, wish.ToPublicFor(0).Reservation)
+	assert.Nil(t, wish.ToPublicFor(1).Reservation)
+	assert.Equal(t, &models.ReservationStatus{Reserved: true, ReservedByMe: true}, wish.ToPublicFor(2).Reservation)
+	assert.Equal(t, &models.ReservationStatus{Reserved: true}, wish.ToPublicFor(3).Reservation)
+}
+
+func TestWish_RemainingQuantity(t *testing.T) {
+	wish := &models.Wish{
+		Model:    gorm.Model{ID: 1},
+		UserID:   1,
+		Quantity: 6,
+		Received: 1,
+		Reservations: []models.Reservation{
+			{WishID: 1, UserID: 2, Quantity: 2},
+			{WishID: 1, UserID: 3, Quantity: 1},
+		},
+	}
+
+	assert.Equal(t, 2, wish.Remaining())
+	assert.False(t, wish.Reserved())
+	assert.Nil(t, wish.ToPublicFor(1).Remaining)
+
+	public := wish.ToPublicFor(2)
+	assert.Equal(t, 2, *public.Remaining)
+	assert.Equal(t, 2, public.Reservation.MyQuantity)
+	assert.False(t, public.Reservation.Reserved)
+
+	wish.Reservations = append(wish.Reservations, models.Reservation{WishID: 1, UserID: 4, Quantity: 2})
+	assert.True(t, wish.Reserved())
+	assert.Equal(t, 0, *wish.ToPublicFor(5).Remaining)
+}
+
+func TestReservationService_ReserveTooMany(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	reservationRepo := new(MockReservationRepository)
+	reservationService := service.NewReservationService(reservationRepo, wishRepo, newAccessPolicy())
+
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Quantity: 3, Visibility: models.VisibilityPublic}, nil)
+	reservationRepo.On("Create", mock.MatchedBy(func(reservation *models.Reservation) bool {
+		return reservation.Quantity == 5
+	})).Return(repository.ErrQuantityExceeded)
+
+	_, err := reservationService.Reserve(2, 1, 5)
+	assert.ErrorIs(t, err, service.ErrQuantityTooLarge)
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+)
+
+type MockShareLinkRepository struct {
+	mock.Mock
+}
+
+func (m *MockShareLinkRepository) Create(link *models.ShareLink) error {
+	args := m.Called(link)
+	return args.Error(0)
+}
+
+func (m *MockShareLinkRepository) GetByID(id uint) (*models.ShareLink, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.ShareLink), args.Error(1)
+}
+
+func (m *MockShareLinkRepository) GetByTokenHash(tokenHash string) (*models.ShareLink, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*models.ShareLink), args.Error(1)
+}
+
+func (m *MockShareLinkRepository) GetByWishlistID(wishlistID uint) ([]models.ShareLink, error) {
+	args := m.Called(wishlistID)
+	return args.Get(0).([]models.ShareLink), args.Error(1)
+}
+
+func (m *MockShareLinkRepository) Update(link *models.ShareLink) error {
+	args := m.Called(link)
+	return args.Error(0)
+}
+
+func TestShareLinkService_CreateAndResolve(t *testing.T) {
+	shareLinkRepo := new(MockShareLinkRepository)
+	wishlistRepo := new(MockWishlistRepository)
+	wishRepo := new(MockWishRepository)
+	shareLinkService := service.NewShareLinkService(shareLinkRepo, wishlistRepo, wishRepo, newAccessPolicy())
+
+	wishlist := &models.Wishlist{Model: gorm.Model{ID: 5}, UserID: 1, Visibility: models.VisibilityLink}
+	wishlistRepo.On("GetByID", uint(5)).Return(wishlist, nil)
+
+	var stored *models.ShareLink
+	shareLinkRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.ShareLink)
+	}).Return(nil)
+
+	_, token, err := shareLinkService.Create(1, 5, nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.NotEqual(t, token, stored.TokenHash)
+
+	shareLinkRepo.On("GetByTokenHash", stored.TokenHash).Return(stored, nil)
+	wishRepo.On("GetByWishlistID", uint(5), models.AccessLink.Visibilities()).Return([]models.Wish{{Title: "Shared"}}, nil)
+
+	resolved, err := shareLinkService.Resolve(0, token)
+	assert.NoError(t, err)
+	assert.Len(t, resolved.Wishes, 1)
+}
+
+func TestShareLinkService_ResolveKeepsAudiencesApart(t *testing.T) {
+	shareLinkRepo := new(MockShareLinkRepository)
+	wishlistRepo := new(MockWishlistRepository)
+	wishRepo := new(MockWishRepository)
+	friendshipRepo := new(MockFriendshipRepository)
+	friendshipRepo.On("AreFriends", uint(2), uint(1)).Return(true, nil)
+	shareLinkService := service.NewShareLinkService(shareLinkRepo, wishlistRepo, wishRepo, service.NewAccessPolicy(friendshipRepo))
+
+	shareLinkRepo.On("GetByTokenHash", mock.Anything).Return(&models.ShareLink{WishlistID: 5}, nil)
+	wishlistRepo.On("GetByID", uint(5)).Return(&models.Wishlist{Model: gorm.Model{ID: 5}, UserID: 1, Visibility: models.VisibilityPublic}, nil)
+
+	var granted []models.Visibility
+	wishRepo.On("GetByWishlistID", uint(5), mock.Anything).Run(func(args mock.Arguments) {
+		granted = args.Get(1).([]models.Visibility)
+	}).Return([]models.Wish{}, nil)
+
+	// Anonymous link holders see link-only wishes but not friends-only ones.
+	_, err := shareLinkService.Resolve(0, "token")
+	assert.NoError(t, err)
+	assert.Contains(t, granted, models.VisibilityLink)
+	assert.NotContains(t, granted, models.VisibilityFriends)
+	assert.NotContains(t, granted, models.VisibilityPrivate)
+
+	// Friends keep seeing friends-only wishes when they open the link.
+	_, err = shareLinkService.Resolve(2, "token")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []models.Visibility{models.VisibilityPublic, models.VisibilityFriends, models.VisibilityLink}, granted)
+}
+
+func TestShareLinkService_RotateRevoked(t *testing.T) {
+	shareLinkRepo := new(MockShareLinkRepository)
+	shareLinkService := service.NewShareLinkService(shareLinkRepo, new(MockWishlistRepository), new(MockWishRepository), newAccessPolicy())
+
+	revokedAt := time.Now().Add(-time.Hour)
+	shareLinkRepo.On("GetByID", uint(7)).Return(&models.ShareLink{ID: 7, UserID: 1, WishlistID: 5, TokenHash: "old", RevokedAt: &revokedAt}, nil)
+
+	_, _, err := shareLinkService.Rotate(1, 7, nil)
+	assert.ErrorIs(t, err, service.ErrShareLinkRevoked)
+	shareLinkRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestShareLinkService_ResolveExpired(t *testing.T) {
+	shareLinkRepo := new(MockShareLinkRepository)
+	shareLinkService := service.NewShareLinkService(shareLinkRepo, new(MockWishlistRepository), new(MockWishRepository), newAccessPolicy())
+
+	expired := time.Now().Add(-time.Hour)
+	shareLinkRepo.On("GetByTokenHash", mock.Anything).Return(&models.ShareLink{WishlistID: 5, ExpiresAt: &expired}, nil)
+
+	_, err := shareLinkService.Resolve(0, "token")
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestShareLinkService_CreateForeignWishlist(t *testing.T) {
+	wishlistRepo := new(MockWishlistRepository)
+	shareLinkService := service.NewShareLinkService(new(MockShareLinkRepository), wishlistRepo, new(MockWishRepository), newAccessPolicy())
+
+	wishlistRepo.On("GetByID", uint(5)).Return(&models.Wishlist{Model: gorm.Model{ID: 5}, UserID: 2}, nil)
+
+	_, _, err := shareLinkService.Create(1, 5, nil)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/pagination"
+)
+
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) Create(tag *models.Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) GetByID(id uint) (*models.Tag, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) Update(tag *models.Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) FindOrCreate(userID uint, names []string) ([]models.Tag, error) {
+	args := m.Called(userID, names)
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) GetCounts(userID uint) ([]models.TagCount, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.TagCount), args.Error(1)
+}
+
+func (m *MockTagRepository) GetFacets(userID uint, visibilities []models.Visibility) (*models.WishFacets, error) {
+	args := m.Called(userID, visibilities)
+	return args.Get(0).(*models.WishFacets), args.Error(1)
+}
+
+func TestTagService_CreateDuplicate(t *testing.T) {
+	tagRepo := new(MockTagRepository)
+	tagService := service.NewTagService(tagRepo, new(MockUserRepository), newAccessPolicy())
+
+	tagRepo.On("Create", &models.Tag{UserID: 1, Name: "books"}).Return(gorm.ErrDuplicatedKey)
+
+	_, err := tagService.Create(1, "  Books ")
+	assert.ErrorIs(t, err, service.ErrTagExists)
+
+	_, err = tagService.Create(1, "   ")
+	assert.ErrorIs(t, err, service.ErrInvalidTag)
+}
+
+func TestTagService_FacetsUseViewerAccess(t *testing.T) {
+	tagRepo := new(MockTagRepository)
+	userRepo := new(MockUserRepository)
+	tagService := service.NewTagService(tagRepo, userRepo, newAccessPolicy())
+
+	facets := &models.WishFacets{Tags: []models.TagCount{{Name: "books", Count: 2}}}
+	userRepo.On("FindByLogin", "owner").Return(&models.User{Model: gorm.Model{ID: 1}, Login: "owner"}, nil)
+	tagRepo.On("GetFacets", uint(1), models.AccessPublic.Visibilities()).Return(facets, nil)
+	tagRepo.On("GetFacets", uint(1), models.AccessOwner.Visibilities()).Return(facets, nil)
+
+	_, err := tagService.GetFacets(2, "owner")
+	assert.NoError(t, err)
+	_, err = tagService.GetFacets(1, "owner")
+	assert.NoError(t, err)
+	tagRepo.AssertCalled(t, "GetFacets", uint(1), models.AccessPublic.Visibilities())
+	tagRepo.AssertCalled(t, "GetFacets", uint(1), models.AccessOwner.Visibilities())
+}
+
+func TestWishService_UpdateTagsAndFilter(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	tagRepo := new(MockTagRepository)
+	wishService := service.NewWishService(wishRepo, mockUserRepo, mockWishlistRepo, tagRepo, newAccessPolicy())
+
+	existing := &models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Status: models.WishActive}
+	tags := []models.Tag{{ID: 3, UserID: 1, Name: "books"}}
+	wishRepo.On("GetByID", uint(1)).Return(existing, nil)
+	wishRepo.On("Update", existing).Return(nil)
+	tagRepo.On("FindOrCreate", uint(1), []string{"books"}).Return(tags, nil)
+	wishRepo.On("ReplaceTags", existing, tags).Return(nil)
+
+	err := wishService.Update(1, &models.Wish{
+		Model:    gorm.Model{ID: 1},
+		Title:    "Novel",
+		Category: models.CategoryBooks,
+		Tags:     []models.Tag{{Name: "Books"}, {Name: "books "}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.CategoryBooks, existing.Category)
+	wishRepo.AssertCalled(t, "ReplaceTags", existing, tags)
+
+	err = wishService.Update(1, &models.Wish{Model: gorm.Model{ID: 1}, Category: "cars"})
+	assert.ErrorIs(t, err, service.ErrInvalidCategory)
+
+	// Updates that leave the category out keep it.
+	err = wishService.Update(1, &models.Wish{Model: gorm.Model{ID: 1}, Title: "Paperback"})
+	assert.NoError(t, err)
+	assert.Equal(t, models.CategoryBooks, existing.Category)
+
+	wishRepo.On("GetByUserID", uint(1), models.WishFilter{Status: models.WishActive, Tag: "books"}, (*pagination.Cursor)(nil), pagination.DefaultLimit).
+		Return([]models.Wish{}, (*pagination.Cursor)(nil), nil)
+	_, _, err = wishService.GetByUserID(1, models.WishFilter{Tag: " Books"}, "", 0)
+	assert.NoError(t, err)
+}
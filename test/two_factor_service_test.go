@@ -0,0 +1,256 @@
+package test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"wishlist-app/internal/models"
+	"wishlist-app/internal/repository"
+	"wishlist-app/internal/service"
+	"wishlist-app/pkg/mail"
+	"wishlist-app/pkg/totp"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) Enable(user *models.User, codes []models.RecoveryCode) error {
+	args := m.Called(user, codes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Disable(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseStep(userID uint, step int64) error {
+	args := m.Called(userID, step)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(userID uint, hash string) error {
+	args := m.Called(userID, hash)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) ReserveAttempt(userID uint, limit int, lockout time.Duration) error {
+	args := m.Called(userID, limit, lockout)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) ResetFailedAttempts(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) CreateChallenge(challenge *models.LoginChallenge) error {
+	args := m.Called(challenge)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) GetChallenge(tokenHash string) (*models.LoginChallenge, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(*models.LoginChallenge), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) UseChallenge(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// recoveryCodeHash mirrors how the service hashes recovery codes.
+func recoveryCodeHash(userID uint, code string) string {
+	salt := []byte(fmt.Sprintf("wishlist-recovery-code:%d", userID))
+	return hex.EncodeToString(argon2.IDKey([]byte(code), salt, 2, 19*1024, 1, 32))
+}
+
+func TestTOTP_RFC6238Vectors(t *testing.T) {
+	// The RFC's SHA-1 seed "12345678901234567890" in base32.
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 2000000000: "279037"} {
+		code, err := totp.Code(secret, totp.Step(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code)
+	}
+
+	now := time.Unix(1111111109, 0)
+	previous, _ := totp.Code(secret, totp.Step(now)-1)
+	step, ok := totp.Validate(secret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now)-1, step)
+
+	stale, _ := totp.Code(secret, totp.Step(now)-2)
+	_, ok = totp.Validate(secret, stale, now)
+	assert.False(t, ok)
+}
+
+func TestTwoFactorService_EnrollAndConfirm(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	twoFactorRepo := new(MockTwoFactorRepository)
+	cfg := testAuthConfig()
+	cfg.Auth.TOTPIssuer = "Wishlist"
+	twoFactorService := service.NewTwoFactorService(userRepo, twoFactorRepo, cfg)
+
+	user := &models.User{Model: gorm.Model{ID: 1}, Login: "alice"}
+	userRepo.On("GetByID", uint(1)).Return(user, nil)
+	userRepo.On("Update", user, []string{"totp_secret", "totp_last_step"}).Return(nil)
+
+	_, err := twoFactorService.Confirm(1, "123456")
+	assert.ErrorIs(t, err, service.ErrTwoFactorNotEnrolled)
+
+	enrollment, err := twoFactorService.Enroll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, enrollment.Secret, user.TOTPSecret)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Wishlist:alice?"))
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+	assert.False(t, user.TwoFactorEnabled())
+
+	_, err = twoFactorService.Confirm(1, "000000")
+	assert.ErrorIs(t, err, service.ErrInvalidTwoFactorCode)
+
+	var stored []models.RecoveryCode
+	twoFactorRepo.On("Enable", user, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).([]models.RecoveryCode)
+	}).Return(nil)
+
+	code, _ := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	codes, err := twoFactorService.Confirm(1, code)
+	assert.NoError(t, err)
+	assert.True(t, user.TwoFactorEnabled())
+	assert.Len(t, codes, 10)
+	assert.Len(t, stored, 10)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, codes[0])
+	assert.Equal(t, recoveryCodeHash(1, strings.ReplaceAll(codes[0], "-", "")), stored[0].CodeHash)
+	assert.NotEqual(t, recoveryCodeHash(2, strings.ReplaceAll(codes[0], "-", "")), stored[0].CodeHash)
+
+	_, err = twoFactorService.Enroll(1)
+	assert.ErrorIs(t, err, service.ErrTwoFactorEnabled)
+}
+
+// twoFactorLogin logs in with the password and returns the challenge along
+// with its record on the server.
+func twoFactorLogin(t *testing.T, authService *service.AuthService, twoFactorRepo *MockTwoFactorRepository) (*service.Challenge, *models.LoginChallenge) {
+	var stored *models.LoginChallenge
+	twoFactorRepo.On("CreateChallenge", mock.Anything).Run(func(args mock.Arguments) {
+		// Any ID that differs between logins will do.
+		stored = args.Get(0).(*models.LoginChallenge)
+		stored.ID = uint(len(twoFactorRepo.Calls))
+	}).Return(nil).Once()
+
+	result, err := authService.Login("alice", "secret", "Firefox", "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Nil(t, result.Tokens)
+	twoFactorRepo.On("GetChallenge", stored.TokenHash).Return(stored, nil)
+	twoFactorRepo.On("UseChallenge", stored.ID).Run(func(args mock.Arguments) {
+		usedAt := time.Now()
+		stored.UsedAt = &usedAt
+	}).Return(nil).Once()
+	return result.Challenge, stored
+}
+
+func TestAuthService_TwoFactorLogin(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	twoFactorRepo := new(MockTwoFactorRepository)
+	cfg := testAuthConfig()
+	cfg.Auth.TwoFactorChallengeLifetime = 5 * time.Minute
+	cfg.Auth.TwoFactorLockout = 15 * time.Minute
+	twoFactorService := service.NewTwoFactorService(userRepo, twoFactorRepo, cfg)
+	authService := service.NewAuthService(userRepo, sessionRepo, new(MockPasswordResetRepository), twoFactorService, mail.NewMemoryMailer(), cfg)
+
+	secret, _ := totp.GenerateSecret()
+	enabledAt := time.Now()
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	user := &models.User{Model: gorm.Model{ID: 1}, Login: "alice", PasswordHash: string(hashedPassword), TOTPSecret: secret, TOTPEnabledAt: &enabledAt}
+	userRepo.On("FindByLogin", "alice").Return(user, nil)
+	userRepo.On("GetByID", uint(1)).Return(user, nil)
+	twoFactorRepo.On("ReserveAttempt", uint(1), 5, 15*time.Minute).Return(nil).Times(3)
+	twoFactorRepo.On("ResetFailedAttempts", uint(1)).Return(nil)
+	sessionRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Session).ID = 5
+	}).Return(nil)
+
+	challenge, stored := twoFactorLogin(t, authService, twoFactorRepo)
+	assert.Equal(t, 5*time.Minute, challenge.ExpiresIn)
+	assert.Equal(t, uint(1), stored.UserID)
+	assert.NotEqual(t, challenge.Token, stored.TokenHash)
+	sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// The challenge is not an access token.
+	_, err := authService.Authenticate(challenge.Token)
+	assert.Error(t, err)
+
+	_, err = authService.CompleteLogin("forged", "123456", "Firefox", "203.0.113.7")
+	assert.ErrorIs(t, err, service.ErrInvalidChallenge)
+
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+	twoFactorRepo.On("UseStep", uint(1), totp.Step(time.Now())).Return(nil).Once()
+	twoFactorRepo.On("UseStep", uint(1), mock.Anything).Return(repository.ErrCodeReused)
+
+	tokens, err := authService.CompleteLogin(challenge.Token, code, "Firefox", "203.0.113.7")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	twoFactorRepo.AssertNumberOfCalls(t, "ResetFailedAttempts", 1)
+
+	// A challenge completes one login only.
+	_, err = authService.CompleteLogin(challenge.Token, code, "Firefox", "203.0.113.7")
+	assert.ErrorIs(t, err, service.ErrInvalidChallenge)
+
+	// A code works once, even within its time step, and a wrong code keeps
+	// its attempt counted.
+	challenge, _ = twoFactorLogin(t, authService, twoFactorRepo)
+	_, err = authService.CompleteLogin(challenge.Token, code, "Firefox", "203.0.113.7")
+	assert.ErrorIs(t, err, service.ErrInvalidTwoFactorCode)
+	twoFactorRepo.AssertNumberOfCalls(t, "ResetFailedAttempts", 1)
+
+	twoFactorRepo.On("UseRecoveryCode", uint(1), recoveryCodeHash(1, "k3m9qxa2rt")).Return(nil).Once()
+	twoFactorRepo.On("UseRecoveryCode", uint(1), mock.Anything).Return(repository.ErrCodeReused)
+	_, err = authService.CompleteLogin(challenge.Token, " K3M9Q-XA2RT ", "Firefox", "203.0.113.7")
+	assert.NoError(t, err)
+
+	// Once locked out, logging in with the password again does not give the
+	// attempts back.
+	twoFactorRepo.On("ReserveAttempt", uint(1), 5, 15*time.Minute).Return(repository.ErrTooManyAttempts)
+	challenge, _ = twoFactorLogin(t, authService, twoFactorRepo)
+	_, err = authService.CompleteLogin(challenge.Token, "k3m9q-xa2rt", "Firefox", "203.0.113.7")
+	assert.ErrorIs(t, err, service.ErrTooManyTwoFactorAttempts)
+	twoFactorRepo.AssertNumberOfCalls(t, "ResetFailedAttempts", 2)
+	twoFactorRepo.AssertNumberOfCalls(t, "UseRecoveryCode", 1)
+}
+
+func TestTwoFactorService_Disable(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	twoFactorRepo := new(MockTwoFactorRepository)
+	twoFactorService := service.NewTwoFactorService(userRepo, twoFactorRepo, testAuthConfig())
+
+	enabledAt := time.Now()
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	userRepo.On("GetByID", uint(1)).Return(&models.User{Model: gorm.Model{ID: 1}, PasswordHash: string(hashedPassword), TOTPSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", TOTPEnabledAt: &enabledAt}, nil)
+	userRepo.On("GetByID", uint(2)).Return(&models.User{Model: gorm.Model{ID: 2}, PasswordHash: string(hashedPassword)}, nil)
+	twoFactorRepo.On("ReserveAttempt", uint(1), 5, mock.Anything).Return(nil)
+	twoFactorRepo.On("ResetFailedAttempts", uint(1)).Return(nil)
+	twoFactorRepo.On("UseRecoveryCode", uint(1), recoveryCodeHash(1, "k3m9qxa2rt")).Return(nil)
+	twoFactorRepo.On("Disable", uint(1)).Return(nil)
+
+	assert.ErrorIs(t, twoFactorService.Disable(2, "secret", "k3m9q-xa2rt"), service.ErrTwoFactorNotEnabled)
+	assert.ErrorIs(t, twoFactorService.Disable(1, "wrong", "k3m9q-xa2rt"), service.ErrWrongPassword)
+	twoFactorRepo.AssertNotCalled(t, "Disable", mock.Anything)
+
+	assert.NoError(t, twoFactorService.Disable(1, "secret", "k3m9q-xa2rt"))
+	twoFactorRepo.AssertCalled(t, "Disable", uint(1))
+}
@@ -3,47 +3,55 @@ package test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"time"
 	"wishlist-app/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
-	"wishlist-app/internal/config"
+	"github.com/stretchr/testify/mock"
 	"wishlist-app/internal/handler"
 	"wishlist-app/internal/models"
 	"wishlist-app/internal/service"
 	"wishlist-app/pkg/logger"
+	"wishlist-app/pkg/mail"
+	"wishlist-app/pkg/pagination"
 )
 
 var (
-	mockWishRepo = new(MockWishRepository)
-	mockUserRepo = new(MockUserRepository)
+	mockWishRepo     = new(MockWishRepository)
+	mockUserRepo     = new(MockUserRepository)
+	mockWishlistRepo = new(MockWishlistRepository)
 )
 
 func setupWishRouter() *gin.Engine {
-	cfg := &config.Config{}
-	cfg.Auth.JWTSecret = "test-secret"
-	cfg.Auth.JWTLifetime = 24 * time.Hour
+	cfg := testAuthConfig()
 	log, _ := logger.New("test")
 
-	authService := service.NewAuthService(mockUserRepo, cfg)
-	wishService := service.NewWishService(mockWishRepo, mockUserRepo)
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Session).ID = 1
+	}).Return(nil)
+	sessionRepo.On("GetByID", uint(1)).Return(activeSession(1, 0), nil)
+	sessionRepo.On("Touch", mock.Anything, mock.Anything).Return(nil)
+	authService := service.NewAuthService(mockUserRepo, sessionRepo, new(MockPasswordResetRepository), nil, mail.NewMemoryMailer(), cfg)
+	wishService := service.NewWishService(mockWishRepo, mockUserRepo, mockWishlistRepo, new(MockTagRepository), newAccessPolicy())
 
 	router := gin.New()
-	authHandler := handler.NewAuthHandler(cfg, log, authService)
-	wishHandler := handler.NewWishHandler(cfg, log, wishService)
+	authHandler := handler.NewAuthHandler(cfg, log, authService, service.NewEmailService(mockUserRepo, mail.NewMemoryMailer(), cfg))
+	wishHandler := handler.NewWishHandler(cfg, log, wishService, service.NewProductService(localFetcher{}), service.NewImageService(mockWishRepo, nil, service.ImageLimits{}))
 
 	api := router.Group("/api")
 	{
 		api.POST("/login", authHandler.Login)
-		api.GET("/wishes/:username", wishHandler.GetByUsername)
+		api.GET("/wishes/:username", middleware.OptionalAuth(authService, log), wishHandler.GetByUsername)
 
 		auth := api.Group("")
-		auth.Use(middleware.Auth(cfg, log))
+		auth.Use(middleware.Auth(authService, log))
 		{
 			auth.POST("/wishes", wishHandler.Create)
 			auth.PUT("/wishes/:id", wishHandler.Update)
@@ -65,7 +73,14 @@ func TestCreateWish(t *testing.T) {
 	}
 	body, _ := json.Marshal(wish)
 
+	defaultWishlistID := uint(1)
+	wish.WishlistID = &defaultWishlistID
+	wish.Priority = models.PriorityWant
+	wish.Quantity = 1
+	wish.PriceUnknown = true
+
 	w := httptest.NewRecorder()
+	mockWishlistRepo.On("GetOrCreateDefault", uint(0)).Return(&models.Wishlist{Model: gorm.Model{ID: defaultWishlistID}}, nil)
 	mockWishRepo.On("Create", &wish).Return(nil)
 
 	req, _ := http.NewRequest("POST", "/api/wishes", bytes.NewBuffer(body))
@@ -78,7 +93,9 @@ func TestCreateWish(t *testing.T) {
 func TestGetPublicWishes(t *testing.T) {
 	router := setupWishRouter()
 
-	mockWishRepo.On("GetByUsername", "testuser").Return([]models.Wish{}, nil)
+	mockUserRepo.On("FindByLogin", "testuser").Return(&models.User{Login: "testuser"}, nil)
+	mockWishRepo.On("GetByUsername", "testuser", models.AccessPublic.Visibilities(), models.WishFilter{}, (*pagination.Cursor)(nil), pagination.DefaultLimit).
+		Return([]models.Wish{}, (*pagination.Cursor)(nil), nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/api/wishes/testuser", nil)
@@ -87,6 +104,46 @@ func TestGetPublicWishes(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestUpdateWishKeepsPrice(t *testing.T) {
+	router := setupWishRouter()
+	token := getTestToken(t, router)
+
+	update := func(wishID uint, body string) *models.Wish {
+		mockWishRepo.On("GetByID", wishID).Return(&models.Wish{
+			Model:         gorm.Model{ID: wishID},
+			Title:         "Headphones",
+			PriceMinor:    2499,
+			PriceMaxMinor: 2499,
+			Currency:      "EUR",
+		}, nil)
+
+		var saved *models.Wish
+		mockWishRepo.On("Update", mock.MatchedBy(func(wish *models.Wish) bool { return wish.ID == wishID })).
+			Run(func(args mock.Arguments) { saved = args.Get(0).(*models.Wish) }).
+			Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/wishes/%d", wishID), bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return saved
+	}
+
+	saved := update(70, `{"title":"Wireless headphones"}`)
+	assert.Equal(t, "Wireless headphones", saved.Title)
+	assert.Equal(t, int64(2499), saved.PriceMinor)
+	assert.Equal(t, int64(2499), saved.PriceMaxMinor)
+	assert.False(t, saved.PriceUnknown)
+	assert.Equal(t, "EUR", saved.Currency)
+
+	saved = update(71, `{"title":"Headphones","price_unknown":true}`)
+	assert.True(t, saved.PriceUnknown)
+	assert.Zero(t, saved.PriceMinor)
+	assert.Zero(t, saved.PriceMaxMinor)
+}
+
 func getTestToken(t *testing.T, router *gin.Engine) string {
 	creds := map[string]string{
 		"login":    "testuser",
@@ -103,7 +160,7 @@ func getTestToken(t *testing.T, router *gin.Engine) string {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response map[string]string
+	var response handler.LoginResponse
 	_ = json.Unmarshal(w.Body.Bytes(), &response)
-	return response["token"]
+	return response.Token
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"wishlist-app/internal/models"
 	"wishlist-app/internal/service"
+	"wishlist-app/pkg/pagination"
 )
 
 type MockWishRepository struct {
@@ -35,16 +36,100 @@ func (m *MockWishRepository) Delete(id uint) error {
 	return args.Error(0)
 }
 
-func (m *MockWishRepository) GetByUserID(userID uint) ([]models.Wish, error) {
+func (m *MockWishRepository) GetByUserID(userID uint, filter models.WishFilter, after *pagination.Cursor, limit int) ([]models.Wish, *pagination.Cursor, error) {
+	args := m.Called(userID, filter, after, limit)
+	return args.Get(0).([]models.Wish), args.Get(1).(*pagination.Cursor), args.Error(2)
+}
+
+func (m *MockWishRepository) GetReceived(userID uint) ([]models.Wish, error) {
 	args := m.Called(userID)
 	return args.Get(0).([]models.Wish), args.Error(1)
 }
 
-func (m *MockWishRepository) GetByUsername(username string) ([]models.Wish, error) {
-	args := m.Called(username)
+func (m *MockWishRepository) GetByUsername(username string, visibilities []models.Visibility, filter models.WishFilter, after *pagination.Cursor, limit int) ([]models.Wish, *pagination.Cursor, error) {
+	args := m.Called(username, visibilities, filter, after, limit)
+	return args.Get(0).([]models.Wish), args.Get(1).(*pagination.Cursor), args.Error(2)
+}
+
+func (m *MockWishRepository) GetByWishlistID(wishlistID uint, visibilities []models.Visibility) ([]models.Wish, error) {
+	args := m.Called(wishlistID, visibilities)
+	return args.Get(0).([]models.Wish), args.Error(1)
+}
+
+func (m *MockWishRepository) GetByOccasionIDs(occasionIDs []uint, visibilities []models.Visibility) ([]models.Wish, error) {
+	args := m.Called(occasionIDs, visibilities)
+	return args.Get(0).([]models.Wish), args.Error(1)
+}
+
+func (m *MockWishRepository) NextRank(userID uint, after string) (string, error) {
+	args := m.Called(userID, after)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockWishRepository) Search(viewerID uint, search models.WishSearch, cursor *pagination.Cursor, limit int) ([]models.WishSearchResult, error) {
+	args := m.Called(viewerID, search, cursor, limit)
+	return args.Get(0).([]models.WishSearchResult), args.Error(1)
+}
+
+func (m *MockWishRepository) UpdateRank(id uint, rank string) error {
+	args := m.Called(id, rank)
+	return args.Error(0)
+}
+
+func (m *MockWishRepository) ReplaceTags(wish *models.Wish, tags []models.Tag) error {
+	args := m.Called(wish, tags)
+	return args.Error(0)
+}
+
+func (m *MockWishRepository) HasPledges(wishID uint) (bool, error) {
+	args := m.Called(wishID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWishRepository) GetFeed(userID uint, cursor *pagination.Cursor, limit int) ([]models.Wish, error) {
+	args := m.Called(userID, cursor, limit)
 	return args.Get(0).([]models.Wish), args.Error(1)
 }
 
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) Create(wishlist *models.Wishlist) error {
+	args := m.Called(wishlist)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) GetByID(id uint) (*models.Wishlist, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) Update(wishlist *models.Wishlist) error {
+	args := m.Called(wishlist)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) Delete(id, defaultID uint) error {
+	args := m.Called(id, defaultID)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) GetByUserID(userID uint) ([]models.Wishlist, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetByUsername(username string, visibilities []models.Visibility) ([]models.Wishlist, error) {
+	args := m.Called(username, visibilities)
+	return args.Get(0).([]models.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetOrCreateDefault(userID uint) (*models.Wishlist, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*models.Wishlist), args.Error(1)
+}
+
 type MockUserRepository struct {
 	mock.Mock
 }
@@ -54,34 +139,95 @@ func (m *MockUserRepository) Create(user *models.User) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) GetByID(id uint) (*models.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(user *models.User, columns ...string) error {
+	args := m.Called(user, columns)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) FindByLogin(login string) (*models.User, error) {
 	args := m.Called(login)
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Exists(login string) (bool, error) {
 	args := m.Called(login)
 	return args.Bool(0), args.Error(1)
 }
 
 func TestWishService_Create(t *testing.T) {
-	wishService := service.NewWishService(mockWishRepo, mockUserRepo)
+	wishService := service.NewWishService(mockWishRepo, mockUserRepo, mockWishlistRepo, new(MockTagRepository), newAccessPolicy())
 
 	testWish := &models.Wish{
 		UserID: 1,
 		Title:  "Test Wish",
 	}
 
+	mockWishlistRepo.On("GetOrCreateDefault", uint(1)).Return(&models.Wishlist{Model: gorm.Model{ID: 7}, UserID: 1}, nil)
 	mockWishRepo.On("Create", testWish).Return(nil)
 
 	createdWish, err := wishService.Create(1, testWish)
 	assert.NoError(t, err)
 	assert.Equal(t, testWish, createdWish)
+	assert.Equal(t, uint(7), *createdWish.WishlistID)
+	assert.Equal(t, models.PriorityWant, createdWish.Priority)
 	mockWishRepo.AssertExpectations(t)
 }
 
+func TestWishService_CreateInForeignWishlist(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	wishlistRepo := new(MockWishlistRepository)
+	wishService := service.NewWishService(wishRepo, mockUserRepo, wishlistRepo, new(MockTagRepository), newAccessPolicy())
+
+	wishlistID := uint(3)
+	wishlistRepo.On("GetByID", wishlistID).Return(&models.Wishlist{Model: gorm.Model{ID: wishlistID}, UserID: 2}, nil)
+
+	_, err := wishService.Create(1, &models.Wish{WishlistID: &wishlistID, Title: "Test Wish"})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	wishRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestWishService_ReorderOnlyRewritesMovedWish(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	wishService := service.NewWishService(wishRepo, mockUserRepo, mockWishlistRepo, new(MockTagRepository), newAccessPolicy())
+
+	// Order is 1 (a), 2 (b), 3 (c); move 3 between 1 and 2.
+	wishRepo.On("GetByID", uint(3)).Return(&models.Wish{Model: gorm.Model{ID: 3}, UserID: 1, Rank: "c"}, nil)
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Rank: "a"}, nil)
+	wishRepo.On("NextRank", uint(1), "a").Return("b", nil)
+	wishRepo.On("UpdateRank", uint(3), mock.MatchedBy(func(rank string) bool {
+		return rank > "a" && rank < "b"
+	})).Return(nil)
+
+	afterID := uint(1)
+	err := wishService.Reorder(1, []service.WishMove{{WishID: 3, AfterID: &afterID}})
+	assert.NoError(t, err)
+	wishRepo.AssertNumberOfCalls(t, "UpdateRank", 1)
+
+	// Moving to the top uses the first rank as the upper bound.
+	wishRepo.On("NextRank", uint(1), "").Return("a", nil)
+	wishRepo.On("UpdateRank", uint(3), mock.MatchedBy(func(rank string) bool {
+		return rank < "a"
+	})).Return(nil)
+
+	err = wishService.Reorder(1, []service.WishMove{{WishID: 3}})
+	assert.NoError(t, err)
+
+	err = wishService.Reorder(2, []service.WishMove{{WishID: 3}})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
 func TestWishService_GetByID(t *testing.T) {
-	wishService := service.NewWishService(mockWishRepo, mockUserRepo)
+	wishService := service.NewWishService(mockWishRepo, mockUserRepo, mockWishlistRepo, new(MockTagRepository), newAccessPolicy())
 
 	testWish := &models.Wish{
 		Model:  gorm.Model{ID: 1, CreatedAt: time.Now()},
@@ -94,5 +240,269 @@ func TestWishService_GetByID(t *testing.T) {
 	wish, err := wishService.GetByID(1, 1)
 	assert.NoError(t, err)
 	assert.Equal(t, testWish, wish)
+
+	_, err = wishService.GetByID(2, 1)
+	assert.ErrorIs(t, err, service.ErrForbidden)
 	mockWishRepo.AssertExpectations(t)
 }
+
+func TestWishService_UpdateCurrency(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	wishService := service.NewWishService(wishRepo, mockUserRepo, mockWishlistRepo, new(MockTagRepository), newAccessPolicy())
+
+	existing := &models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Title: "Kettle", PriceMinor: 100, PriceMaxMinor: 100, Currency: "JPY"}
+	wishRepo.On("GetByID", uint(1)).Return(existing, nil)
+	wishRepo.On("Update", existing).Return(nil)
+	wishRepo.On("HasPledges", uint(1)).Return(true, nil)
+
+	// Leaving the price out keeps the currency.
+	err := wishService.Update(1, &models.Wish{Model: gorm.Model{ID: 1}, Title: "Tea kettle", PriceUnknown: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "JPY", existing.Currency)
+
+	err = wishService.Update(1, &models.Wish{Model: gorm.Model{ID: 1}, Title: "Tea kettle", PriceMinor: 100, PriceMaxMinor: 100, Currency: "EUR"})
+	assert.ErrorIs(t, err, service.ErrCurrencyLocked)
+	assert.Equal(t, "JPY", existing.Currency)
+}
+
+func TestWishService_GetByUsernameVisibility(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	userRepo := new(MockUserRepository)
+	wishService := service.NewWishService(wishRepo, userRepo, new(MockWishlistRepository), new(MockTagRepository), newAccessPolicy())
+
+	userRepo.On("FindByLogin", "owner").Return(&models.User{Model: gorm.Model{ID: 1}, Login: "owner"}, nil)
+	noCursor := (*pagination.Cursor)(nil)
+	wishRepo.On("GetByUsername", "owner", []models.Visibility{models.VisibilityPublic}, models.WishFilter{}, noCursor, pagination.DefaultLimit).Return([]models.Wish{}, noCursor, nil)
+	wishRepo.On("GetByUsername", "owner", []models.Visibility{
+		models.VisibilityPublic, models.VisibilityFriends, models.VisibilityLink, models.VisibilityPrivate,
+	}, models.WishFilter{}, noCursor, pagination.DefaultLimit).Return([]models.Wish{}, noCursor, nil)
+
+	_, _, err := wishService.GetByUsername(0, "owner", models.WishFilter{}, "", 0)
+	assert.NoError(t, err)
+	_, _, err = wishService.GetByUsername(2, "owner", models.WishFilter{}, "", 0)
+	assert.NoError(t, err)
+	_, _, err = wishService.GetByUsername(1, "owner", models.WishFilter{}, "", 0)
+	assert.NoError(t, err)
+
+	wishRepo.AssertNumberOfCalls(t, "GetByUsername", 3)
+	wishRepo.AssertCalled(t, "GetByUsername", "owner", models.AccessOwner.Visibilities(), models.WishFilter{}, noCursor, pagination.DefaultLimit)
+}
+
+func TestWishService_Lifecycle(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	wishService := service.NewWishService(wishRepo, mockUserRepo, mockWishlistRepo, new(MockTagRepository), newAccessPolicy())
+
+	wish := &models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, Status: models.WishActive}
+	wishRepo.On("GetByID", uint(1)).Return(wish, nil)
+	wishRepo.On("Update", wish).Return(nil)
+
+	_, err := wishService.SetThankYouNote(1, 1, "Thanks!")
+	assert.ErrorIs(t, err, service.ErrNotReceived)
+
+	received, err := wishService.Receive(1, 1, "Thank you so much!")
+	assert.NoError(t, err)
+	assert.Equal(t, models.WishReceived, received.Status)
+	assert.NotNil(t, received.ReceivedAt)
+
+	_, err = wishService.Receive(1, 1, "")
+	assert.ErrorIs(t, err, service.ErrInvalidTransition)
+
+	archived, err := wishService.Archive(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WishArchived, archived.Status)
+	assert.NotNil(t, archived.ReceivedAt)
+
+	restored, err := wishService.Restore(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WishActive, restored.Status)
+	assert.Nil(t, restored.ReceivedAt)
+
+	_, err = wishService.Archive(2, 1)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestWish_ThankYouNoteOnlyForGifters(t *testing.T) {
+	wish := &models.Wish{
+		Model:        gorm.Model{ID: 1},
+		UserID:       1,
+		Status:       models.WishReceived,
+		ThankYouNote: "Love it!",
+		Reservations: []models.Reservation{{WishID: 1, UserID: 2}},
+	}
+
+	assert.Equal(t, "Love it!", wish.ToPublic().ThankYouNote)
+	assert.Equal(t, "Love it!", wish.ToPublicFor(2).ThankYouNote)
+	assert.Empty(t, wish.ToPublicFor(3).ThankYouNote)
+	assert.Empty(t, wish.ToPublicFor(0).ThankYouNote)
+}
+
+func TestAccess_SeparateAudiences(t *testing.T) {
+	assert.Equal(t, []models.Visibility{models.VisibilityPublic}, models.AccessPublic.Visibilities())
+	assert.Equal(t, []models.Visibility{models.VisibilityPublic, models.VisibilityFriends}, models.AccessFriend.Visibilities())
+	assert.Equal(t, []models.Visibility{models.VisibilityPublic, models.VisibilityLink}, models.AccessLink.Visibilities())
+	assert.Equal(t, []models.Visibility{models.VisibilityPublic, models.VisibilityFriends, models.VisibilityLink},
+		models.AccessFriend.With(models.AccessLink).Visibilities())
+	assert.Equal(t, models.AccessOwner, models.AccessOwner.With(models.AccessLink))
+
+	// Unknown visibilities are private.
+	assert.True(t, models.AccessOwner.CanSee(""))
+	assert.False(t, models.AccessFriend.With(models.AccessLink).CanSee(""))
+
+	// Both the wish and its list must be visible to the viewer.
+	wish := &models.Wish{Visibility: models.VisibilityFriends, Wishlist: &models.Wishlist{Visibility: models.VisibilityLink}}
+	assert.False(t, wish.VisibleWith(models.AccessFriend))
+	assert.False(t, wish.VisibleWith(models.AccessLink))
+	assert.True(t, wish.VisibleWith(models.AccessFriend.With(models.AccessLink)))
+	assert.True(t, wish.VisibleWith(models.AccessOwner))
+}
+
+func TestWish_PriceViews(t *testing.T) {
+	unknown := &models.Wish{PriceUnknown: true}
+	public := unknown.ToPublic()
+	assert.True(t, public.PriceUnknown)
+	assert.Empty(t, public.Price)
+	assert.Empty(t, public.Currency)
+
+	free := &models.Wish{Currency: "EUR"}
+	assert.Equal(t, "0.00", free.ToPublic().Price)
+
+	ranged := &models.Wish{PriceMinor: 5000, PriceMaxMinor: 15000, Currency: "USD"}
+	public = ranged.ToPublic()
+	assert.Empty(t, public.Price)
+	assert.Equal(t, "50.00", public.PriceMin)
+	assert.Equal(t, "150.00", public.PriceMax)
+	assert.Equal(t, "USD", public.Currency)
+}
+
+func TestWishService_PriceRangeValidation(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	wishlistRepo := new(MockWishlistRepository)
+	wishService := service.NewWishService(wishRepo, mockUserRepo, wishlistRepo, new(MockTagRepository), newAccessPolicy())
+
+	wishlistRepo.On("GetOrCreateDefault", uint(1)).Return(&models.Wishlist{Model: gorm.Model{ID: 1}}, nil)
+
+	_, err := wishService.Create(1, &models.Wish{Title: "Headphones", PriceMinor: 15000, PriceMaxMinor: 5000, Currency: "USD"})
+	assert.ErrorIs(t, err, service.ErrInvalidPriceRange)
+
+	_, err = wishService.Create(1, &models.Wish{Title: "Headphones", PriceMinor: 5000, PriceMaxMinor: 15000, Currency: "ABC"})
+	assert.ErrorIs(t, err, service.ErrInvalidCurrency)
+	wishRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	_, _, err = wishService.GetByUserID(1, models.WishFilter{Sort: "cheapest"}, "", 0)
+	assert.ErrorIs(t, err, service.ErrInvalidSort)
+
+	lower, upper := int64(100), int64(50)
+	_, _, err = wishService.GetByUserID(1, models.WishFilter{MinPrice: &lower, MaxPrice: &upper, Currency: "EUR"}, "", 0)
+	assert.ErrorIs(t, err, service.ErrInvalidPriceRange)
+
+	filter := models.WishFilter{Status: models.WishActive, Sort: models.SortPriceAsc, Currency: "EUR"}
+	wishRepo.On("GetByUserID", uint(1), filter, (*pagination.Cursor)(nil), pagination.DefaultLimit).Return([]models.Wish{}, (*pagination.Cursor)(nil), nil)
+	_, _, err = wishService.GetByUserID(1, models.WishFilter{Sort: models.SortPriceAsc, Currency: "EUR"}, "", 0)
+	assert.NoError(t, err)
+}
+
+func TestWishService_GetByUserIDPaging(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	wishService := service.NewWishService(wishRepo, mockUserRepo, new(MockWishlistRepository), new(MockTagRepository), newAccessPolicy())
+
+	_, _, err := wishService.GetByUserID(1, models.WishFilter{}, "not a cursor", 0)
+	assert.ErrorIs(t, err, service.ErrInvalidCursor)
+
+	filter := models.WishFilter{Status: models.WishActive, Sort: models.SortCreatedDesc}
+	after := &pagination.Cursor{Value: "2026-01-02T00:00:00Z", ID: 7, Sort: string(models.SortCreatedDesc)}
+	next := &pagination.Cursor{Value: "2026-01-01T00:00:00Z", ID: 3, Sort: string(models.SortCreatedDesc)}
+	wishRepo.On("GetByUserID", uint(1), filter, after, pagination.MaxLimit).
+		Return([]models.Wish{{Model: gorm.Model{ID: 5}}, {Model: gorm.Model{ID: 3}}}, next, nil)
+
+	wishes, cursor, err := wishService.GetByUserID(1, models.WishFilter{Sort: models.SortCreatedDesc}, pagination.Encode(*after), 500)
+	assert.NoError(t, err)
+	assert.Len(t, wishes, 2)
+	assert.Equal(t, pagination.Encode(*next), cursor)
+
+	// A cursor taken from a list in another order is rejected by the repository.
+	stale := &pagination.Cursor{Value: "Lamp", ID: 2, Sort: string(models.SortTitleAsc)}
+	wishRepo.On("GetByUserID", uint(1), filter, stale, pagination.DefaultLimit).
+		Return([]models.Wish(nil), (*pagination.Cursor)(nil), pagination.ErrInvalidCursor)
+	_, _, err = wishService.GetByUserID(1, models.WishFilter{Sort: models.SortCreatedDesc}, pagination.Encode(*stale), 0)
+	assert.ErrorIs(t, err, service.ErrInvalidCursor)
+}
+
+func TestWishService_Copy(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	wishlistRepo := new(MockWishlistRepository)
+	wishService := service.NewWishService(wishRepo, mockUserRepo, wishlistRepo, new(MockTagRepository), newAccessPolicy())
+
+	owner := models.User{Model: gorm.Model{ID: 1}, Login: "owner"}
+	wishRepo.On("GetByID", uint(1)).Return(&models.Wish{
+		Model: gorm.Model{ID: 1}, UserID: 1, User: owner, Title: "Lamp", Comment: "The green one",
+		PriceMinor: 4000, PriceMaxMinor: 4000, Currency: "EUR", Quantity: 2, Priority: models.PriorityMustHave,
+		Category: models.CategoryHome, Status: models.WishActive, Visibility: models.VisibilityPublic,
+	}, nil)
+	wishRepo.On("GetByID", uint(2)).Return(&models.Wish{Model: gorm.Model{ID: 2}, UserID: 1, Visibility: models.VisibilityFriends}, nil)
+	wishRepo.On("GetByID", uint(3)).Return(&models.Wish{Model: gorm.Model{ID: 3}, UserID: 1, Status: models.WishReceived, Visibility: models.VisibilityPublic}, nil)
+
+	_, err := wishService.Copy(1, 1, nil)
+	assert.ErrorIs(t, err, service.ErrCopyOwnWish)
+	_, err = wishService.Copy(2, 2, nil)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	_, err = wishService.Copy(2, 3, nil)
+	assert.ErrorIs(t, err, service.ErrWishNotActive)
+	wishRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	wishlistRepo.On("GetOrCreateDefault", uint(2)).Return(&models.Wishlist{Model: gorm.Model{ID: 9}, UserID: 2}, nil)
+	wishRepo.On("Create", mock.Anything).Return(nil)
+
+	copied, err := wishService.Copy(2, 1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), copied.UserID)
+	assert.Equal(t, uint(9), *copied.WishlistID)
+	assert.Equal(t, uint(1), *copied.SourceWishID)
+	assert.Equal(t, "Lamp", copied.Title)
+	assert.Equal(t, int64(4000), copied.PriceMaxMinor)
+	// Quantity and priority are the saver's own choice.
+	assert.Equal(t, 1, copied.Quantity)
+	assert.Equal(t, models.PriorityWant, copied.Priority)
+	assert.Equal(t, "owner", copied.ToPublic().SavedFrom.Login)
+}
+
+func TestWish_SavedByOnlyForOwner(t *testing.T) {
+	wish := &models.Wish{Model: gorm.Model{ID: 1}, UserID: 1, SavedBy: 3}
+	assert.Equal(t, 3, wish.ToPublicFor(1).SavedBy)
+	assert.Zero(t, wish.ToPublicFor(2).SavedBy)
+	assert.Zero(t, wish.ToPublicFor(0).SavedBy)
+}
+
+func TestWishService_Search(t *testing.T) {
+	wishRepo := new(MockWishRepository)
+	wishService := service.NewWishService(wishRepo, mockUserRepo, new(MockWishlistRepository), new(MockTagRepository), newAccessPolicy())
+
+	_, _, err := wishService.Search(1, models.WishSearch{Query: "   "}, "", 0)
+	assert.ErrorIs(t, err, service.ErrInvalidSearch)
+	_, _, err = wishService.Search(1, models.WishSearch{Query: "lego", Filter: models.WishFilter{Sort: models.SortPriceAsc}}, "", 0)
+	assert.ErrorIs(t, err, service.ErrInvalidSort)
+	_, _, err = wishService.Search(1, models.WishSearch{Query: "lego"}, "not a cursor", 0)
+	assert.ErrorIs(t, err, service.ErrInvalidCursor)
+
+	search := models.WishSearch{Query: "lego", Owner: "alice", Filter: models.WishFilter{Tag: "toys"}}
+	wishRepo.On("Search", uint(1), search, (*pagination.Cursor)(nil), 3).Return([]models.WishSearchResult{
+		{Wish: models.Wish{Model: gorm.Model{ID: 9}}, Rank: 0.6},
+		{Wish: models.Wish{Model: gorm.Model{ID: 4}}, Rank: 0.0607927},
+		{Wish: models.Wish{Model: gorm.Model{ID: 7}}, Rank: 0.0607927},
+	}, nil)
+
+	results, next, err := wishService.Search(1, models.WishSearch{Query: " lego ", Owner: "alice", Filter: models.WishFilter{Tag: " Toys"}}, "", 2)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	cursor, err := pagination.Decode(next)
+	assert.NoError(t, err)
+	assert.Equal(t, &pagination.Cursor{Value: "0.0607927", ID: 4}, cursor)
+
+	wishRepo.On("Search", uint(1), search, cursor, 3).Return([]models.WishSearchResult{
+		{Wish: models.Wish{Model: gorm.Model{ID: 7}}, Rank: 0.0607927},
+	}, nil)
+	results, next, err = wishService.Search(1, search, next, 2)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Empty(t, next)
+}